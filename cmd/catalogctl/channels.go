@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newChannelsCommand(opts *options) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "channels",
+		Aliases: []string{"channel"},
+		Short:   "Point channels, e.g. stable, to versions of a service",
+	}
+
+	command.AddCommand(
+		newChannelsListCommand(opts),
+		newChannelsSetCommand(opts),
+		newChannelsDeleteCommand(opts),
+		newChannelsHistoryCommand(opts),
+	)
+
+	return command
+}
+
+func newChannelsListCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "list SERVICE",
+		Short:             "List the channels of a service",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			channels, err := catalog.ListChannels(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			rows := table{headers: []string{"NAME", "VERSION", "UPDATED"}}
+			for _, channel := range channels {
+				rows.rows = append(rows.rows, []string{channel.Name, channel.VersionName, formatTime(channel.UpdatedAt)})
+			}
+
+			return printOutput(opts.out, opts.output, channels, rows)
+		},
+	}
+}
+
+func newChannelsSetCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "set SERVICE CHANNEL VERSION",
+		Short:             "Create a channel, or move it to another version",
+		Long:              "Create a channel, or move it to another version. VERSION may be another channel, e.g. to promote canary to stable.",
+		Args:              exactArgs(3),
+		ValidArgsFunction: completeChannelNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.SetChannel(cmd.Context(), args[0], args[1], args[2]); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("channel %q of service %q points to %q", args[1], args[0], args[2]))
+		},
+	}
+}
+
+func newChannelsDeleteCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete SERVICE CHANNEL",
+		Short:             "Delete a channel, its history is kept",
+		Args:              exactArgs(2),
+		ValidArgsFunction: completeChannelNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.DeleteChannel(cmd.Context(), args[0], args[1]); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("channel %q of service %q deleted", args[1], args[0]))
+		},
+	}
+}
+
+func newChannelsHistoryCommand(opts *options) *cobra.Command {
+	var page int
+
+	command := &cobra.Command{
+		Use:               "history SERVICE CHANNEL",
+		Short:             "List the moves of a channel, latest first",
+		Args:              exactArgs(2),
+		ValidArgsFunction: completeChannelNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			history, err := catalog.GetChannelHistory(cmd.Context(), args[0], args[1], page)
+			if err != nil {
+				return err
+			}
+
+			rows := table{
+				headers: []string{"FROM", "TO", "MOVED"},
+				footer:  pageFooter(history.CurrentPage, history.TotalPages, history.TotalRecords),
+			}
+			for _, move := range history.Moves {
+				rows.rows = append(rows.rows, []string{move.FromVersion, move.ToVersion, formatTime(move.MovedAt)})
+			}
+
+			return printOutput(opts.out, opts.output, history, rows)
+		},
+	}
+
+	command.Flags().IntVar(&page, "page", 1, "page number")
+
+	return command
+}
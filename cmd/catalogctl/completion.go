@@ -0,0 +1,159 @@
+package main
+
+import (
+	"github.com/Prashansa-K/serviceCatalog/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// Completions query the server, this bounds how long a <TAB> can take
+const MAX_COMPLETION_PAGES = 10
+
+type completionFunc func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)
+
+// completeServiceNames completes the first argument with service names
+func completeServiceNames(opts *options) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		catalog, err := opts.catalog()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var names []string
+		for page := 1; page <= MAX_COMPLETION_PAGES; page++ {
+			response, err := catalog.ListServices(cmd.Context(), client.ListServicesOptions{Page: page, Name: toComplete})
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			for _, service := range response.Services {
+				names = append(names, service.Name)
+			}
+
+			if page >= response.TotalPages {
+				break
+			}
+		}
+
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeVersionNames completes a service name, then one of its versions
+func completeVersionNames(opts *options) completionFunc {
+	completeServices := completeServiceNames(opts)
+
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 1 {
+			return completeServices(cmd, args, toComplete)
+		}
+
+		catalog, err := opts.catalog()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var names []string
+		for page := 1; page <= MAX_COMPLETION_PAGES; page++ {
+			service, err := catalog.GetService(cmd.Context(), args[0], page)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			for _, version := range service.Versions {
+				names = append(names, version.Name)
+			}
+
+			if page >= service.TotalPages {
+				break
+			}
+		}
+
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeChannelNames completes a service name, then one of its channels
+func completeChannelNames(opts *options) completionFunc {
+	completeServices := completeServiceNames(opts)
+
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completeServices(cmd, args, toComplete)
+		}
+		if len(args) != 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		catalog, err := opts.catalog()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		channels, err := catalog.ListChannels(cmd.Context(), args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var names []string
+		for _, channel := range channels {
+			names = append(names, channel.Name)
+		}
+
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeEnvironmentNames completes the argument at position with an
+// environment name
+func completeEnvironmentNames(opts *options, position int) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != position {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		catalog, err := opts.catalog()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		environments, err := catalog.ListEnvironments(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var names []string
+		for _, environment := range environments {
+			names = append(names, environment.Name)
+		}
+
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeDeployment completes a service name, one of its versions, then an
+// environment name
+func completeDeployment(opts *options) completionFunc {
+	completeVersions := completeVersionNames(opts)
+	completeEnvironments := completeEnvironmentNames(opts, 2)
+
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) < 2 {
+			return completeVersions(cmd, args, toComplete)
+		}
+		return completeEnvironments(cmd, args, toComplete)
+	}
+}
+
+func completeContexts(opts *options) []string {
+	config, err := opts.loadConfig()
+	if err != nil {
+		return nil
+	}
+
+	return config.ContextNames()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CONFIG_FILE_NAME   = ".catalogctl.yaml"
+	CONFIG_ENV         = "CATALOGCTL_CONFIG"
+	DEFAULT_SERVER_URL = "http://localhost:8080"
+)
+
+// Context holds what is needed to talk to one catalog deployment
+type Context struct {
+	Server string `yaml:"server"`
+	Key    string `yaml:"key,omitempty"`
+}
+
+type Config struct {
+	CurrentContext string             `yaml:"current-context,omitempty"`
+	Contexts       map[string]Context `yaml:"contexts,omitempty"`
+
+	path string
+}
+
+// configPath resolves the dotfile location: --config, then $CATALOGCTL_CONFIG,
+// then ~/.catalogctl.yaml
+func configPath(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	if path := os.Getenv(CONFIG_ENV); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, CONFIG_FILE_NAME), nil
+}
+
+// loadConfig reads the dotfile, a missing file being an empty config
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Contexts: map[string]Context{}, path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if config.Contexts == nil {
+		config.Contexts = map[string]Context{}
+	}
+
+	return config, nil
+}
+
+func (c *Config) Save() error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	// the file holds API keys
+	return os.WriteFile(c.path, data, 0600)
+}
+
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Resolve picks the named context, or the current one when name is empty.
+// Having no context at all is fine and falls back to the local server.
+func (c *Config) Resolve(name string) (Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+
+	if name == "" {
+		return Context{Server: DEFAULT_SERVER_URL}, nil
+	}
+
+	context, ok := c.Contexts[name]
+	if !ok {
+		return Context{}, &usageError{fmt.Errorf("context %q not found in %s", name, c.path)}
+	}
+
+	return context, nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand(opts *options) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the contexts of the config file",
+	}
+
+	command.AddCommand(
+		newSetContextCommand(opts),
+		newUseContextCommand(opts),
+		newGetContextsCommand(opts),
+		newDeleteContextCommand(opts),
+	)
+
+	return command
+}
+
+func (o *options) loadConfig() (*Config, error) {
+	path, err := configPath(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadConfig(path)
+}
+
+func newSetContextCommand(opts *options) *cobra.Command {
+	var (
+		server string
+		key    string
+		use    bool
+	)
+
+	command := &cobra.Command{
+		Use:   "set-context NAME",
+		Short: "Create or update a context",
+		Args:  exactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			context, ok := config.Contexts[args[0]]
+			if !ok {
+				context.Server = DEFAULT_SERVER_URL
+			}
+			if cmd.Flags().Changed("server") {
+				context.Server = server
+			}
+			if cmd.Flags().Changed("key") {
+				context.Key = key
+			}
+			config.Contexts[args[0]] = context
+
+			// the first context becomes the current one
+			if use || config.CurrentContext == "" {
+				config.CurrentContext = args[0]
+			}
+
+			if err := config.Save(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "context %q saved\n", args[0])
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&server, "server", "", "catalog URL (default "+DEFAULT_SERVER_URL+")")
+	command.Flags().StringVar(&key, "key", "", "API key")
+	command.Flags().BoolVar(&use, "use", false, "make it the current context")
+
+	return command
+}
+
+func newUseContextCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-context NAME",
+		Short: "Set the current context",
+		Args:  exactArgs(1),
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return completeContexts(opts), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			if _, err := config.Resolve(args[0]); err != nil {
+				return err
+			}
+
+			config.CurrentContext = args[0]
+			if err := config.Save(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "switched to context %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newGetContextsCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List the contexts, keys are not shown",
+		Args:  exactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			config, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			type contextOutput struct {
+				Name    string `json:"name"`
+				Server  string `json:"server"`
+				Current bool   `json:"current"`
+			}
+
+			var contexts []contextOutput
+			rows := table{headers: []string{"CURRENT", "NAME", "SERVER"}}
+			for _, name := range config.ContextNames() {
+				current := name == config.CurrentContext
+				contexts = append(contexts, contextOutput{Name: name, Server: config.Contexts[name].Server, Current: current})
+
+				marker := ""
+				if current {
+					marker = "*"
+				}
+				rows.rows = append(rows.rows, []string{marker, name, config.Contexts[name].Server})
+			}
+
+			return printOutput(opts.out, opts.output, contexts, rows)
+		},
+	}
+}
+
+func newDeleteContextCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-context NAME",
+		Short: "Remove a context",
+		Args:  exactArgs(1),
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return completeContexts(opts), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			if _, err := config.Resolve(args[0]); err != nil {
+				return err
+			}
+
+			delete(config.Contexts, args[0])
+			if config.CurrentContext == args[0] {
+				config.CurrentContext = ""
+			}
+
+			if err := config.Save(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "context %q deleted\n", args[0])
+			return nil
+		},
+	}
+}
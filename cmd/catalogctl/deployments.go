@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Prashansa-K/serviceCatalog/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newDeploymentsCommand(opts *options) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "deployments",
+		Aliases: []string{"deployment", "deploy"},
+		Short:   "Record and list deployments of service versions",
+	}
+
+	command.AddCommand(
+		newDeploymentsListCommand(opts),
+		newDeploymentsCreateCommand(opts),
+	)
+
+	return command
+}
+
+func newDeploymentsListCommand(opts *options) *cobra.Command {
+	var (
+		page        int
+		environment string
+	)
+
+	command := &cobra.Command{
+		Use:               "list SERVICE",
+		Short:             "List the deployments of a service, latest first",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			history, err := catalog.GetDeploymentHistory(cmd.Context(), args[0], environment, page)
+			if err != nil {
+				return err
+			}
+
+			rows := table{
+				headers: []string{"ENVIRONMENT", "VERSION", "ACTOR", "DEPLOYED"},
+				footer:  pageFooter(history.CurrentPage, history.TotalPages, history.TotalRecords),
+			}
+			for _, deployment := range history.Deployments {
+				rows.rows = append(rows.rows, []string{deployment.Environment, deployment.VersionName, deployment.Actor, formatTime(deployment.DeployedAt)})
+			}
+
+			return printOutput(opts.out, opts.output, history, rows)
+		},
+	}
+
+	command.Flags().IntVar(&page, "page", 1, "page number")
+	command.Flags().StringVar(&environment, "environment", "", "only deployments to this environment")
+
+	return command
+}
+
+func newDeploymentsCreateCommand(opts *options) *cobra.Command {
+	var actor string
+
+	command := &cobra.Command{
+		Use:               "create SERVICE VERSION ENVIRONMENT",
+		Short:             "Record a version of a service being deployed to an environment",
+		Args:              exactArgs(3),
+		ValidArgsFunction: completeDeployment(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if actor == "" {
+				return &usageError{fmt.Errorf("who deployed the version is unknown, set --actor")}
+			}
+
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.Deploy(cmd.Context(), args[0], client.DeployRequest{
+				Version:     args[1],
+				Environment: args[2],
+				Actor:       actor,
+			}); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("version %q of service %q deployed to %q", args[1], args[0], args[2]))
+		},
+	}
+
+	command.Flags().StringVar(&actor, "actor", os.Getenv("USER"), "who, or which pipeline, deployed the version")
+
+	return command
+}
+
+func newEnvironmentsCommand(opts *options) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "environments",
+		Aliases: []string{"environment", "env"},
+		Short:   "List and create environments, and show what runs in them",
+	}
+
+	command.AddCommand(
+		newEnvironmentsListCommand(opts),
+		newEnvironmentsCreateCommand(opts),
+		newEnvironmentsServicesCommand(opts),
+	)
+
+	return command
+}
+
+func newEnvironmentsListCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List environments",
+		Args:  exactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			environments, err := catalog.ListEnvironments(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			rows := table{headers: []string{"NAME", "DESCRIPTION", "CREATED"}}
+			for _, environment := range environments {
+				rows.rows = append(rows.rows, []string{environment.Name, environment.Description, formatTime(environment.CreatedAt)})
+			}
+
+			return printOutput(opts.out, opts.output, environments, rows)
+		},
+	}
+}
+
+func newEnvironmentsCreateCommand(opts *options) *cobra.Command {
+	var description string
+
+	command := &cobra.Command{
+		Use:   "create ENVIRONMENT",
+		Short: "Create an environment",
+		Args:  exactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.CreateEnvironment(cmd.Context(), client.CreateEnvironmentRequest{
+				Name:        args[0],
+				Description: description,
+			}); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("environment %q created", args[0]))
+		},
+	}
+
+	command.Flags().StringVar(&description, "description", "", "description of the environment")
+
+	return command
+}
+
+func newEnvironmentsServicesCommand(opts *options) *cobra.Command {
+	var page int
+
+	command := &cobra.Command{
+		Use:               "services ENVIRONMENT",
+		Short:             "List the version of each service deployed to an environment",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeEnvironmentNames(opts, 0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			response, err := catalog.GetEnvironmentServices(cmd.Context(), args[0], page)
+			if err != nil {
+				return err
+			}
+
+			rows := table{
+				headers: []string{"SERVICE", "VERSION", "ACTOR", "DEPLOYED"},
+				footer:  pageFooter(response.CurrentPage, response.TotalPages, response.TotalRecords),
+			}
+			for _, deployment := range response.Services {
+				rows.rows = append(rows.rows, []string{deployment.ServiceName, deployment.VersionName, deployment.Actor, formatTime(deployment.DeployedAt)})
+			}
+
+			return printOutput(opts.out, opts.output, response, rows)
+		},
+	}
+
+	command.Flags().IntVar(&page, "page", 1, "page number")
+
+	return command
+}
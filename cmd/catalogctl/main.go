@@ -0,0 +1,146 @@
+// catalogctl is the command-line client of the service catalog
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Prashansa-K/serviceCatalog/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, so that scripts can tell failures apart
+const (
+	EXIT_OK           = 0
+	EXIT_ERROR        = 1
+	EXIT_USAGE        = 2
+	EXIT_NOT_FOUND    = 3
+	EXIT_CONFLICT     = 4
+	EXIT_UNAUTHORIZED = 5
+)
+
+// usageError is an invalid invocation, as opposed to a failed request
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+type options struct {
+	configPath string
+	context    string
+	server     string
+	key        string
+	output     string
+
+	out io.Writer
+}
+
+// catalog builds a client for the selected context, --server and --key
+// taking precedence over it
+func (o *options) catalog() (*client.Client, error) {
+	config, err := o.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := config.Resolve(o.context)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.server != "" {
+		target.Server = o.server
+	}
+	if o.key != "" {
+		target.Key = o.key
+	}
+
+	return client.New(target.Server, target.Key), nil
+}
+
+func newRootCommand(out io.Writer) *cobra.Command {
+	opts := &options{out: out}
+
+	root := &cobra.Command{
+		Use:           "catalogctl",
+		Short:         "Manage services and versions of the service catalog",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.SetOut(out)
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &usageError{err}
+	})
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.configPath, "config", "", "config file (default $"+CONFIG_ENV+" or ~/"+CONFIG_FILE_NAME+")")
+	flags.StringVar(&opts.context, "context", "", "context to use instead of the current one")
+	flags.StringVar(&opts.server, "server", "", "catalog URL, overrides the context")
+	flags.StringVar(&opts.key, "key", "", "API key, overrides the context")
+	flags.StringVarP(&opts.output, "output", "o", OUTPUT_TABLE, "output format: table, json or yaml")
+
+	root.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return outputFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	root.RegisterFlagCompletionFunc("context", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return completeContexts(opts), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	root.AddCommand(
+		newServicesCommand(opts),
+		newVersionsCommand(opts),
+		newChannelsCommand(opts),
+		newDeploymentsCommand(opts),
+		newEnvironmentsCommand(opts),
+		newConfigCommand(opts),
+	)
+
+	return root
+}
+
+// exactArgs is cobra.ExactArgs reporting a usage error
+func exactArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(n)(cmd, args); err != nil {
+			return &usageError{err}
+		}
+		return nil
+	}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return EXIT_OK
+	}
+
+	var usage *usageError
+	if errors.As(err, &usage) {
+		return EXIT_USAGE
+	}
+
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		return EXIT_NOT_FOUND
+	case errors.Is(err, client.ErrConflict):
+		return EXIT_CONFLICT
+	case errors.Is(err, client.ErrUnauthorized):
+		return EXIT_UNAUTHORIZED
+	}
+
+	return EXIT_ERROR
+}
+
+func main() {
+	err := newRootCommand(os.Stdout).Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+
+	os.Exit(exitCode(err))
+}
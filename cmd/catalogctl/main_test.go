@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const TEST_API_KEY = "test-key"
+
+// newTestServer answers like the catalog for a "payments" service
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v1/services", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"services":      []map[string]interface{}{{"id": 1, "name": "payments", "description": "Payments", "version_count": 2}},
+			"total_pages":   1,
+			"current_page":  1,
+			"total_records": 1,
+		})
+	})
+
+	mux.HandleFunc("/v1/service/payments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": 1, "name": "payments", "description": "Payments", "version_count": 2,
+			"versions":              []map[string]interface{}{{"name": "v1"}, {"name": "v2"}},
+			"total_pages":           1,
+			"current_page":          1,
+			"total_version_records": 2,
+		})
+	})
+
+	mux.HandleFunc("/v1/service/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode("service not found")
+	})
+
+	mux.HandleFunc("/v1/service/version", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode("version with the same name already exists for this service")
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+TEST_API_KEY {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode("invalid key")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func execute(args ...string) (string, error) {
+	var out bytes.Buffer
+	command := newRootCommand(&out)
+	command.SetArgs(args)
+	err := command.Execute()
+
+	return out.String(), err
+}
+
+func useConfig(t *testing.T) {
+	t.Setenv(CONFIG_ENV, filepath.Join(t.TempDir(), "nested", CONFIG_FILE_NAME))
+}
+
+func TestServicesList_OutputFormats(t *testing.T) {
+	useConfig(t)
+	server := newTestServer(t)
+	args := []string{"services", "list", "--server", server.URL, "--key", TEST_API_KEY}
+
+	out, err := execute(args...)
+	require.NoError(t, err)
+	assert.Regexp(t, `1\s+payments\s+Payments\s+2`, out)
+	assert.Contains(t, out, "page 1 of 1, 1 records")
+
+	out, err = execute(append(args, "-o", "json")...)
+	require.NoError(t, err)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &response))
+	assert.Equal(t, float64(1), response["total_records"])
+
+	out, err = execute(append(args, "-o", "yaml")...)
+	require.NoError(t, err)
+	assert.Contains(t, out, "total_records: 1")
+	assert.Contains(t, out, "    name: payments")
+
+	_, err = execute(append(args, "-o", "xml")...)
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+}
+
+func TestExitCodes(t *testing.T) {
+	useConfig(t)
+	server := newTestServer(t)
+
+	_, err := execute("services", "get", "payments", "--server", server.URL, "--key", TEST_API_KEY)
+	assert.Equal(t, EXIT_OK, exitCode(err))
+
+	_, err = execute("services", "get", "missing", "--server", server.URL, "--key", TEST_API_KEY)
+	assert.Equal(t, EXIT_NOT_FOUND, exitCode(err))
+
+	_, err = execute("versions", "create", "payments", "v1", "--server", server.URL, "--key", TEST_API_KEY)
+	assert.Equal(t, EXIT_CONFLICT, exitCode(err))
+
+	_, err = execute("services", "list", "--server", server.URL, "--key", "wrong-key")
+	assert.Equal(t, EXIT_UNAUTHORIZED, exitCode(err))
+
+	_, err = execute("services", "get")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+
+	_, err = execute("services", "list", "--page", "one")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+
+	assert.Equal(t, EXIT_ERROR, exitCode(errors.New("connection refused")))
+}
+
+func TestContexts(t *testing.T) {
+	useConfig(t)
+	server := newTestServer(t)
+
+	_, err := execute("config", "set-context", "staging", "--server", server.URL, "--key", TEST_API_KEY)
+	require.NoError(t, err)
+	_, err = execute("config", "set-context", "prod", "--server", "http://127.0.0.1:1")
+	require.NoError(t, err)
+
+	// the first context saved is the current one
+	out, err := execute("services", "list", "-o", "json")
+	require.NoError(t, err)
+	assert.Contains(t, out, "payments")
+
+	out, err = execute("config", "get-contexts")
+	require.NoError(t, err)
+	assert.Regexp(t, `\*\s+staging`, out)
+	assert.NotContains(t, out, TEST_API_KEY)
+
+	_, err = execute("config", "use-context", "prod")
+	require.NoError(t, err)
+	_, err = execute("services", "list")
+	assert.Error(t, err)
+
+	// --context overrides the current context
+	_, err = execute("services", "list", "--context", "staging")
+	assert.NoError(t, err)
+
+	_, err = execute("config", "use-context", "unknown")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+}
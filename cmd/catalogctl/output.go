@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+	OUTPUT_YAML  = "yaml"
+)
+
+var outputFormats = []string{OUTPUT_TABLE, OUTPUT_JSON, OUTPUT_YAML}
+
+// table is the tabular rendering of a response
+type table struct {
+	headers []string
+	rows    [][]string
+	footer  string
+}
+
+// printOutput writes value as JSON or YAML, or rows as a table. YAML keys are
+// the JSON ones so that both formats describe the API payloads.
+func printOutput(out io.Writer, format string, value interface{}, rows table) error {
+	switch format {
+	case OUTPUT_JSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+
+	case OUTPUT_YAML:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+
+	case OUTPUT_TABLE:
+		writer := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(rows.headers, "\t"))
+		for _, row := range rows.rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+
+		if rows.footer != "" {
+			fmt.Fprintln(out, rows.footer)
+		}
+		return nil
+	}
+
+	return &usageError{fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(outputFormats, ", "))}
+}
+
+func pageFooter(currentPage, totalPages int, totalRecords int64) string {
+	return fmt.Sprintf("\npage %d of %d, %d records", currentPage, totalPages, totalRecords)
+}
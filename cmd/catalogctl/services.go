@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Prashansa-K/serviceCatalog/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newServicesCommand(opts *options) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "services",
+		Aliases: []string{"service", "svc"},
+		Short:   "List, inspect and change services",
+	}
+
+	command.AddCommand(
+		newServicesListCommand(opts),
+		newServicesGetCommand(opts),
+		newServicesCreateCommand(opts),
+		newServicesUpdateCommand(opts),
+		newServicesDeleteCommand(opts),
+		newServicesAliasesCommand(opts),
+	)
+
+	return command
+}
+
+func newServicesListCommand(opts *options) *cobra.Command {
+	var (
+		page        int
+		sort        string
+		name        string
+		description string
+	)
+
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List services, filtered by name and description",
+		Args:  exactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			response, err := catalog.ListServices(cmd.Context(), client.ListServicesOptions{
+				Page:        page,
+				Sort:        strings.ToUpper(sort),
+				Name:        name,
+				Description: description,
+			})
+			if err != nil {
+				return err
+			}
+
+			rows := table{
+				headers: []string{"ID", "NAME", "DESCRIPTION", "VERSIONS"},
+				footer:  pageFooter(response.CurrentPage, response.TotalPages, response.TotalRecords),
+			}
+			for _, service := range response.Services {
+				rows.rows = append(rows.rows, []string{
+					fmt.Sprint(service.ID), service.Name, service.Description, fmt.Sprint(service.VersionCount),
+				})
+			}
+
+			return printOutput(opts.out, opts.output, response, rows)
+		},
+	}
+
+	command.Flags().IntVar(&page, "page", 1, "page number")
+	command.Flags().StringVar(&sort, "sort", "asc", "sort by name: asc or desc")
+	command.Flags().StringVar(&name, "name", "", "only services whose name contains this")
+	command.Flags().StringVar(&description, "description", "", "only services whose description contains this")
+
+	return command
+}
+
+func newServicesGetCommand(opts *options) *cobra.Command {
+	var page int
+
+	command := &cobra.Command{
+		Use:               "get SERVICE",
+		Short:             "Show a service with a page of its versions",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			service, err := catalog.GetService(cmd.Context(), args[0], page)
+			if err != nil {
+				return err
+			}
+
+			rows := table{
+				headers: []string{"ID", "NAME", "DESCRIPTION", "VERSIONS", "CREATED"},
+				rows: [][]string{{
+					fmt.Sprint(service.ID), service.Name, service.Description, fmt.Sprint(service.VersionCount), formatTime(service.CreatedAt),
+				}},
+			}
+
+			return printOutput(opts.out, opts.output, service, rows)
+		},
+	}
+
+	command.Flags().IntVar(&page, "page", 1, "page of versions to include")
+
+	return command
+}
+
+func newServicesCreateCommand(opts *options) *cobra.Command {
+	var description string
+
+	command := &cobra.Command{
+		Use:   "create SERVICE",
+		Short: "Create a service",
+		Args:  exactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.CreateService(cmd.Context(), client.CreateServiceRequest{
+				Name:        args[0],
+				Description: description,
+			}); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("service %q created", args[0]))
+		},
+	}
+
+	command.Flags().StringVar(&description, "description", "", "description of the service")
+
+	return command
+}
+
+func newServicesUpdateCommand(opts *options) *cobra.Command {
+	var (
+		name        string
+		description string
+	)
+
+	command := &cobra.Command{
+		Use:               "update SERVICE",
+		Short:             "Rename a service or change its description",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name == "" && description == "" {
+				return &usageError{fmt.Errorf("nothing to update, set --name or --description")}
+			}
+
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			// the API updates services by ID
+			service, err := catalog.GetService(cmd.Context(), args[0], 1)
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.UpdateService(cmd.Context(), client.UpdateServiceRequest{
+				ID:          service.ID,
+				Name:        name,
+				Description: description,
+			}); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("service %q updated", args[0]))
+		},
+	}
+
+	command.Flags().StringVar(&name, "name", "", "new name of the service")
+	command.Flags().StringVar(&description, "description", "", "new description of the service")
+
+	return command
+}
+
+func newServicesDeleteCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete SERVICE",
+		Short:             "Delete a service along with all its versions",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.DeleteService(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("service %q deleted", args[0]))
+		},
+	}
+}
+
+func newServicesAliasesCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "aliases SERVICE",
+		Short:             "List the previous names of a service, which keep resolving to it",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			aliases, err := catalog.ListServiceAliases(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			rows := table{headers: []string{"NAME", "RENAMED"}}
+			for _, alias := range aliases {
+				rows.rows = append(rows.rows, []string{alias.Name, formatTime(alias.RenamedAt)})
+			}
+
+			return printOutput(opts.out, opts.output, aliases, rows)
+		},
+	}
+}
+
+// printMessage outputs the confirmation of a mutation
+func printMessage(opts *options, message string) error {
+	return printOutput(opts.out, opts.output, map[string]string{"message": message}, table{
+		headers: []string{"MESSAGE"},
+		rows:    [][]string{{message}},
+	})
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newVersionsCommand(opts *options) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "versions",
+		Aliases: []string{"version"},
+		Short:   "List, create and delete versions of a service and show its changelog",
+	}
+
+	command.AddCommand(
+		newVersionsListCommand(opts),
+		newVersionsCreateCommand(opts),
+		newVersionsDeleteCommand(opts),
+		newVersionsChangelogCommand(opts),
+	)
+
+	return command
+}
+
+func newVersionsListCommand(opts *options) *cobra.Command {
+	var page int
+
+	command := &cobra.Command{
+		Use:               "list SERVICE",
+		Short:             "List the versions of a service",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			service, err := catalog.GetService(cmd.Context(), args[0], page)
+			if err != nil {
+				return err
+			}
+
+			rows := table{
+				headers: []string{"NAME", "DESCRIPTION", "CREATED"},
+				footer:  pageFooter(service.CurrentPage, service.TotalPages, service.TotalVersionRecords),
+			}
+			for _, version := range service.Versions {
+				rows.rows = append(rows.rows, []string{version.Name, version.Description, formatTime(version.CreatedAt)})
+			}
+
+			return printOutput(opts.out, opts.output, service.Versions, rows)
+		},
+	}
+
+	command.Flags().IntVar(&page, "page", 1, "page number")
+
+	return command
+}
+
+func newVersionsCreateCommand(opts *options) *cobra.Command {
+	var description string
+	var releaseNotes client.ReleaseNotes
+
+	command := &cobra.Command{
+		Use:               "create SERVICE VERSION",
+		Short:             "Create a version of a service",
+		Args:              exactArgs(2),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if err := catalog.CreateVersion(cmd.Context(), client.CreateVersionRequest{
+				ServiceName:  args[0],
+				Name:         args[1],
+				Description:  description,
+				ReleaseNotes: &releaseNotes,
+			}); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("version %q of service %q created", args[1], args[0]))
+		},
+	}
+
+	command.Flags().StringVar(&description, "description", "", "description of the version")
+	command.Flags().StringArrayVar(&releaseNotes.Added, "added", nil, "release note of a feature added, may be repeated")
+	command.Flags().StringArrayVar(&releaseNotes.Changed, "changed", nil, "release note of a change, may be repeated")
+	command.Flags().StringArrayVar(&releaseNotes.Fixed, "fixed", nil, "release note of a fix, may be repeated")
+	command.Flags().StringArrayVar(&releaseNotes.Security, "security", nil, "release note of a security fix, may be repeated")
+
+	return command
+}
+
+func newVersionsDeleteCommand(opts *options) *cobra.Command {
+	var force bool
+
+	command := &cobra.Command{
+		Use:               "delete SERVICE VERSION",
+		Short:             "Delete a version of a service",
+		Args:              exactArgs(2),
+		ValidArgsFunction: completeVersionNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			deleteVersion := catalog.DeleteVersion
+			if force {
+				deleteVersion = catalog.ForceDeleteVersion
+			}
+
+			if err := deleteVersion(cmd.Context(), args[0], args[1]); err != nil {
+				return err
+			}
+
+			return printMessage(opts, fmt.Sprintf("version %q of service %q deleted", args[1], args[0]))
+		},
+	}
+
+	command.Flags().BoolVar(&force, "force", false, "delete the version even when it is currently deployed")
+
+	return command
+}
+
+// newVersionsChangelogCommand prints the changelog rendered by the server, or
+// as returned by the API with --output json or yaml
+func newVersionsChangelogCommand(opts *options) *cobra.Command {
+	var from, to, format string
+
+	command := &cobra.Command{
+		Use:               "changelog SERVICE",
+		Short:             "Show the release notes of a range of versions of a service",
+		Args:              exactArgs(1),
+		ValidArgsFunction: completeServiceNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalog, err := opts.catalog()
+			if err != nil {
+				return err
+			}
+
+			if opts.output != OUTPUT_TABLE {
+				changelog, err := catalog.GetChangelog(cmd.Context(), args[0], from, to)
+				if err != nil {
+					return err
+				}
+				return printOutput(opts.out, opts.output, changelog, table{})
+			}
+
+			page, err := catalog.RenderChangelog(cmd.Context(), args[0], from, to, format)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprint(opts.out, page)
+			return err
+		},
+	}
+
+	command.Flags().StringVar(&from, "from", "", "oldest version to include")
+	command.Flags().StringVar(&to, "to", "", "latest version to include")
+	command.Flags().StringVar(&format, "format", "markdown", "format of the changelog: markdown, keep-a-changelog or html")
+	command.RegisterFlagCompletionFunc("format", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return []string{"markdown", "keep-a-changelog", "html"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return command
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
@@ -2,28 +2,337 @@
 package main
 
 import (
-	"log"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	servicecatalog "github.com/Prashansa-K/serviceCatalog"
 	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/rpc"
+	"github.com/Prashansa-K/serviceCatalog/internal/cache"
+	"github.com/Prashansa-K/serviceCatalog/internal/certs"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
 	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/deprecation"
+	"github.com/Prashansa-K/serviceCatalog/internal/events"
+	"github.com/Prashansa-K/serviceCatalog/internal/health"
+	"github.com/Prashansa-K/serviceCatalog/internal/logging"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
+	"github.com/Prashansa-K/serviceCatalog/internal/outbox"
 	"github.com/Prashansa-K/serviceCatalog/internal/routes"
+	"github.com/Prashansa-K/serviceCatalog/internal/stats"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
 
 	"github.com/labstack/echo/v4"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	_, err := db.GetDB()
+	if err := newRootCommand().Execute(); err != nil {
+		fatal("error running the command", err)
+	}
+}
+
+// fatal logs err and exits, when the service can not run
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "service-catalog",
+		Short:         "Serve the service catalog",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serviceConfig, err := config.Load(cmd.Flags())
+			if err != nil {
+				return fmt.Errorf("invalid configuration:\n%w", err)
+			}
+			config.Set(serviceConfig)
+
+			run(cmd.Flags())
+			return nil
+		},
+	}
+	config.Flags(root.PersistentFlags())
+
+	root.AddCommand(newConfigCommand())
+
+	return root
+}
+
+func newConfigCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
 
+	command.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the configuration the service would run with, secrets redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serviceConfig, err := config.Load(cmd.Flags())
+			if err != nil {
+				return fmt.Errorf("invalid configuration:\n%w", err)
+			}
+
+			data, err := serviceConfig.YAML()
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	})
+
+	return command
+}
+
+func run(flags *pflag.FlagSet) {
+	// SIGTERM starts the shutdown, e.g. on rolling deploys
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
+
+	// Logs of the requests and of the service, JSON to stdout or to a file
+	logFile, err := logging.Init(config.GetLogConfig())
 	if err != nil {
-		log.Fatal("Can not connect to DB: ", err)
+		fatal("can not set up logging", err)
+	}
+	defer logFile.Close()
+
+	// Pages are sized once, for clients going through them not to skip any
+	constants.PAGE_SIZE = config.GetPaginationConfig().PageSize
+
+	// Spans of the requests, of their controller calls and of their queries
+	stopTracing, err := tracing.Init(context.Background(), config.GetTracingConfig())
+	if err != nil {
+		fatal("can not set up tracing", err)
+	}
+
+	database, err := db.GetDB()
+
+	if err != nil {
+		fatal("can not connect to DB", err)
+	}
+
+	// Replicas serve the reads while they answer, the primary otherwise
+	replicasCtx, stopReplicaChecks := context.WithCancel(context.Background())
+	defer stopReplicaChecks()
+	go db.CheckReplicas(replicasCtx, config.GetDBConfig().ReplicaCheckInterval)
+
+	// Dispatch the recorded events to the streams, created before serving them
+	dispatcher, err := controllers.NewEventDispatcher(database)
+	if err != nil {
+		fatal("can not dispatch events", err)
+	}
+	go dispatcher.Run(context.Background())
+
+	// Gauges of the services and versions in the catalog, on /metrics
+	statsCtx, stopStats := context.WithCancel(context.Background())
+	defer stopStats()
+	go stats.Run(statsCtx, config.GetCatalogMetricsConfig())
+
+	// Relay the events written to the outbox by the controllers
+	outboxConfig := config.GetOutboxConfig()
+	sinks, err := outbox.NewSinks(outboxConfig)
+	if err != nil {
+		fatal("can not configure outbox sinks", err)
+	}
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outbox.NewRelay(database, sinks, outboxConfig).Run(relayCtx)
+	}()
+
+	// Attributes of services must match the schema configured by the admin
+	if err := metadata.LoadAttributesSchema(config.GetMetadataConfig().AttributesSchemaPath); err != nil {
+		fatal("can not load the service attributes schema", err)
+	}
+
+	// Routes being retired are signalled to their clients, then refused
+	if err := deprecation.LoadRoutes(config.GetDeprecationConfig().RoutesPath); err != nil {
+		fatal("can not load the deprecated routes", err)
+	}
+
+	// Cache the service lookups, mutations drop the entries they affect
+	cacheConfig := config.GetCacheConfig()
+	if cacheConfig.TTL > 0 {
+		controllers.EnableCache(cache.New(cacheConfig.TTL, cacheConfig.MaxEntries))
+	}
+
+	latestMigration, err := servicecatalog.LatestMigration()
+	if err != nil {
+		fatal("can not read the migrations shipped", err)
 	}
 
 	serverConfig := config.GetServerConfig()
+	metricsServerConfig := config.GetMetricsServerConfig()
 
 	app := echo.New()
 
 	// Register the routes with the database connection
 	routes.RegisterRoutes(app)
 
-	app.Logger.Fatal(app.Start(serverConfig.Address))
+	// streams never end on their own, they are closed for the shutdown to
+	// complete
+	app.Server.RegisterOnShutdown(events.Close)
+	app.TLSServer.RegisterOnShutdown(events.Close)
+
+	metricsServer := routes.NewMetricsServer()
+
+	// Readiness fails while any of these does
+	health.Register("db", health.DBCheck(database))
+	health.Register("migrations", health.MigrationsCheck(database, latestMigration))
+	health.Register("metrics", health.ServerCheck("metrics", metricsServer))
+
+	go serve(metricsServer, metricsServerConfig.Address, serverTLS(signals, config.GetMetricsTLSConfig()))
+
+	// gRPC API, served alongside the REST APIs using the same controllers
+	grpcServerConfig := config.GetGRPCServerConfig()
+	listener, err := net.Listen("tcp", grpcServerConfig.Address)
+	if err != nil {
+		fatal("can not listen for gRPC", err)
+	}
+	grpcServer := rpc.NewServer()
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			fatal("error serving gRPC", err)
+		}
+	}()
+
+	go serve(app, serverConfig.Address, serverTLS(signals, config.GetTLSConfig()))
+
+	// SIGHUP reloads the settings safe to change while serving
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			reload(app, flags)
+		}
+	}()
+
+	<-signals.Done()
+	stopSignals()
+
+	shutdown(app, metricsServer, grpcServer, config.GetShutdownConfig())
+
+	stopRelay()
+	<-relayDone
+
+	// the spans of the last requests are flushed
+	ctx, cancel := context.WithTimeout(context.Background(), config.GetShutdownConfig().Timeout)
+	defer cancel()
+	if err := stopTracing(ctx); err != nil {
+		slog.Error("error flushing the spans", "error", err)
+	}
+}
+
+// reload applies the settings safe to change while serving, and reads the
+// files they point to again. The other changes are logged as needing a
+// restart.
+func reload(app *echo.Echo, flags *pflag.FlagSet) {
+	next, err := config.Load(flags)
+	if err != nil {
+		slog.Error("error reloading the configuration, keeping the current one", "error", err)
+		return
+	}
+
+	reloaded, applied, ignored := config.Current().Reloaded(next)
+
+	// the previous schema and routes are kept when the files are not usable
+	if err := metadata.LoadAttributesSchema(reloaded.Metadata.AttributesSchemaPath); err != nil {
+		slog.Error("error reloading the service attributes schema", "error", err)
+	}
+	if err := deprecation.LoadRoutes(reloaded.Deprecation.RoutesPath); err != nil {
+		slog.Error("error reloading the deprecated routes", "error", err)
+	}
+
+	config.Set(reloaded)
+	routes.SetLogLevel(app, reloaded.Log.Level)
+
+	slog.Info("configuration reloaded", "changed", applied)
+	if len(ignored) > 0 {
+		slog.Warn("changes applied on restart only", "changed", ignored)
+	}
+}
+
+// serverTLS is nil when TLS is not configured, the files being reloaded
+// whenever they change until ctx is done otherwise
+func serverTLS(ctx context.Context, tlsConfig *config.TLSConfig) *tls.Config {
+	if !tlsConfig.Enabled() {
+		return nil
+	}
+
+	reloader, err := certs.NewReloader(tlsConfig)
+	if err != nil {
+		fatal("can not load the TLS certificate", err)
+	}
+	go reloader.Watch(ctx)
+
+	return reloader.TLSConfig()
+}
+
+func serve(server *echo.Echo, address string, tlsConfig *tls.Config) {
+	var err error
+	if tlsConfig == nil {
+		err = server.Start(address)
+	} else {
+		server.TLSServer.Addr = address
+		server.TLSServer.TLSConfig = tlsConfig
+		err = server.StartServer(server.TLSServer)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatal("error serving "+address, err)
+	}
+}
+
+// shutdown drains the process, then lets in-flight requests and calls
+// complete within the timeout
+func shutdown(app, metricsServer *echo.Echo, grpcServer *grpc.Server, shutdownConfig *config.ShutdownConfig) {
+	slog.Info("draining before shutting down", "delay", shutdownConfig.DrainDelay)
+	health.Drain()
+	time.Sleep(shutdownConfig.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownConfig.Timeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		grpcServer.GracefulStop()
+	}()
+
+	if err := app.Shutdown(ctx); err != nil {
+		slog.Error("error shutting down the API server", "error", err)
+	}
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		slog.Error("error shutting down the gRPC server", "error", ctx.Err())
+		grpcServer.Stop()
+	}
+
+	// the metrics server is stopped last, to scrape the shutdown too
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		slog.Error("error shutting down the metrics server", "error", err)
+	}
 }
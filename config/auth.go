@@ -0,0 +1,27 @@
+package config
+
+import (
+	"strings"
+)
+
+// AuthConfig authenticates the callers of every API (REST, GraphQL and gRPC)
+type AuthConfig struct {
+	// bearer key, none being accepted when empty
+	Key string `yaml:"key" env:"API_AUTH_KEY" secret:"true" reload:"true"`
+	// subjects of the client certificates accepted in place of the key, any
+	// verified certificate being when empty. Subjects hold commas, so the
+	// variable separates them with semicolons.
+	Subjects []string `yaml:"subjects" env:"API_AUTH_SUBJECTS" separator:";" reload:"true"`
+}
+
+func (c *AuthConfig) validate(v *validator, tls *TLSConfig) {
+	v.check(c.Key == strings.TrimSpace(c.Key), "auth.key", "must not start or end with spaces")
+	v.check(len(c.Subjects) == 0 || tls.ClientCAFile != "", "auth.subjects", "needs tls.client_ca_file to verify certificates")
+	for _, subject := range c.Subjects {
+		v.check(strings.Contains(subject, "="), "auth.subjects", "%q is not a subject, e.g. CN=billing,O=Payments", subject)
+	}
+}
+
+func GetAuthConfig() *AuthConfig {
+	return &Current().Auth
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	DEFAULT_CACHE_TTL         = 30 * time.Second
+	DEFAULT_CACHE_MAX_ENTRIES = 1000
+)
+
+type CacheConfig struct {
+	// a TTL of 0 disables the cache
+	TTL        time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	MaxEntries int           `yaml:"max_entries" env:"CACHE_MAX_ENTRIES"`
+}
+
+func defaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		TTL:        DEFAULT_CACHE_TTL,
+		MaxEntries: DEFAULT_CACHE_MAX_ENTRIES,
+	}
+}
+
+func (c *CacheConfig) validate(v *validator) {
+	v.check(c.TTL >= 0, "cache.ttl", "must not be negative")
+	v.check(c.MaxEntries >= 1, "cache.max_entries", "must be at least 1")
+}
+
+func GetCacheConfig() *CacheConfig {
+	return &Current().Cache
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"regexp"
+	"time"
+)
+
+const (
+	DEFAULT_CATALOG_METRICS_INTERVAL = 1 * time.Minute
+)
+
+// paths of attributes, e.g. team or owner.team, as filtered on by the APIs
+var attributePathPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+type CatalogMetricsConfig struct {
+	// between two counts of the services and versions
+	Interval time.Duration `yaml:"interval" env:"CATALOG_METRICS_INTERVAL"`
+	// services and versions are also counted per value of these attributes,
+	// which should take few values
+	Attributes []string `yaml:"attributes" env:"CATALOG_METRICS_ATTRIBUTES"`
+}
+
+func defaultCatalogMetricsConfig() CatalogMetricsConfig {
+	return CatalogMetricsConfig{
+		Interval: DEFAULT_CATALOG_METRICS_INTERVAL,
+	}
+}
+
+func (c *CatalogMetricsConfig) validate(v *validator) {
+	v.check(c.Interval > 0, "catalog_metrics.interval", "must be positive")
+	for _, path := range c.Attributes {
+		v.check(attributePathPattern.MatchString(path), "catalog_metrics.attributes", "invalid attribute path %q", path)
+	}
+}
+
+func GetCatalogMetricsConfig() *CatalogMetricsConfig {
+	return &Current().CatalogMetrics
+}
@@ -0,0 +1,385 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// flag and environment variable pointing to the YAML file, the flag
+	// taking precedence
+	CONFIG_FLAG = "config"
+	CONFIG_ENV  = "CONFIG_FILE"
+
+	REDACTED = "REDACTED"
+)
+
+// Config is the configuration of the service as a whole. Each setting comes
+// from, in increasing order of precedence: its default, the YAML file, its
+// environment variable and its flag, named after its path in the file, e.g.
+// --db.max_open_conns.
+//
+// Settings tagged with reload are applied on SIGHUP, the others on restart.
+type Config struct {
+	Server         ServerConfig         `yaml:"server"`
+	MetricsServer  MetricsServerConfig  `yaml:"metrics_server"`
+	CatalogMetrics CatalogMetricsConfig `yaml:"catalog_metrics"`
+	GRPCServer     GRPCServerConfig     `yaml:"grpc_server"`
+	TLS            TLSConfig            `yaml:"tls"`
+	// same variables as the API's, prefixed
+	MetricsTLS  TLSConfig         `yaml:"metrics_tls" env:"METRICS_"`
+	Auth        AuthConfig        `yaml:"auth"`
+	DB          DBConfig          `yaml:"db"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Metadata    MetadataConfig    `yaml:"metadata"`
+	Deprecation DeprecationConfig `yaml:"deprecation"`
+	OpenAPI     OpenAPIConfig     `yaml:"openapi"`
+	Shutdown    ShutdownConfig    `yaml:"shutdown"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Pagination  PaginationConfig  `yaml:"pagination"`
+	Log         LogConfig         `yaml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+}
+
+// Default is the configuration when nothing is set
+func Default() *Config {
+	return &Config{
+		Server:         defaultServerConfig(),
+		MetricsServer:  defaultMetricsServerConfig(),
+		CatalogMetrics: defaultCatalogMetricsConfig(),
+		GRPCServer:     defaultGRPCServerConfig(),
+		TLS:            defaultTLSConfig(),
+		MetricsTLS:     defaultTLSConfig(),
+		DB:             defaultDBConfig(),
+		Cache:          defaultCacheConfig(),
+		Outbox:         defaultOutboxConfig(),
+		OpenAPI:        defaultOpenAPIConfig(),
+		Shutdown:       defaultShutdownConfig(),
+		RateLimit:      defaultRateLimitConfig(),
+		Pagination:     defaultPaginationConfig(),
+		Log:            defaultLogConfig(),
+		Tracing:        defaultTracingConfig(),
+	}
+}
+
+var current atomic.Pointer[Config]
+
+// Current is the configuration set at startup, or the defaults overridden by
+// the environment when none was, e.g. in tests
+func Current() *Config {
+	if config := current.Load(); config != nil {
+		return config
+	}
+
+	config, err := Load(nil)
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	current.CompareAndSwap(nil, config)
+
+	return current.Load()
+}
+
+// Set replaces the current configuration, which must not be modified
+// afterwards
+func Set(config *Config) {
+	current.Store(config)
+}
+
+// Flags registers --config and a flag per setting
+func Flags(flags *pflag.FlagSet) {
+	flags.String(CONFIG_FLAG, "", "YAML configuration file (default $"+CONFIG_ENV+")")
+
+	for _, setting := range settings(Default()) {
+		flags.String(setting.path, "", "overrides $"+setting.env)
+	}
+}
+
+// Load reads the configuration from the YAML file set by --config or
+// $CONFIG_FILE if any, the environment and the flags if any, and checks it
+func Load(flags *pflag.FlagSet) (*Config, error) {
+	path := os.Getenv(CONFIG_ENV)
+	if flags != nil && flags.Changed(CONFIG_FLAG) {
+		path, _ = flags.GetString(CONFIG_FLAG)
+	}
+
+	config := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// unknown keys are most likely misspelled
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var errs []error
+	for _, setting := range settings(config) {
+		if value := os.Getenv(setting.env); value != "" {
+			if err := setting.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s ($%s): %w", setting.path, setting.env, err))
+			}
+		}
+
+		if flags != nil && flags.Changed(setting.path) {
+			value, _ := flags.GetString(setting.path)
+			if err := setting.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s (--%s): %w", setting.path, setting.path, err))
+			}
+		}
+	}
+
+	config.complete()
+
+	if err := config.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return config, nil
+}
+
+// complete derives the settings computed from others
+func (c *Config) complete() {
+	c.Server.Address = fmt.Sprintf("%s:%s", c.Server.Host, c.Server.Port)
+	c.MetricsServer.Address = fmt.Sprintf("%s:%s", c.MetricsServer.Host, c.MetricsServer.Port)
+	c.GRPCServer.Address = fmt.Sprintf("%s:%s", c.GRPCServer.Host, c.GRPCServer.Port)
+	c.DB.DSN = c.DB.dsn()
+
+	c.OpenAPI.ValidationMode = strings.ToLower(c.OpenAPI.ValidationMode)
+	c.Log.Output = strings.ToLower(c.Log.Output)
+	c.Log.Level = strings.ToLower(c.Log.Level)
+	c.Tracing.Exporter = strings.ToLower(c.Tracing.Exporter)
+}
+
+// Validate tells every invalid setting at once, by its path and variable
+func (c *Config) Validate() error {
+	v := &validator{envs: map[string]string{}}
+	for _, setting := range settings(c) {
+		v.envs[setting.path] = setting.env
+	}
+
+	c.Server.validate(v, "server")
+	c.MetricsServer.validate(v, "metrics_server")
+	c.GRPCServer.validate(v, "grpc_server")
+	c.TLS.validate(v, "tls")
+	c.MetricsTLS.validate(v, "metrics_tls")
+	c.Auth.validate(v, &c.TLS)
+	c.DB.validate(v)
+	c.Cache.validate(v)
+	c.CatalogMetrics.validate(v)
+	c.Outbox.validate(v)
+	c.OpenAPI.validate(v)
+	c.Shutdown.validate(v)
+	c.RateLimit.validate(v)
+	c.Pagination.validate(v)
+	c.Log.validate(v)
+	c.Tracing.validate(v)
+
+	return errors.Join(v.errs...)
+}
+
+// Reloaded applies the settings tagged with reload from next, telling the
+// ones applied and the ones changed that need a restart
+func (c *Config) Reloaded(next *Config) (*Config, []string, []string) {
+	reloaded := *c
+
+	var applied, ignored []string
+	nextSettings := settings(next)
+	for i, setting := range settings(&reloaded) {
+		if reflect.DeepEqual(setting.value.Interface(), nextSettings[i].value.Interface()) {
+			continue
+		}
+
+		if !setting.reload {
+			ignored = append(ignored, setting.path)
+			continue
+		}
+
+		setting.value.Set(nextSettings[i].value)
+		applied = append(applied, setting.path)
+	}
+
+	return &reloaded, applied, ignored
+}
+
+// YAML is the configuration as it would be written in the file, the secrets
+// being redacted
+func (c *Config) YAML() ([]byte, error) {
+	redacted := *c
+	for _, setting := range settings(&redacted) {
+		if !setting.secret || setting.value.IsZero() {
+			continue
+		}
+
+		// lists are replaced rather than changed, being shared with c
+		if items, ok := setting.value.Interface().([]string); ok {
+			redactedItems := make([]string, len(items))
+			for i := range redactedItems {
+				redactedItems[i] = REDACTED
+			}
+			setting.value.Set(reflect.ValueOf(redactedItems))
+			continue
+		}
+
+		setting.value.SetString(REDACTED)
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&redacted); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// setting is a leaf of the configuration
+type setting struct {
+	// in the YAML file, also the name of the flag
+	path   string
+	env    string
+	secret bool
+	reload bool
+	// of the items of a list in a variable or a flag
+	separator string
+	value     reflect.Value
+}
+
+// settings lists the leaves of the configuration, in the order of the fields
+func settings(config *Config) []setting {
+	return walk(reflect.ValueOf(config).Elem(), "", "")
+}
+
+func walk(value reflect.Value, pathPrefix, envPrefix string) []setting {
+	var leaves []setting
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		path := pathPrefix + name
+		env := envPrefix + field.Tag.Get("env")
+
+		if field.Type.Kind() == reflect.Struct {
+			leaves = append(leaves, walk(value.Field(i), path+".", env)...)
+			continue
+		}
+
+		separator := field.Tag.Get("separator")
+		if separator == "" {
+			separator = ","
+		}
+
+		leaves = append(leaves, setting{
+			path:      path,
+			env:       env,
+			secret:    field.Tag.Get("secret") == "true",
+			reload:    field.Tag.Get("reload") == "true",
+			separator: separator,
+			value:     value.Field(i),
+		})
+	}
+
+	return leaves
+}
+
+// set parses the value of a variable or a flag, lists being comma-separated
+// unless the field tells another separator
+func (s setting) set(raw string) error {
+	switch s.value.Interface().(type) {
+	case time.Duration:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(duration))
+	case string:
+		s.value.SetString(raw)
+	case int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(number))
+	case float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		s.value.SetFloat(number)
+	case bool:
+		boolean, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetBool(boolean)
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, s.separator) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		s.value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", s.value.Type())
+	}
+
+	return nil
+}
+
+// validator gathers the invalid settings
+type validator struct {
+	envs map[string]string
+	errs []error
+}
+
+func (v *validator) check(valid bool, path, format string, args ...interface{}) {
+	if valid {
+		return
+	}
+
+	v.errs = append(v.errs, fmt.Errorf("%s ($%s): %s", path, v.envs[path], fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) checkPort(path, port string) {
+	number, err := strconv.Atoi(port)
+	v.check(err == nil && number > 0 && number < 65536, path, "%q is not a port", port)
+}
+
+func (v *validator) checkOneOf(path, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+
+	v.check(false, path, "%q is not one of %s", value, strings.Join(allowed, ", "))
+}
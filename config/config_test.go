@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func parseFlags(t *testing.T, args ...string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("service-catalog", pflag.ContinueOnError)
+	Flags(flags)
+	require.NoError(t, flags.Parse(args))
+	return flags
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfigFile(t, `
+cache:
+  ttl: 1m
+rate_limit:
+  rps: 2
+db:
+  host: file
+`)
+	t.Setenv("CACHE_TTL", "2m")
+	t.Setenv("DB_HOST", "env")
+	t.Setenv("METRICS_TLS_RELOAD_INTERVAL", "1m")
+
+	config, err := Load(parseFlags(t, "--config", path, "--cache.ttl", "3m", "--outbox.sinks", "stdout, file"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3*time.Minute, config.Cache.TTL, "flags override variables")
+	assert.Equal(t, "env", config.DB.Host, "variables override the file")
+	assert.Equal(t, 2.0, config.RateLimit.RPS, "the file overrides defaults")
+	assert.Equal(t, DEFAULT_CACHE_MAX_ENTRIES, config.Cache.MaxEntries)
+	assert.Equal(t, []string{"stdout", "file"}, config.Outbox.Sinks)
+	assert.Equal(t, time.Minute, config.MetricsTLS.ReloadInterval)
+	assert.Equal(t, DEFAULT_TLS_RELOAD_INTERVAL, config.TLS.ReloadInterval)
+
+	assert.Equal(t, "localhost:8080", config.Server.Address)
+	assert.Equal(t, "host=env port=5432 user=postgres password= dbname=servicecatalog sslmode=disable", config.DB.DSN)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(parseFlags(t, "--config", writeConfigFile(t, "cache:\n  max_entrie: 3\n")))
+	assert.ErrorContains(t, err, "field max_entrie not found")
+
+	t.Setenv("RATE_LIMIT_BURST", "ten")
+	t.Setenv("DB_SSLMODE", "on")
+	t.Setenv("TLS_CERT_FILE", "tls.crt")
+
+	// every invalid setting is told at once
+	_, err = Load(parseFlags(t, "--server.port", "99999"))
+	assert.EqualError(t, err, `rate_limit.burst ($RATE_LIMIT_BURST): strconv.Atoi: parsing "ten": invalid syntax
+server.port ($SERVICE_PORT): "99999" is not a port
+tls.key_file ($TLS_KEY_FILE): a certificate and its key are set together
+db.sslmode ($DB_SSLMODE): "on" is not one of disable, allow, prefer, require, verify-ca, verify-full`)
+}
+
+func TestLoad_AuthSubjects(t *testing.T) {
+	t.Setenv("API_AUTH_SUBJECTS", "CN=billing,O=Payments; CN=shipping,O=Payments")
+
+	_, err := Load(nil)
+	assert.EqualError(t, err, "auth.subjects ($API_AUTH_SUBJECTS): needs tls.client_ca_file to verify certificates")
+
+	t.Setenv("TLS_CERT_FILE", "tls.crt")
+	t.Setenv("TLS_KEY_FILE", "tls.key")
+	t.Setenv("TLS_CLIENT_CA_FILE", "ca.crt")
+
+	// subjects hold commas
+	config, err := Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CN=billing,O=Payments", "CN=shipping,O=Payments"}, config.Auth.Subjects)
+}
+
+func TestReloaded(t *testing.T) {
+	current := Default()
+	current.complete()
+
+	next := Default()
+	next.RateLimit.RPS = 1
+	next.Log.Level = LOG_LEVEL_DEBUG
+	next.Server.Port = "9000"
+	next.complete()
+
+	reloaded, applied, ignored := current.Reloaded(next)
+
+	assert.Equal(t, []string{"rate_limit.rps", "log.level"}, applied)
+	assert.Equal(t, []string{"server.port"}, ignored)
+	assert.Equal(t, 1.0, reloaded.RateLimit.RPS)
+	assert.Equal(t, DEFAULT_PORT, reloaded.Server.Port)
+	assert.Equal(t, "localhost:8080", reloaded.Server.Address)
+	assert.Equal(t, float64(DEFAULT_RATE_LIMIT_RPS), current.RateLimit.RPS, "the current configuration is left as is")
+}
+
+func TestYAML_RedactsSecrets(t *testing.T) {
+	config := Default()
+	config.DB.Password = "hunter2"
+	config.Auth.Key = "hunter2"
+	config.DB.Replicas = []string{"host=replica password=hunter2"}
+
+	data, err := config.YAML()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "  password: REDACTED\n")
+	assert.Contains(t, string(data), "  key: REDACTED\n")
+	assert.Contains(t, string(data), "  webhook_url: \"\"\n", "unset secrets are told apart")
+	assert.Contains(t, string(data), "  replicas:\n    - REDACTED\n")
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Equal(t, "hunter2", config.DB.Password)
+	assert.Equal(t, []string{"host=replica password=hunter2"}, config.DB.Replicas)
+}
@@ -2,8 +2,7 @@ package config
 
 import (
 	"fmt"
-
-	utils "github.com/Prashansa-K/serviceCatalog/internal"
+	"time"
 )
 
 const (
@@ -12,33 +11,105 @@ const (
 	DEFAULT_DB_USER     = "postgres"
 	DEFAULT_DB_PASSWORD = ""
 	DEFAULT_DB_NAME     = "servicecatalog"
+	DEFAULT_DB_SSLMODE  = "disable"
+
+	DEFAULT_DB_MAX_OPEN_CONNS     = 20
+	DEFAULT_DB_MAX_IDLE_CONNS     = 10
+	DEFAULT_DB_CONN_MAX_LIFETIME  = 30 * time.Minute
+	DEFAULT_DB_CONN_MAX_IDLE_TIME = 5 * time.Minute
+	DEFAULT_DB_CONNECT_TIMEOUT    = 1 * time.Minute
+
+	DEFAULT_DB_REPLICA_CHECK_INTERVAL  = 5 * time.Second
+	DEFAULT_DB_READ_YOUR_WRITES_WINDOW = 5 * time.Second
 )
 
+// modes of libpq, verify-full being the one checking the server's name
+var dbSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
 type DBConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	DSN      string
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	DBName   string `yaml:"name" env:"DB_NAME"`
+	DSN      string `yaml:"-"`
+
+	SSLMode string `yaml:"sslmode" env:"DB_SSLMODE"`
+	// PEM files, the server's certificate being verified against the root
+	// CA and the client authenticating with its certificate when set
+	SSLRootCert string `yaml:"sslrootcert" env:"DB_SSLROOTCERT"`
+	SSLCert     string `yaml:"sslcert" env:"DB_SSLCERT"`
+	SSLKey      string `yaml:"sslkey" env:"DB_SSLKEY"`
+
+	// unlimited when 0
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// how long connecting is retried at startup
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+
+	// DSNs of read replicas, the reads that can lag behind the writes going
+	// to the healthy ones
+	Replicas             []string      `yaml:"replicas" env:"DB_REPLICAS" secret:"true"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" env:"DB_REPLICA_CHECK_INTERVAL"`
+	// how long the reads of a caller go to the primary after it wrote, longer
+	// than the replication lag
+	ReadYourWritesWindow time.Duration `yaml:"read_your_writes_window" env:"DB_READ_YOUR_WRITES_WINDOW"`
 }
 
-func GetDBConfig() *DBConfig {
-	host := utils.GetEnvWithDefault("DB_HOST", DEFAULT_DB_HOST)
-	port := utils.GetEnvWithDefault("DB_PORT", DEFAULT_DB_PORT)
-	user := utils.GetEnvWithDefault("DB_USER", DEFAULT_DB_USER)
-	password := utils.GetEnvWithDefault("DB_PASSWORD", DEFAULT_DB_PASSWORD)
-	dbName := utils.GetEnvWithDefault("DB_NAME", DEFAULT_DB_NAME)
-
-	return &DBConfig{
-		Host:     host,
-		Port:     port,
-		User:     user,
-		Password: password,
-		DBName:   dbName,
-		DSN: fmt.Sprintf(
-			"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			host, port, user, password, dbName,
-		),
+func defaultDBConfig() DBConfig {
+	return DBConfig{
+		Host:     DEFAULT_DB_HOST,
+		Port:     DEFAULT_DB_PORT,
+		User:     DEFAULT_DB_USER,
+		Password: DEFAULT_DB_PASSWORD,
+		DBName:   DEFAULT_DB_NAME,
+		SSLMode:  DEFAULT_DB_SSLMODE,
+
+		MaxOpenConns:    DEFAULT_DB_MAX_OPEN_CONNS,
+		MaxIdleConns:    DEFAULT_DB_MAX_IDLE_CONNS,
+		ConnMaxLifetime: DEFAULT_DB_CONN_MAX_LIFETIME,
+		ConnMaxIdleTime: DEFAULT_DB_CONN_MAX_IDLE_TIME,
+		ConnectTimeout:  DEFAULT_DB_CONNECT_TIMEOUT,
+
+		ReplicaCheckInterval: DEFAULT_DB_REPLICA_CHECK_INTERVAL,
+		ReadYourWritesWindow: DEFAULT_DB_READ_YOUR_WRITES_WINDOW,
 	}
 }
+
+func (c *DBConfig) dsn() string {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode,
+	)
+	if c.SSLRootCert != "" {
+		dsn += " sslrootcert=" + c.SSLRootCert
+	}
+	if c.SSLCert != "" {
+		dsn += " sslcert=" + c.SSLCert
+	}
+	if c.SSLKey != "" {
+		dsn += " sslkey=" + c.SSLKey
+	}
+
+	return dsn
+}
+
+func (c *DBConfig) validate(v *validator) {
+	v.checkPort("db.port", c.Port)
+	v.checkOneOf("db.sslmode", c.SSLMode, dbSSLModes...)
+	v.check((c.SSLCert == "") == (c.SSLKey == ""), "db.sslkey", "a certificate and its key are set together")
+	v.check(c.MaxOpenConns >= 0, "db.max_open_conns", "must not be negative")
+	v.check(c.MaxIdleConns >= 0, "db.max_idle_conns", "must not be negative")
+	v.check(c.ConnMaxLifetime > 0, "db.conn_max_lifetime", "must be positive")
+	v.check(c.ConnMaxIdleTime > 0, "db.conn_max_idle_time", "must be positive")
+	v.check(c.ConnectTimeout > 0, "db.connect_timeout", "must be positive")
+	v.check(c.ReplicaCheckInterval > 0, "db.replica_check_interval", "must be positive")
+	v.check(c.ReadYourWritesWindow >= 0, "db.read_your_writes_window", "must not be negative")
+}
+
+func GetDBConfig() *DBConfig {
+	return &Current().DB
+}
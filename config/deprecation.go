@@ -0,0 +1,10 @@
+package config
+
+type DeprecationConfig struct {
+	// JSON file listing the deprecated routes, none when empty
+	RoutesPath string `yaml:"routes_path" env:"DEPRECATED_ROUTES" reload:"true"`
+}
+
+func GetDeprecationConfig() *DeprecationConfig {
+	return &Current().Deprecation
+}
@@ -0,0 +1,27 @@
+package config
+
+const (
+	DEFAULT_GRPC_HOST = "localhost"
+	DEFAULT_GRPC_PORT = "9090"
+)
+
+type GRPCServerConfig struct {
+	Host    string `yaml:"host" env:"GRPC_HOST"`
+	Port    string `yaml:"port" env:"GRPC_PORT"`
+	Address string `yaml:"-"`
+}
+
+func defaultGRPCServerConfig() GRPCServerConfig {
+	return GRPCServerConfig{
+		Host: DEFAULT_GRPC_HOST,
+		Port: DEFAULT_GRPC_PORT,
+	}
+}
+
+func (c *GRPCServerConfig) validate(v *validator, path string) {
+	v.checkPort(path+".port", c.Port)
+}
+
+func GetGRPCServerConfig() *GRPCServerConfig {
+	return &Current().GRPCServer
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	LOG_LEVEL_DEBUG = "debug"
+	LOG_LEVEL_INFO  = "info"
+	LOG_LEVEL_WARN  = "warn"
+	LOG_LEVEL_ERROR = "error"
+	LOG_LEVEL_OFF   = "off"
+
+	LOG_OUTPUT_STDOUT = "stdout"
+	LOG_OUTPUT_FILE   = "file"
+
+	DEFAULT_LOG_OUTPUT               = LOG_OUTPUT_FILE
+	DEFAULT_LOG_PATH                 = ".log/log_file"
+	DEFAULT_LOG_MAX_SIZE             = 100
+	DEFAULT_LOG_MAX_BACKUPS          = 5
+	DEFAULT_LOG_LEVEL                = LOG_LEVEL_INFO
+	DEFAULT_LOG_SLOW_QUERY_THRESHOLD = 200 * time.Millisecond
+)
+
+type LogConfig struct {
+	// of the request and application logs alike
+	Output string `yaml:"output" env:"LOG_OUTPUT"`
+	// written to by the file output
+	Path string `yaml:"path" env:"LOG_FILE"`
+	// in megabytes, the file being rotated once it reaches it
+	MaxSize int `yaml:"max_size" env:"LOG_MAX_SIZE"`
+	// rotated files kept, the oldest being deleted
+	MaxBackups int    `yaml:"max_backups" env:"LOG_MAX_BACKUPS"`
+	Level      string `yaml:"level" env:"LOG_LEVEL" reload:"true"`
+	// queries taking longer are logged as warnings, the others at debug level
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"LOG_SLOW_QUERY_THRESHOLD"`
+}
+
+func defaultLogConfig() LogConfig {
+	return LogConfig{
+		Output:             DEFAULT_LOG_OUTPUT,
+		Path:               DEFAULT_LOG_PATH,
+		MaxSize:            DEFAULT_LOG_MAX_SIZE,
+		MaxBackups:         DEFAULT_LOG_MAX_BACKUPS,
+		Level:              DEFAULT_LOG_LEVEL,
+		SlowQueryThreshold: DEFAULT_LOG_SLOW_QUERY_THRESHOLD,
+	}
+}
+
+func (c *LogConfig) validate(v *validator) {
+	v.checkOneOf("log.output", c.Output, LOG_OUTPUT_STDOUT, LOG_OUTPUT_FILE)
+	v.check(c.Output != LOG_OUTPUT_FILE || c.Path != "", "log.path", "is required by the file output")
+	v.check(c.MaxSize >= 1, "log.max_size", "must be at least 1")
+	v.check(c.MaxBackups >= 0, "log.max_backups", "must not be negative")
+	v.checkOneOf("log.level", c.Level, LOG_LEVEL_DEBUG, LOG_LEVEL_INFO, LOG_LEVEL_WARN, LOG_LEVEL_ERROR, LOG_LEVEL_OFF)
+	v.check(c.SlowQueryThreshold > 0, "log.slow_query_threshold", "must be positive")
+}
+
+func GetLogConfig() *LogConfig {
+	return &Current().Log
+}
@@ -0,0 +1,10 @@
+package config
+
+type MetadataConfig struct {
+	// JSON Schema the attributes of services must match, none when empty
+	AttributesSchemaPath string `yaml:"attributes_schema_path" env:"SERVICE_ATTRIBUTES_SCHEMA" reload:"true"`
+}
+
+func GetMetadataConfig() *MetadataConfig {
+	return &Current().Metadata
+}
@@ -1,29 +1,27 @@
 package config
 
-import (
-	"fmt"
-
-	utils "github.com/Prashansa-K/serviceCatalog/internal"
-)
-
 const (
 	DEFAULT_METRICS_HOST = "localhost"
 	DEFAULT_METRICS_PORT = "8081"
 )
 
 type MetricsServerConfig struct {
-	Host    string
-	Port    string
-	Address string
+	Host    string `yaml:"host" env:"METRICS_HOST"`
+	Port    string `yaml:"port" env:"METRICS_PORT"`
+	Address string `yaml:"-"`
 }
 
-func GetMetricsServerConfig() *MetricsServerConfig {
-	host := utils.GetEnvWithDefault("METRICS_HOST", DEFAULT_METRICS_HOST)
-	port := utils.GetEnvWithDefault("METRICS_PORT", DEFAULT_METRICS_PORT)
-	return &MetricsServerConfig{
-		Host:    host,
-		Port:    port,
-		Address: fmt.Sprintf("%s:%s", host, port),
+func defaultMetricsServerConfig() MetricsServerConfig {
+	return MetricsServerConfig{
+		Host: DEFAULT_METRICS_HOST,
+		Port: DEFAULT_METRICS_PORT,
 	}
+}
+
+func (c *MetricsServerConfig) validate(v *validator, path string) {
+	v.checkPort(path+".port", c.Port)
+}
 
+func GetMetricsServerConfig() *MetricsServerConfig {
+	return &Current().MetricsServer
 }
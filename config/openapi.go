@@ -0,0 +1,30 @@
+package config
+
+const (
+	// requests and responses are not checked against the spec
+	OPENAPI_VALIDATION_OFF = "off"
+	// mismatches are logged, payloads go through unchanged
+	OPENAPI_VALIDATION_LOG = "log"
+	// mismatching requests are rejected with a 400, mismatching responses replaced by a 500
+	OPENAPI_VALIDATION_ENFORCE = "enforce"
+
+	DEFAULT_OPENAPI_VALIDATION = OPENAPI_VALIDATION_OFF
+)
+
+type OpenAPIConfig struct {
+	ValidationMode string `yaml:"validation_mode" env:"OPENAPI_VALIDATION"`
+}
+
+func defaultOpenAPIConfig() OpenAPIConfig {
+	return OpenAPIConfig{
+		ValidationMode: DEFAULT_OPENAPI_VALIDATION,
+	}
+}
+
+func (c *OpenAPIConfig) validate(v *validator) {
+	v.checkOneOf("openapi.validation_mode", c.ValidationMode, OPENAPI_VALIDATION_OFF, OPENAPI_VALIDATION_LOG, OPENAPI_VALIDATION_ENFORCE)
+}
+
+func GetOpenAPIConfig() *OpenAPIConfig {
+	return &Current().OpenAPI
+}
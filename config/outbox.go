@@ -0,0 +1,40 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	DEFAULT_OUTBOX_SINKS         = "stdout"
+	DEFAULT_OUTBOX_FILE_PATH     = ".log/events"
+	DEFAULT_OUTBOX_POLL_INTERVAL = 1 * time.Second
+	DEFAULT_OUTBOX_BATCH_SIZE    = 100
+)
+
+type OutboxConfig struct {
+	// any of stdout, file and webhook
+	Sinks        []string      `yaml:"sinks" env:"OUTBOX_SINKS"`
+	WebhookURL   string        `yaml:"webhook_url" env:"OUTBOX_WEBHOOK_URL" secret:"true"`
+	FilePath     string        `yaml:"file_path" env:"OUTBOX_FILE_PATH"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+}
+
+func defaultOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		Sinks:        []string{DEFAULT_OUTBOX_SINKS},
+		FilePath:     DEFAULT_OUTBOX_FILE_PATH,
+		PollInterval: DEFAULT_OUTBOX_POLL_INTERVAL,
+		BatchSize:    DEFAULT_OUTBOX_BATCH_SIZE,
+	}
+}
+
+// the sinks themselves are checked when created
+func (c *OutboxConfig) validate(v *validator) {
+	v.check(c.PollInterval > 0, "outbox.poll_interval", "must be positive")
+	v.check(c.BatchSize >= 1, "outbox.batch_size", "must be at least 1")
+}
+
+func GetOutboxConfig() *OutboxConfig {
+	return &Current().Outbox
+}
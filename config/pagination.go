@@ -0,0 +1,24 @@
+package config
+
+const (
+	DEFAULT_PAGE_SIZE = 2
+)
+
+type PaginationConfig struct {
+	// not reloaded, for clients going through the pages not to skip any
+	PageSize int `yaml:"page_size" env:"PAGE_SIZE"`
+}
+
+func defaultPaginationConfig() PaginationConfig {
+	return PaginationConfig{
+		PageSize: DEFAULT_PAGE_SIZE,
+	}
+}
+
+func (c *PaginationConfig) validate(v *validator) {
+	v.check(c.PageSize >= 1, "pagination.page_size", "must be at least 1")
+}
+
+func GetPaginationConfig() *PaginationConfig {
+	return &Current().Pagination
+}
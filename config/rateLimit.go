@@ -0,0 +1,30 @@
+package config
+
+const (
+	DEFAULT_RATE_LIMIT_RPS   = 5
+	DEFAULT_RATE_LIMIT_BURST = 10
+)
+
+// RateLimitConfig applies per client IP, changes resetting the limits
+// reached
+type RateLimitConfig struct {
+	// tokens refilled per second
+	RPS   float64 `yaml:"rps" env:"RATE_LIMIT_RPS" reload:"true"`
+	Burst int     `yaml:"burst" env:"RATE_LIMIT_BURST" reload:"true"`
+}
+
+func defaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RPS:   DEFAULT_RATE_LIMIT_RPS,
+		Burst: DEFAULT_RATE_LIMIT_BURST,
+	}
+}
+
+func (c *RateLimitConfig) validate(v *validator) {
+	v.check(c.RPS > 0, "rate_limit.rps", "must be positive")
+	v.check(c.Burst >= 1, "rate_limit.burst", "must be at least 1")
+}
+
+func GetRateLimitConfig() *RateLimitConfig {
+	return &Current().RateLimit
+}
@@ -1,30 +1,27 @@
 package config
 
-import (
-	"fmt"
-
-	utils "github.com/Prashansa-K/serviceCatalog/internal"
-)
-
 const (
 	DEFAULT_HOST = "localhost"
 	DEFAULT_PORT = "8080"
 )
 
 type ServerConfig struct {
-	Host    string
-	Port    string
-	Address string
+	Host    string `yaml:"host" env:"SERVICE_HOST"`
+	Port    string `yaml:"port" env:"SERVICE_PORT"`
+	Address string `yaml:"-"`
 }
 
-func GetServerConfig() *ServerConfig {
-	host := utils.GetEnvWithDefault("SERVICE_HOST", DEFAULT_HOST)
-	port := utils.GetEnvWithDefault("SERVICE_PORT", DEFAULT_PORT)
-
-	return &ServerConfig{
-		Host:    host,
-		Port:    port,
-		Address: fmt.Sprintf("%s:%s", host, port),
+func defaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Host: DEFAULT_HOST,
+		Port: DEFAULT_PORT,
 	}
+}
+
+func (c *ServerConfig) validate(v *validator, path string) {
+	v.checkPort(path+".port", c.Port)
+}
 
+func GetServerConfig() *ServerConfig {
+	return &Current().Server
 }
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	DEFAULT_DRAIN_DELAY      = 5 * time.Second
+	DEFAULT_SHUTDOWN_TIMEOUT = 30 * time.Second
+)
+
+type ShutdownConfig struct {
+	// how long readiness fails before the servers stop accepting requests,
+	// for load balancers to stop sending them
+	DrainDelay time.Duration `yaml:"drain_delay" env:"DRAIN_DELAY"`
+	// how long in-flight requests have to complete once the servers stop
+	Timeout time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+func defaultShutdownConfig() ShutdownConfig {
+	return ShutdownConfig{
+		DrainDelay: DEFAULT_DRAIN_DELAY,
+		Timeout:    DEFAULT_SHUTDOWN_TIMEOUT,
+	}
+}
+
+func (c *ShutdownConfig) validate(v *validator) {
+	v.check(c.DrainDelay >= 0, "shutdown.drain_delay", "must not be negative")
+	v.check(c.Timeout > 0, "shutdown.timeout", "must be positive")
+}
+
+func GetShutdownConfig() *ShutdownConfig {
+	return &Current().Shutdown
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	DEFAULT_TLS_RELOAD_INTERVAL = 10 * time.Second
+)
+
+type TLSConfig struct {
+	// TLS is served when set, PEM files
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE"`
+	// PEM bundle client certificates are verified against, none asked for
+	// when empty
+	ClientCAFile string `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	// refuse the clients without a certificate, instead of letting them
+	// authenticate with a key
+	RequireClientCert bool `yaml:"require_client_cert" env:"TLS_REQUIRE_CLIENT_CERT"`
+	// how often the files are checked for changes
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL"`
+}
+
+func defaultTLSConfig() TLSConfig {
+	return TLSConfig{
+		ReloadInterval: DEFAULT_TLS_RELOAD_INTERVAL,
+	}
+}
+
+// Enabled tells whether TLS is configured
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+func (c *TLSConfig) validate(v *validator, path string) {
+	v.check((c.CertFile == "") == (c.KeyFile == ""), path+".key_file", "a certificate and its key are set together")
+	v.check(!c.RequireClientCert || c.ClientCAFile != "", path+".client_ca_file", "is required to require client certificates")
+	v.check(c.ClientCAFile == "" || c.Enabled(), path+".cert_file", "is required to verify client certificates")
+	v.check(c.ReloadInterval > 0, path+".reload_interval", "must be positive")
+}
+
+func GetTLSConfig() *TLSConfig {
+	return &Current().TLS
+}
+
+func GetMetricsTLSConfig() *TLSConfig {
+	return &Current().MetricsTLS
+}
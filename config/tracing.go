@@ -0,0 +1,47 @@
+package config
+
+const (
+	// spans are recorded, for their trace IDs to show up in responses and
+	// logs, but not exported
+	TRACING_EXPORTER_NONE = "none"
+	// to a collector, set by the standard OTEL_EXPORTER_OTLP_* variables
+	TRACING_EXPORTER_OTLP = "otlp"
+	// as JSON, e.g. for local use
+	TRACING_EXPORTER_STDOUT = "stdout"
+	TRACING_EXPORTER_FILE   = "file"
+
+	DEFAULT_TRACING_EXPORTER     = TRACING_EXPORTER_NONE
+	DEFAULT_TRACING_FILE         = ".log/traces"
+	DEFAULT_TRACING_SAMPLE_RATIO = 1
+	DEFAULT_TRACING_SERVICE_NAME = "service-catalog"
+)
+
+type TracingConfig struct {
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	// written to by the file exporter
+	Path string `yaml:"path" env:"TRACING_FILE"`
+	// of the traces started by the service, the others following the
+	// decision of their caller
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME"`
+}
+
+func defaultTracingConfig() TracingConfig {
+	return TracingConfig{
+		Exporter:    DEFAULT_TRACING_EXPORTER,
+		Path:        DEFAULT_TRACING_FILE,
+		SampleRatio: DEFAULT_TRACING_SAMPLE_RATIO,
+		ServiceName: DEFAULT_TRACING_SERVICE_NAME,
+	}
+}
+
+func (c *TracingConfig) validate(v *validator) {
+	v.checkOneOf("tracing.exporter", c.Exporter, TRACING_EXPORTER_NONE, TRACING_EXPORTER_OTLP, TRACING_EXPORTER_STDOUT, TRACING_EXPORTER_FILE)
+	v.check(c.Exporter != TRACING_EXPORTER_FILE || c.Path != "", "tracing.path", "is required by the file exporter")
+	v.check(c.SampleRatio >= 0 && c.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+	v.check(c.ServiceName != "", "tracing.service_name", "is required")
+}
+
+func GetTracingConfig() *TracingConfig {
+	return &Current().Tracing
+}
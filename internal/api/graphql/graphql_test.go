@@ -0,0 +1,229 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func initMockDB(t *testing.T) sqlmock.Sqlmock {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db.DB, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.DB = nil
+	})
+
+	return mock
+}
+
+func doQuery(t *testing.T, query string) (int, map[string]interface{}) {
+	body, err := json.Marshal(Request{Query: query})
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+
+	app := echo.New()
+	app.POST("/graphql", Handler)
+	app.ServeHTTP(recorder, request)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+
+	return recorder.Code, result
+}
+
+func TestHandler_ServicesWithNestedVersions(t *testing.T) {
+	mock := initMockDB(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE LOWER(name) LIKE $1 AND "services"."deleted_at" IS NULL`)).
+		WithArgs(`%pay%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE LOWER(name) LIKE $1 AND "services"."deleted_at" IS NULL ORDER BY name DESC LIMIT $2`)).
+		WithArgs(`%pay%`, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow(123, "payments", "Payments", 3))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions" JOIN services ON versions.service_id = services.id WHERE services.name = $1 AND "versions"."deleted_at" IS NULL`)).
+		WithArgs("payments").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(3))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow(123, "payments", "Payments", 3))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE "versions"."service_id" = $1 AND "versions"."deleted_at" IS NULL LIMIT $2 OFFSET $3`)).
+		WithArgs(123, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description"}).
+			AddRow(3, "v3", 123, "Version 3"))
+
+	status, result := doQuery(t, `{
+		services(name: "Pay", sort: DESC) {
+			totalRecords
+			nodes {
+				name
+				versions(page: 2) { currentPage totalPages nodes { name description } }
+			}
+		}
+	}`)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, result["errors"])
+
+	services := result["data"].(map[string]interface{})["services"].(map[string]interface{})
+	assert.Equal(t, float64(1), services["totalRecords"])
+
+	service := services["nodes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "payments", service["name"])
+
+	versions := service["versions"].(map[string]interface{})
+	assert.Equal(t, float64(2), versions["currentPage"])
+	assert.Equal(t, float64(2), versions["totalPages"])
+	assert.Equal(t, "v3", versions["nodes"].([]interface{})[0].(map[string]interface{})["name"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHandler_ServiceByPreviousName(t *testing.T) {
+	mock := initMockDB(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("billing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WithArgs("billing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}).
+			AddRow("payments", time.Now()))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+		WithArgs("payments").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow(123, "payments", "Payments", 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE "versions"."service_id" = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description"}))
+
+	status, result := doQuery(t, `{ service(name: "billing") { name } }`)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, result["errors"])
+	assert.Equal(t, "payments", result["data"].(map[string]interface{})["service"].(map[string]interface{})["name"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHandler_ServiceNotFoundIsNull(t *testing.T) {
+	mock := initMockDB(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("missing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WithArgs("missing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}))
+
+	status, result := doQuery(t, `{ service(name: "missing") { name } }`)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, result["errors"])
+	assert.Nil(t, result["data"].(map[string]interface{})["service"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHandler_RejectsBeforeExecuting(t *testing.T) {
+	// no expectations, nothing may reach the database
+	mock := initMockDB(t)
+
+	tooDeep := `{ services { nodes { versions { nodes { name } } } } }`
+	tooDeep = strings.Replace(tooDeep, "{ name }", "{ name versions { nodes { name } } }", 1)
+	status, result := doQuery(t, tooDeep)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, result["errors"].([]interface{})[0].(map[string]interface{})["message"], "query depth")
+
+	// aliases multiply the work done in a single request
+	var aliased strings.Builder
+	aliased.WriteString("{")
+	for i := 0; i < 10; i++ {
+		aliased.WriteString(" s" + string(rune('a'+i)) + `: services { nodes { name versions { nodes { name } } } }`)
+	}
+	aliased.WriteString(" }")
+	status, result = doQuery(t, aliased.String())
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, result["errors"].([]interface{})[0].(map[string]interface{})["message"], "query complexity")
+
+	status, _ = doQuery(t, "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHandler_IntrospectionIsAllowed(t *testing.T) {
+	initMockDB(t)
+
+	status, result := doQuery(t, `{ __schema { queryType { name fields { name type { kind ofType { kind ofType { kind ofType { name } } } } } } } }`)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, result["errors"])
+}
+
+func TestCheckLimits_FragmentCycleDoesNotLoop(t *testing.T) {
+	status, result := doQuery(t, `
+		query { services { ...A } }
+		fragment A on ServicePage { nodes { ...B } }
+		fragment B on Service { versions { ...A } }
+	`)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotNil(t, result["errors"])
+}
+
+func TestCheckLimits_FollowPageSize(t *testing.T) {
+	previous := constants.PAGE_SIZE
+	t.Cleanup(func() { constants.PAGE_SIZE = previous })
+
+	query := `{ services { nodes { name versions { nodes { name } } } } }`
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	require.NoError(t, err)
+
+	// the versions of 50 services cost 2652
+	constants.PAGE_SIZE = 50
+	assert.NoError(t, checkLimits(document))
+
+	// pages smaller than the default keep the default limit
+	constants.PAGE_SIZE = 1
+	assert.Equal(t, MAX_QUERY_COMPLEXITY, maxQueryComplexity())
+}
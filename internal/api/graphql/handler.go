@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/labstack/echo/v4"
+)
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler serves both GET and POST requests. Batched queries are not supported
+// so that each HTTP request, as seen by the rate limiter, runs a single operation.
+func Handler(ctx echo.Context) error {
+	var request Request
+
+	if ctx.Request().Method == http.MethodGet {
+		request.Query = ctx.QueryParam("query")
+		request.OperationName = ctx.QueryParam("operationName")
+		if variables := ctx.QueryParam("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return ctx.JSON(http.StatusBadRequest, errorResult(constants.INVALID_REQUEST_BODY))
+			}
+		}
+	} else if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, errorResult(constants.INVALID_REQUEST_BODY))
+	}
+
+	if request.Query == "" {
+		return ctx.JSON(http.StatusBadRequest, errorResult(constants.MISSING_GRAPHQL_QUERY))
+	}
+
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, &gql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+
+	if err := checkLimits(document); err != nil {
+		return ctx.JSON(http.StatusBadRequest, errorResult(err.Error()))
+	}
+
+	result := gql.Do(gql.Params{
+		Schema:         Schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        ctx.Request().Context(),
+	})
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func errorResult(message string) *gql.Result {
+	return &gql.Result{
+		Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)},
+	}
+}
@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// A query can be arbitrarily expensive within a single request, which would
+	// let it bypass the intent of the per-request rate limiter. Both of these
+	// are checked before anything gets executed.
+	MAX_QUERY_DEPTH = 6
+	// with pages of the default size, see maxQueryComplexity
+	MAX_QUERY_COMPLEXITY = 100
+)
+
+type queryCost struct {
+	depth      int
+	complexity int
+}
+
+// checkLimits measures each operation of the document. Every field costs 1 and
+// introspection fields are free so that tooling keeps working.
+func checkLimits(document *ast.Document) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		cost := measure(operation.SelectionSet, fragments, map[string]bool{})
+
+		if cost.depth > MAX_QUERY_DEPTH {
+			return fmt.Errorf("query depth %d exceeds the maximum of %d", cost.depth, MAX_QUERY_DEPTH)
+		}
+
+		if maximum := maxQueryComplexity(); cost.complexity > maximum {
+			return fmt.Errorf("query complexity %d exceeds the maximum of %d", cost.complexity, maximum)
+		}
+	}
+
+	return nil
+}
+
+// maxQueryComplexity scales MAX_QUERY_COMPLEXITY with the square of the page
+// size, the cost of the pages nested once, e.g. the versions of the services,
+// so that a larger page size does not reject the queries served with the
+// default one. It is never lower than MAX_QUERY_COMPLEXITY.
+func maxQueryComplexity() int {
+	pageSize := constants.PAGE_SIZE
+	if pageSize < config.DEFAULT_PAGE_SIZE {
+		pageSize = config.DEFAULT_PAGE_SIZE
+	}
+
+	return MAX_QUERY_COMPLEXITY * pageSize * pageSize / (config.DEFAULT_PAGE_SIZE * config.DEFAULT_PAGE_SIZE)
+}
+
+func measure(selectionSet *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, visiting map[string]bool) queryCost {
+	var total queryCost
+	if selectionSet == nil {
+		return total
+	}
+
+	for _, selection := range selectionSet.Selections {
+		var cost queryCost
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+
+			cost = measure(selection.SelectionSet, fragments, visiting)
+
+			// the selection of a page's nodes is resolved once per item
+			if selection.Name.Value == PAGE_NODES_FIELD {
+				cost.complexity *= constants.PAGE_SIZE
+			}
+			cost.depth++
+			cost.complexity++
+
+		case *ast.InlineFragment:
+			cost = measure(selection.SelectionSet, fragments, visiting)
+
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := fragments[name]
+			if !ok || visiting[name] {
+				// unknown fragments and cycles are reported by validation
+				continue
+			}
+
+			visiting[name] = true
+			cost = measure(fragment.SelectionSet, fragments, visiting)
+			delete(visiting, name)
+		}
+
+		total.complexity += cost.complexity
+		if cost.depth > total.depth {
+			total.depth = cost.depth
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,266 @@
+package graphql
+
+import (
+	"math"
+	"strings"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// serviceNode is the source of the Service type. Versions are preloaded when the
+// service is fetched by name, so that querying their first page doesn't need
+// another round trip.
+type serviceNode struct {
+	service       models.Service
+	versionsPage  int
+	totalVersions int64
+	preloaded     bool
+}
+
+const (
+	PAGE_NODES_FIELD = "nodes"
+)
+
+type page struct {
+	items        interface{}
+	totalRecords int64
+	currentPage  int
+}
+
+var sortOrderEnum = gql.NewEnum(gql.EnumConfig{
+	Name: "SortOrder",
+	Values: gql.EnumValueConfigMap{
+		constants.ASC:  &gql.EnumValueConfig{Value: constants.ASC},
+		constants.DESC: &gql.EnumValueConfig{Value: constants.DESC},
+	},
+})
+
+var versionType = gql.NewObject(gql.ObjectConfig{
+	Name: "Version",
+	Fields: gql.Fields{
+		"name": &gql.Field{
+			Type: gql.NewNonNull(gql.String),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.Version).Name, nil
+			},
+		},
+		"description": &gql.Field{
+			Type: gql.String,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.Version).Description, nil
+			},
+		},
+		"createdAt": &gql.Field{
+			Type: gql.DateTime,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.Version).CreatedAt, nil
+			},
+		},
+	},
+})
+
+var versionPageType = newPageType("VersionPage", versionType)
+
+var serviceType = gql.NewObject(gql.ObjectConfig{
+	Name: "Service",
+	Fields: gql.Fields{
+		"id": &gql.Field{
+			Type: gql.NewNonNull(gql.ID),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*serviceNode).service.ID, nil
+			},
+		},
+		"name": &gql.Field{
+			Type: gql.NewNonNull(gql.String),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*serviceNode).service.Name, nil
+			},
+		},
+		"description": &gql.Field{
+			Type: gql.String,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*serviceNode).service.Description, nil
+			},
+		},
+		"versionCount": &gql.Field{
+			Type: gql.Int,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*serviceNode).service.VersionCount, nil
+			},
+		},
+		"createdAt": &gql.Field{
+			Type: gql.DateTime,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*serviceNode).service.CreatedAt, nil
+			},
+		},
+		"updatedAt": &gql.Field{
+			Type: gql.DateTime,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*serviceNode).service.UpdatedAt, nil
+			},
+		},
+		"versions": &gql.Field{
+			Type: versionPageType,
+			Args: gql.FieldConfigArgument{
+				"page": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 1},
+			},
+			Resolve: resolveVersions,
+		},
+	},
+})
+
+var servicePageType = newPageType("ServicePage", serviceType)
+
+var queryType = gql.NewObject(gql.ObjectConfig{
+	Name: "Query",
+	Fields: gql.Fields{
+		"services": &gql.Field{
+			Type: gql.NewNonNull(servicePageType),
+			Args: gql.FieldConfigArgument{
+				"page":        &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 1},
+				"sort":        &gql.ArgumentConfig{Type: sortOrderEnum, DefaultValue: constants.ASC},
+				"name":        &gql.ArgumentConfig{Type: gql.String, DefaultValue: ""},
+				"description": &gql.ArgumentConfig{Type: gql.String, DefaultValue: ""},
+			},
+			Resolve: resolveServices,
+		},
+		"service": &gql.Field{
+			Type: serviceType,
+			Args: gql.FieldConfigArgument{
+				"name": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+			},
+			Resolve: resolveService,
+		},
+	},
+})
+
+var Schema = newSchema()
+
+func newSchema() gql.Schema {
+	schema, err := gql.NewSchema(gql.SchemaConfig{
+		Query: queryType,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func newPageType(name string, itemType *gql.Object) *gql.Object {
+	return gql.NewObject(gql.ObjectConfig{
+		Name: name,
+		Fields: gql.Fields{
+			PAGE_NODES_FIELD: &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(itemType))),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*page).items, nil
+				},
+			},
+			"totalPages": &gql.Field{
+				Type: gql.NewNonNull(gql.Int),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return int(math.Ceil(float64(p.Source.(*page).totalRecords) / float64(constants.PAGE_SIZE))), nil
+				},
+			},
+			"currentPage": &gql.Field{
+				Type: gql.NewNonNull(gql.Int),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*page).currentPage, nil
+				},
+			},
+			"totalRecords": &gql.Field{
+				Type: gql.NewNonNull(gql.Int),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*page).totalRecords, nil
+				},
+			},
+		},
+	})
+}
+
+func resolveServices(p gql.ResolveParams) (interface{}, error) {
+	db, err := db.GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	pageNumber := pageArgument(p)
+	totalServices, services, _, err := controllers.CachedPaginatedServicesByFilters(
+		db.WithContext(p.Context),
+		pageNumber,
+		p.Args["sort"].(string),
+		strings.ToLower(p.Args["name"].(string)),
+		strings.ToLower(p.Args["description"].(string)),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*serviceNode, 0, len(services))
+	for _, service := range services {
+		nodes = append(nodes, &serviceNode{service: service})
+	}
+
+	return &page{items: nodes, totalRecords: totalServices, currentPage: pageNumber}, nil
+}
+
+func resolveService(p gql.ResolveParams) (interface{}, error) {
+	db, err := db.GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	versionsPage := 1
+	var totalVersions int64
+	var service *models.Service
+	// a service looked up by a previous name resolves to the renamed one
+	err = controllers.FollowRename(p.Args["name"].(string), func(serviceName string) error {
+		totalVersions, service, _, err = controllers.CachedServiceByNameWithPaginatedVersions(db.WithContext(p.Context), versionsPage, serviceName)
+		return err
+	})
+	if err != nil {
+		// a missing service resolves to null
+		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &serviceNode{service: *service, versionsPage: versionsPage, totalVersions: totalVersions, preloaded: true}, nil
+}
+
+func resolveVersions(p gql.ResolveParams) (interface{}, error) {
+	node := p.Source.(*serviceNode)
+	pageNumber := pageArgument(p)
+
+	if node.preloaded && node.versionsPage == pageNumber {
+		return &page{items: node.service.Versions, totalRecords: node.totalVersions, currentPage: pageNumber}, nil
+	}
+
+	db, err := db.GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	totalVersions, service, _, err := controllers.CachedServiceByNameWithPaginatedVersions(db.WithContext(p.Context), pageNumber, node.service.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page{items: service.Versions, totalRecords: totalVersions, currentPage: pageNumber}, nil
+}
+
+func pageArgument(p gql.ResolveParams) int {
+	pageNumber, ok := p.Args["page"].(int)
+	if !ok || pageNumber < 1 {
+		return 1
+	}
+	return pageNumber
+}
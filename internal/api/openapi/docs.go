@@ -0,0 +1,47 @@
+package openapi
+
+import (
+	"net/http"
+
+	servicecatalog "github.com/Prashansa-K/serviceCatalog"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	SPEC_PATH = "/openapi.yaml"
+	DOCS_PATH = "/docs"
+
+	YAML_MIME = "application/yaml"
+
+	// Swagger UI is loaded from a CDN rather than vendored
+	SWAGGER_UI_VERSION = "5.17.14"
+	SWAGGER_UI_CDN     = "https://unpkg.com/swagger-ui-dist@" + SWAGGER_UI_VERSION
+)
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Service Catalog API</title>
+  <link rel="stylesheet" href="` + SWAGGER_UI_CDN + `/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="` + SWAGGER_UI_CDN + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "` + SPEC_PATH + `", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+func ServeSpec(ctx echo.Context) error {
+	return ctx.Blob(http.StatusOK, YAML_MIME, servicecatalog.OpenAPISpec)
+}
+
+func ServeDocs(ctx echo.Context) error {
+	return ctx.HTML(http.StatusOK, docsPage)
+}
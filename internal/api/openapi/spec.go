@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	"context"
+	"sync"
+
+	servicecatalog "github.com/Prashansa-K/serviceCatalog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	loadOnce sync.Once
+	spec     *openapi3.T
+	specErr  error
+)
+
+// Spec parses and validates the embedded openapi_spec.yaml, once
+func Spec() (*openapi3.T, error) {
+	loadOnce.Do(func() {
+		loader := openapi3.NewLoader()
+
+		spec, specErr = loader.LoadFromData(servicecatalog.OpenAPISpec)
+		if specErr != nil {
+			return
+		}
+
+		specErr = spec.Validate(context.Background())
+	})
+
+	return spec, specErr
+}
@@ -0,0 +1,216 @@
+package openapi
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	REQUEST  = "request"
+	RESPONSE = "response"
+
+	EVENT_STREAM_MIME = "text/event-stream"
+	MARKDOWN_MIME     = "text/markdown"
+)
+
+func init() {
+	// rendered documents, such as changelogs, are validated as plain text
+	for _, mime := range []string{MARKDOWN_MIME, echo.MIMETextHTML} {
+		openapi3filter.RegisterBodyDecoder(mime, openapi3filter.RegisteredBodyDecoder(echo.MIMETextPlain))
+	}
+}
+
+type ValidatorConfig struct {
+	// config.OPENAPI_VALIDATION_LOG or config.OPENAPI_VALIDATION_ENFORCE
+	Mode string
+
+	// called with every mismatch after it is logged, tests use it to fail
+	// when a handler drifts from the spec. kind is REQUEST or RESPONSE.
+	OnMismatch func(ctx echo.Context, kind string, err error)
+}
+
+// Validator checks requests and responses of the routes described in the spec
+// against it. Routes missing from the spec are left alone, and so are the
+// bodies of streamed responses.
+func Validator(validatorConfig ValidatorConfig) (echo.MiddlewareFunc, error) {
+	spec, err := Spec()
+	if err != nil {
+		return nil, err
+	}
+
+	enforce := validatorConfig.Mode == config.OPENAPI_VALIDATION_ENFORCE
+	options := &openapi3filter.Options{
+		// authentication is done by the key auth middleware
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+		SkipSettingDefaults:   true,
+	}
+
+	report := func(ctx echo.Context, kind string, err error) {
+		slog.WarnContext(ctx.Request().Context(), "openapi: "+kind+" does not match the spec", "method", ctx.Request().Method, "path", ctx.Path(), "error", err)
+		if validatorConfig.OnMismatch != nil {
+			validatorConfig.OnMismatch(ctx, kind, err)
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			route, pathParams := findRoute(spec, ctx)
+			if route == nil {
+				return next(ctx)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    ctx.Request(),
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+
+			if err := openapi3filter.ValidateRequest(ctx.Request().Context(), input); err != nil {
+				report(ctx, REQUEST, err)
+				if enforce {
+					return ctx.JSON(http.StatusBadRequest, echo.Map{
+						"error": err.Error(),
+					})
+				}
+			}
+
+			if isStreamed(route.Operation) {
+				return next(ctx)
+			}
+
+			response := ctx.Response()
+			recorder := &responseRecorder{ResponseWriter: response.Writer, hold: enforce}
+			response.Writer = recorder
+			defer func() {
+				response.Writer = recorder.ResponseWriter
+			}()
+
+			if err := next(ctx); err != nil {
+				recorder.release()
+				return err
+			}
+
+			err := openapi3filter.ValidateResponse(ctx.Request().Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 response.Status,
+				Header:                 response.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+				Options:                options,
+			})
+			if err != nil {
+				report(ctx, RESPONSE, err)
+			}
+
+			if err == nil || !enforce {
+				return recorder.release()
+			}
+
+			// the drifted response was held back and is replaced
+			response.Status = http.StatusInternalServerError
+			response.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			response.Header().Del(echo.HeaderContentLength)
+			recorder.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+			_, err = recorder.ResponseWriter.Write([]byte(`{"error":"response does not match the API specification"}` + "\n"))
+			return err
+		}
+	}, nil
+}
+
+// findRoute maps the echo route matched by the request to the spec operation
+func findRoute(spec *openapi3.T, ctx echo.Context) (*routers.Route, map[string]string) {
+	segments := strings.Split(ctx.Path(), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	path := strings.Join(segments, "/")
+
+	pathItem := spec.Paths.Find(path)
+	if pathItem == nil {
+		return nil, nil
+	}
+
+	operation := pathItem.GetOperation(ctx.Request().Method)
+	if operation == nil {
+		return nil, nil
+	}
+
+	pathParams := map[string]string{}
+	for i, name := range ctx.ParamNames() {
+		pathParams[name] = ctx.ParamValues()[i]
+	}
+
+	return &routers.Route{
+		Spec:      spec,
+		Path:      path,
+		PathItem:  pathItem,
+		Method:    ctx.Request().Method,
+		Operation: operation,
+	}, pathParams
+}
+
+func isStreamed(operation *openapi3.Operation) bool {
+	for _, response := range operation.Responses.Map() {
+		if response.Value != nil && response.Value.Content.Get(EVENT_STREAM_MIME) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// responseRecorder keeps a copy of the body to validate it. When holding, the
+// response is only written on release, so that it can still be replaced.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	hold   bool
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	if !r.hold {
+		r.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	if r.hold {
+		return len(data), nil
+	}
+
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) release() error {
+	if !r.hold {
+		return nil
+	}
+
+	if r.status != 0 {
+		r.ResponseWriter.WriteHeader(r.status)
+	}
+
+	if r.body.Len() == 0 {
+		return nil
+	}
+	_, err := r.ResponseWriter.Write(r.body.Bytes())
+
+	return err
+}
@@ -0,0 +1,68 @@
+// Package renames answers the requests naming a service by one of its
+// previous names, in every version of the REST API
+package renames
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Redirect permanently redirects a read of a service by one of its previous
+// names to the same path with its current name
+func Redirect(ctx echo.Context, err error) error {
+	var renamed *controllers.ServiceRenamedError
+	if !errors.As(err, &renamed) {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	setDeprecation(ctx, renamed)
+
+	// the route is rebuilt with the parameters of the request, escaped again
+	segments := strings.Split(ctx.Path(), "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		value := ctx.Param(segment[1:])
+		if segment == ":serviceName" {
+			value = renamed.Name
+		}
+		segments[i] = url.PathEscape(value)
+	}
+
+	location := strings.Join(segments, "/")
+	if query := ctx.Request().URL.RawQuery; query != "" {
+		location += "?" + query
+	}
+
+	return ctx.Redirect(http.StatusMovedPermanently, location)
+}
+
+// Follow calls fn with the service named in the path and, when the service
+// was renamed since, once more with its current name. The response then tells
+// that the name used is deprecated.
+func Follow(ctx echo.Context, fn func(serviceName string) error) error {
+	return controllers.FollowRename(ctx.Param("serviceName"), func(serviceName string) error {
+		err := fn(serviceName)
+
+		var renamed *controllers.ServiceRenamedError
+		if errors.As(err, &renamed) {
+			setDeprecation(ctx, renamed)
+		}
+
+		return err
+	})
+}
+
+func setDeprecation(ctx echo.Context, renamed *controllers.ServiceRenamedError) {
+	ctx.Response().Header().Set(constants.DEPRECATION_HEADER, fmt.Sprintf("@%d", renamed.RenamedAt.Unix()))
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Prashansa-K/serviceCatalog/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	AUTH_METADATA_KEY = "authorization"
+	INVALID_KEY       = "invalid key"
+)
+
+// Same contract as the REST APIs: an "authorization: Bearer <key>" metadata entry
+func authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, value := range md.Get(AUTH_METADATA_KEY) {
+		scheme, key, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, auth.BEARER_SCHEME) && auth.IsValidKey(key) {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, INVALID_KEY)
+}
+
+func unaryAuthInterceptor(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, request)
+}
+
+func streamAuthInterceptor(server interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := authenticate(stream.Context()); err != nil {
+		return err
+	}
+
+	return handler(server, stream)
+}
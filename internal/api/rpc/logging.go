@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Prashansa-K/serviceCatalog/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// the X-Request-ID header of the REST APIs, metadata keys being lower case
+const REQUEST_ID_METADATA_KEY = "x-request-id"
+
+// Each call is identified by its x-request-id metadata, one being generated
+// when the caller sent none. It is echoed in the headers, and carried by the
+// records logged with the context of the call.
+func unaryRequestIDInterceptor(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	requestID := callRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(REQUEST_ID_METADATA_KEY, requestID))
+
+	return handler(logging.WithRequestID(ctx, requestID), request)
+}
+
+func streamRequestIDInterceptor(server interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	requestID := callRequestID(stream.Context())
+	_ = stream.SetHeader(metadata.Pairs(REQUEST_ID_METADATA_KEY, requestID))
+
+	return handler(server, &requestIDStream{
+		ServerStream: stream,
+		ctx:          logging.WithRequestID(stream.Context(), requestID),
+	})
+}
+
+func callRequestID(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, REQUEST_ID_METADATA_KEY); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+
+	return logging.NewRequestID()
+}
+
+// requestIDStream is the stream of a call, with the context carrying its ID
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestIDStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,310 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/rpc/pb"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/events"
+	// not to be mistaken with the metadata of the calls
+	servicemetadata "github.com/Prashansa-K/serviceCatalog/internal/metadata"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Server struct {
+	pb.UnimplementedServiceCatalogServer
+}
+
+func NewServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryTraceIDInterceptor, unaryRequestIDInterceptor, unaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(streamTraceIDInterceptor, streamRequestIDInterceptor, streamAuthInterceptor),
+	)
+	pb.RegisterServiceCatalogServer(server, &Server{})
+
+	return server
+}
+
+func (s *Server) ListServices(ctx context.Context, request *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+	db, err := db.GetDBContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	page := int(request.GetPage())
+	if page < 1 {
+		page = 1
+	}
+
+	sort := constants.ASC
+	if request.GetSort() == pb.SortOrder_SORT_ORDER_DESC {
+		sort = constants.DESC
+	}
+
+	totalServices, services, _, err := controllers.CachedPaginatedServicesByFilters(db.WithContext(ctx), page, sort, request.GetName(), request.GetDescription(), nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListServicesResponse{
+		TotalPages:   int32(math.Ceil(float64(totalServices) / float64(constants.PAGE_SIZE))),
+		CurrentPage:  int32(page),
+		TotalRecords: totalServices,
+	}
+	for _, service := range services {
+		response.Services = append(response.Services, toServiceMessage(service))
+	}
+
+	return response, nil
+}
+
+func (s *Server) GetService(ctx context.Context, request *pb.GetServiceRequest) (*pb.GetServiceResponse, error) {
+	db, err := db.GetDBContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	page := int(request.GetPage())
+	if page < 1 {
+		page = 1
+	}
+
+	var totalVersions int64
+	var service *models.Service
+	err = controllers.FollowRename(request.GetName(), func(serviceName string) error {
+		totalVersions, service, _, err = controllers.CachedServiceByNameWithPaginatedVersions(db.WithContext(ctx), page, serviceName)
+		return err
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.GetServiceResponse{
+		Service:             toServiceMessage(*service),
+		TotalPages:          int32((totalVersions + int64(constants.PAGE_SIZE) - 1) / int64(constants.PAGE_SIZE)),
+		CurrentPage:         int32(page),
+		TotalVersionRecords: totalVersions,
+	}
+	for _, version := range service.Versions {
+		response.Versions = append(response.Versions, &pb.Version{
+			Name:        version.Name,
+			Description: version.Description,
+			CreatedAt:   timestamppb.New(version.CreatedAt),
+		})
+	}
+
+	return response, nil
+}
+
+func (s *Server) CreateService(ctx context.Context, request *pb.CreateServiceRequest) (*pb.CreateServiceResponse, error) {
+	db, err := db.GetDBContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if request.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, constants.INVALID_REQUEST_BODY)
+	}
+
+	if err := controllers.CreateService(db.WithContext(ctx), api.ServiceRequest{
+		Name:        request.GetName(),
+		Description: request.GetDescription(),
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.CreateServiceResponse{Message: constants.SERVICE_CREATED}, nil
+}
+
+func (s *Server) CreateVersion(ctx context.Context, request *pb.CreateVersionRequest) (*pb.CreateVersionResponse, error) {
+	db, err := db.GetDBContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if request.GetName() == "" || request.GetServiceName() == "" {
+		return nil, status.Error(codes.InvalidArgument, constants.INVALID_REQUEST_BODY)
+	}
+
+	if err := controllers.CreateVersion(db.WithContext(ctx), api.ServiceVersionRequest{
+		Name:        request.GetName(),
+		ServiceName: request.GetServiceName(),
+		Description: request.GetDescription(),
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.CreateVersionResponse{Message: constants.SERVICE_VERSION_CREATED}, nil
+}
+
+func (s *Server) UpdateService(ctx context.Context, request *pb.UpdateServiceRequest) (*pb.UpdateServiceResponse, error) {
+	db, err := db.GetDBContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := controllers.UpdateService(db.WithContext(ctx), api.ServiceRequest{
+		ID:          uint(request.GetId()),
+		Name:        request.GetName(),
+		Description: request.GetDescription(),
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.UpdateServiceResponse{Message: constants.SERVICE_UPDATED}, nil
+}
+
+func (s *Server) DeleteService(ctx context.Context, request *pb.DeleteServiceRequest) (*pb.DeleteServiceResponse, error) {
+	db, err := db.GetDBContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := controllers.FollowRename(request.GetName(), func(serviceName string) error {
+		return controllers.DeleteService(db.WithContext(ctx), serviceName)
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DeleteServiceResponse{Message: constants.SERVICE_DELETED}, nil
+}
+
+func (s *Server) DeleteVersion(ctx context.Context, request *pb.DeleteVersionRequest) (*pb.DeleteVersionResponse, error) {
+	db, err := db.GetDBContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := controllers.FollowRename(request.GetServiceName(), func(serviceName string) error {
+		return controllers.DeleteVersion(db.WithContext(ctx), serviceName, request.GetVersionName(), request.GetForce())
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DeleteVersionResponse{Message: constants.SERVICE_VERSION_DELETED}, nil
+}
+
+func (s *Server) WatchServices(request *pb.WatchServicesRequest, stream pb.ServiceCatalog_WatchServicesServer) error {
+	db, err := db.GetDBContext(stream.Context())
+	if err != nil {
+		return toStatus(err)
+	}
+
+	filter := events.Filter{ServiceName: request.GetServiceName()}
+	for _, eventType := range request.GetTypes() {
+		eventType = strings.TrimSpace(eventType)
+		if !events.IsValidEventType(eventType) {
+			return status.Error(codes.InvalidArgument, constants.INVALID_EVENT_TYPE)
+		}
+		filter.Types = append(filter.Types, eventType)
+	}
+
+	// Subscribe before replaying so that nothing dispatched in between is lost
+	subscription := events.Subscribe(filter)
+	defer events.Unsubscribe(subscription)
+
+	// without after_event_id, the stream starts with the events to come
+	var position events.Position
+	if lastEventID := uint(request.GetAfterEventId()); lastEventID > 0 {
+		position, err = controllers.GetEventPosition(db, lastEventID)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		position, err = controllers.ReplayEvents(db, position, filter, func(event models.Event) error {
+			return stream.Send(toEventMessage(event))
+		})
+		if err != nil {
+			return toStatus(err)
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+
+		case event, ok := <-subscription.C:
+			if !ok {
+				if events.Closed() {
+					return status.Error(codes.Unavailable, constants.SHUTTING_DOWN)
+				}
+				// dropped for being too slow, the client resumes with after_event_id
+				return status.Error(codes.ResourceExhausted, constants.SUBSCRIBER_TOO_SLOW)
+			}
+
+			if !events.PositionOf(event).After(position) {
+				continue
+			}
+
+			if err := stream.Send(toEventMessage(event)); err != nil {
+				return err
+			}
+			position = events.PositionOf(event)
+		}
+	}
+}
+
+// toStatus maps controller errors to the codes grpc-gateway translates to the
+// HTTP statuses returned by the REST APIs
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch err.Error() {
+	case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND:
+		return status.Error(codes.NotFound, err.Error())
+	case constants.INVALID_PAGE_NUMBER, constants.INVALID_REQUEST_BODY, constants.UNKNOWN_LAST_EVENT_ID:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case constants.DUPLICATE_VERSION_RECORD_ERROR, constants.DUPLICATE_SERVICE_NAME_ERROR:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case constants.VERSION_CURRENTLY_DEPLOYED, constants.VERSION_POINTED_TO_BY_CHANNEL:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if errors.Is(err, servicemetadata.ErrInvalidAttributes) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err == context.Canceled {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toServiceMessage(service models.Service) *pb.Service {
+	return &pb.Service{
+		Id:           uint64(service.ID),
+		Name:         service.Name,
+		Description:  service.Description,
+		VersionCount: int64(service.VersionCount),
+		CreatedAt:    timestamppb.New(service.CreatedAt),
+		UpdatedAt:    timestamppb.New(service.UpdatedAt),
+	}
+}
+
+func toEventMessage(event models.Event) *pb.ServiceEvent {
+	return &pb.ServiceEvent{
+		Id:          uint64(event.ID),
+		Type:        event.Type,
+		ServiceName: event.ServiceName,
+		VersionName: event.VersionName,
+		Data:        event.Data,
+		CreatedAt:   timestamppb.New(event.CreatedAt),
+	}
+}
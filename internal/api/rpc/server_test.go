@@ -0,0 +1,236 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/rpc/pb"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/events"
+	servicemetadata "github.com/Prashansa-K/serviceCatalog/internal/metadata"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const TEST_API_KEY = "test-key"
+
+func initMockDB(t *testing.T) sqlmock.Sqlmock {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db.DB, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.DB = nil
+	})
+
+	return mock
+}
+
+// useAuthKey has the API accept key, the configuration being restored after
+// the test
+func useAuthKey(t *testing.T, key string) {
+	previous := config.Current()
+	t.Cleanup(func() { config.Set(previous) })
+
+	authenticated := *previous
+	authenticated.Auth = config.AuthConfig{Key: key}
+	config.Set(&authenticated)
+}
+
+func newTestClient(t *testing.T) pb.ServiceCatalogClient {
+	useAuthKey(t, TEST_API_KEY)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewServiceCatalogClient(conn)
+}
+
+func authenticated(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AUTH_METADATA_KEY, "Bearer "+TEST_API_KEY)
+}
+
+func TestAuth_MissingOrInvalidKey(t *testing.T) {
+	initMockDB(t)
+	client := newTestClient(t)
+
+	_, err := client.GetService(context.Background(), &pb.GetServiceRequest{Name: "payments"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), AUTH_METADATA_KEY, "Bearer wrong-key")
+	_, err = client.GetService(ctx, &pb.GetServiceRequest{Name: "payments"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.WatchServices(context.Background(), &pb.WatchServicesRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRequestID_EchoedOrGenerated(t *testing.T) {
+	initMockDB(t)
+	client := newTestClient(t)
+
+	// the ID is set before authenticating, for refused calls to be found too
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), REQUEST_ID_METADATA_KEY, "caller-request-id")
+	_, err := client.GetService(ctx, &pb.GetServiceRequest{Name: "payments"}, grpc.Header(&header))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, []string{"caller-request-id"}, header.Get(REQUEST_ID_METADATA_KEY))
+
+	_, err = client.GetService(context.Background(), &pb.GetServiceRequest{Name: "payments"}, grpc.Header(&header))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	if assert.Len(t, header.Get(REQUEST_ID_METADATA_KEY), 1) {
+		assert.Len(t, header.Get(REQUEST_ID_METADATA_KEY)[0], 32)
+	}
+}
+
+func TestGetService_Success(t *testing.T) {
+	mock := initMockDB(t)
+	client := newTestClient(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions" JOIN services ON versions.service_id = services.id WHERE services.name = $1 AND "versions"."deleted_at" IS NULL`)).
+		WithArgs("payments").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(3))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow(123, "payments", "Payments", 3))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE "versions"."service_id" = $1 AND "versions"."deleted_at" IS NULL LIMIT $2`)).
+		WithArgs(123, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description"}).
+			AddRow(1, "v1", 123, "Version 1").
+			AddRow(2, "v2", 123, "Version 2"))
+
+	response, err := client.GetService(authenticated(context.Background()), &pb.GetServiceRequest{Name: "payments"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "payments", response.GetService().GetName())
+	assert.Equal(t, int64(3), response.GetTotalVersionRecords())
+	assert.Equal(t, int32(2), response.GetTotalPages())
+	assert.Len(t, response.GetVersions(), 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusCodes(t *testing.T) {
+	mock := initMockDB(t)
+	client := newTestClient(t)
+	ctx := authenticated(context.Background())
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("missing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WithArgs("missing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}))
+
+	_, err := client.GetService(ctx, &pb.GetServiceRequest{Name: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateService(ctx, &pb.CreateServiceRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestToStatus(t *testing.T) {
+	for message, code := range map[string]codes.Code{
+		constants.SERVICE_RECORD_NOT_FOUND:       codes.NotFound,
+		constants.VERSION_RECORD_NOT_FOUND:       codes.NotFound,
+		constants.DUPLICATE_VERSION_RECORD_ERROR: codes.AlreadyExists,
+		constants.DUPLICATE_SERVICE_NAME_ERROR:   codes.AlreadyExists,
+		constants.INVALID_PAGE_NUMBER:            codes.InvalidArgument,
+		"connection reset by peer":               codes.Internal,
+	} {
+		assert.Equal(t, code, status.Code(toStatus(errors.New(message))), message)
+	}
+
+	invalid := fmt.Errorf("%w: missing properties: 'tier'", servicemetadata.ErrInvalidAttributes)
+	assert.Equal(t, codes.InvalidArgument, status.Code(toStatus(invalid)))
+}
+
+func TestWatchServices_ReplayAndLive(t *testing.T) {
+	mock := initMockDB(t)
+	client := newTestClient(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "xid","id" FROM "events" WHERE "events"."id" = $1`)).
+		WithArgs(10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"xid", "id"}).
+			AddRow(700, 10))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "events" WHERE (xid, id) > ($1, $2) AND xid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint AND service_name = $3 ORDER BY xid ASC, id ASC LIMIT $4`)).
+		WithArgs(700, 10, "payments", 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "xid", "type", "service_name", "version_name", "data", "created_at"}).
+			AddRow(11, 701, "version.created", "payments", "v2", `{"name":"v2"}`, time.Now()))
+
+	ctx, cancel := context.WithCancel(authenticated(context.Background()))
+	defer cancel()
+
+	stream, err := client.WatchServices(ctx, &pb.WatchServicesRequest{ServiceName: "payments", AfterEventId: 10})
+	require.NoError(t, err)
+
+	replayed, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(11), replayed.GetId())
+	assert.Equal(t, "v2", replayed.GetVersionName())
+
+	// the subscription is in place once the replay has been sent
+	events.Publish(models.Event{ID: 11, Xid: 701, Type: "version.created", ServiceName: "payments"})
+	events.Publish(models.Event{ID: 12, Xid: 702, Type: "service.updated", ServiceName: "orders"})
+	events.Publish(models.Event{ID: 13, Xid: 703, Type: "service.updated", ServiceName: "payments", Data: `{}`})
+
+	live, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(13), live.GetId())
+	assert.Equal(t, "service.updated", live.GetType())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWatchServices_InvalidType(t *testing.T) {
+	initMockDB(t)
+	client := newTestClient(t)
+
+	stream, err := client.WatchServices(authenticated(context.Background()), &pb.WatchServicesRequest{Types: []string{"service.renamed"}})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,31 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// the X-Trace-Id header of the REST APIs, metadata keys being lower case
+const TRACE_ID_METADATA_KEY = "x-trace-id"
+
+// The span of each call is started by the stats handler, continuing the trace
+// of its traceparent metadata if any. Its trace ID is echoed in the headers.
+func unaryTraceIDInterceptor(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(TRACE_ID_METADATA_KEY, traceID))
+	}
+
+	return handler(ctx, request)
+}
+
+func streamTraceIDInterceptor(server interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if traceID := tracing.TraceID(stream.Context()); traceID != "" {
+		_ = stream.SetHeader(metadata.Pairs(TRACE_ID_METADATA_KEY, traceID))
+	}
+
+	return handler(server, stream)
+}
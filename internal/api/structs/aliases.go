@@ -0,0 +1,14 @@
+package structs
+
+import "time"
+
+// RenamedAt is when the service stopped being named after the alias
+type ServiceAliasResponse struct {
+	Name      string    `json:"name"`
+	RenamedAt time.Time `json:"renamed_at"`
+}
+
+type ServiceAliasListResponse struct {
+	ServiceName string                 `json:"service_name"`
+	Aliases     []ServiceAliasResponse `json:"aliases"`
+}
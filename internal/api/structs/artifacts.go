@@ -0,0 +1,44 @@
+package structs
+
+import "time"
+
+// Artifact types a version can reference
+const (
+	ARTIFACT_IMAGE  = "image"
+	ARTIFACT_BINARY = "binary"
+	ARTIFACT_COMMIT = "commit"
+)
+
+// Artifact is what was shipped as a version:
+//   - image: Reference is the image name, Digest its sha256:<hex> digest
+//   - binary: Reference is the download URL, Digest its sha256:<hex> checksum
+//   - commit: Reference is the repository, optional, Digest the commit hash
+type Artifact struct {
+	Type      string `json:"type"`
+	Reference string `json:"reference,omitempty"`
+	Digest    string `json:"digest"`
+}
+
+type ServiceVersionWithArtifacts struct {
+	Name         string       `json:"name"`
+	ServiceName  string       `json:"service_name"`
+	Description  string       `json:"description"`
+	CreatedAt    time.Time    `json:"created_at"`
+	Artifacts    []Artifact   `json:"artifacts"`
+	ReleaseNotes ReleaseNotes `json:"release_notes"`
+}
+
+// ArtifactMatch is a version shipping an artifact with the digest looked up
+type ArtifactMatch struct {
+	ServiceName string    `json:"service_name"`
+	VersionName string    `json:"version_name"`
+	Type        string    `json:"type"`
+	Reference   string    `json:"reference,omitempty"`
+	Digest      string    `json:"digest"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ArtifactLookupResponse struct {
+	Digest  string          `json:"digest"`
+	Matches []ArtifactMatch `json:"matches"`
+}
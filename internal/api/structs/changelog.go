@@ -0,0 +1,26 @@
+package structs
+
+import "time"
+
+// ReleaseNotes of a version, each entry of a section is Markdown
+type ReleaseNotes struct {
+	Added    []string `json:"added,omitempty"`
+	Changed  []string `json:"changed,omitempty"`
+	Fixed    []string `json:"fixed,omitempty"`
+	Security []string `json:"security,omitempty"`
+}
+
+type VersionReleaseNotes struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ReleaseNotes ReleaseNotes `json:"release_notes"`
+}
+
+// the release notes of a range of versions, latest version first
+type ChangelogResponse struct {
+	ServiceName string                `json:"service_name"`
+	From        string                `json:"from,omitempty"`
+	To          string                `json:"to,omitempty"`
+	Versions    []VersionReleaseNotes `json:"versions"`
+}
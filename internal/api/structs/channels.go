@@ -0,0 +1,37 @@
+package structs
+
+import "time"
+
+// Version is the name of a version, or of another channel to point to the
+// same version
+type ChannelRequest struct {
+	Version string `json:"version"`
+}
+
+type ChannelResponse struct {
+	Name        string    `json:"name"`
+	VersionName string    `json:"version_name"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ChannelListResponse struct {
+	ServiceName string            `json:"service_name"`
+	Channels    []ChannelResponse `json:"channels"`
+}
+
+// FromVersion is empty when the channel was created and ToVersion when it was
+// deleted
+type ChannelMoveResponse struct {
+	Channel     string    `json:"channel"`
+	FromVersion string    `json:"from_version,omitempty"`
+	ToVersion   string    `json:"to_version,omitempty"`
+	MovedAt     time.Time `json:"moved_at"`
+}
+
+// paginated response structures
+type ChannelHistoryResponse struct {
+	Moves        []ChannelMoveResponse `json:"moves"`
+	TotalPages   int                   `json:"total_pages"`
+	CurrentPage  int                   `json:"current_page"`
+	TotalRecords int64                 `json:"total_records"`
+}
@@ -0,0 +1,50 @@
+package structs
+
+import "time"
+
+type EnvironmentRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type EnvironmentResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type EnvironmentListResponse struct {
+	Environments []EnvironmentResponse `json:"environments"`
+}
+
+// Actor is whoever, or whatever pipeline, deployed the version
+type DeploymentRequest struct {
+	Version     string `json:"version"`
+	Environment string `json:"environment"`
+	Actor       string `json:"actor"`
+}
+
+type DeploymentResponse struct {
+	ServiceName string    `json:"service_name"`
+	VersionName string    `json:"version_name"`
+	Environment string    `json:"environment"`
+	Actor       string    `json:"actor"`
+	DeployedAt  time.Time `json:"deployed_at"`
+}
+
+// paginated response structures
+type DeploymentHistoryResponse struct {
+	Deployments  []DeploymentResponse `json:"deployments"`
+	TotalPages   int                  `json:"total_pages"`
+	CurrentPage  int                  `json:"current_page"`
+	TotalRecords int64                `json:"total_records"`
+}
+
+// the latest deployment of every service to an environment, by service name
+type EnvironmentServicesResponse struct {
+	Environment  string               `json:"environment"`
+	Services     []DeploymentResponse `json:"services"`
+	TotalPages   int                  `json:"total_pages"`
+	CurrentPage  int                  `json:"current_page"`
+	TotalRecords int64                `json:"total_records"`
+}
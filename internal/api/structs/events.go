@@ -0,0 +1,49 @@
+package structs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// payloads stored with each event
+type ServiceEventData struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	VersionCount int    `json:"version_count"`
+	PreviousName string `json:"previous_name,omitempty"`
+}
+
+type VersionEventData struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description"`
+	ServiceName  string        `json:"service_name"`
+	Artifacts    []Artifact    `json:"artifacts,omitempty"`
+	ReleaseNotes *ReleaseNotes `json:"release_notes,omitempty"`
+}
+
+type DeploymentEventData struct {
+	ServiceName string `json:"service_name"`
+	VersionName string `json:"version_name"`
+	Environment string `json:"environment"`
+	Actor       string `json:"actor"`
+}
+
+// FromVersion is empty when the channel was created and ToVersion when it was
+// deleted
+type ChannelEventData struct {
+	ServiceName string `json:"service_name"`
+	Channel     string `json:"channel"`
+	FromVersion string `json:"from_version,omitempty"`
+	ToVersion   string `json:"to_version,omitempty"`
+}
+
+// message sent on the change stream
+type EventResponse struct {
+	ID          uint            `json:"id"`
+	Type        string          `json:"type"`
+	ServiceName string          `json:"service_name"`
+	VersionName string          `json:"version_name,omitempty"`
+	Data        json.RawMessage `json:"data"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
@@ -0,0 +1,8 @@
+package structs
+
+// HealthResponse is the outcome of a probe, each check being "ok" or the
+// reason it failed
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
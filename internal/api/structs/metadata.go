@@ -0,0 +1,30 @@
+package structs
+
+// Link types accepted on services
+const (
+	LINK_REPOSITORY = "repository"
+	LINK_RUNBOOK    = "runbook"
+	LINK_DASHBOARD  = "dashboard"
+	LINK_DOCS       = "docs"
+)
+
+type ServiceLink struct {
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+type ServiceContact struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// free-form, e.g. owner or on-call
+	Role string `json:"role,omitempty"`
+}
+
+// ServiceMetadata is embedded in the service responses
+type ServiceMetadata struct {
+	Links    []ServiceLink    `json:"links"`
+	Contacts []ServiceContact `json:"contacts"`
+	// validated against the JSON Schema configured by the admin, if any
+	Attributes map[string]interface{} `json:"attributes"`
+}
@@ -1,13 +1,20 @@
 package structs
 
+// On updates, empty fields are left unchanged. Metadata is replaced as a
+// whole when present, an empty list or object clears it.
 type ServiceRequest struct {
-	ID          uint   `json:"id,omitempty"`
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
+	ID          uint                   `json:"id,omitempty"`
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Links       []ServiceLink          `json:"links,omitempty"`
+	Contacts    []ServiceContact       `json:"contacts,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
 }
 
 type ServiceVersionRequest struct {
-	Name        string `json:"name"`
-	ServiceName string `json:"service_name"`
-	Description string `json:"description"`
+	Name         string        `json:"name"`
+	ServiceName  string        `json:"service_name"`
+	Description  string        `json:"description"`
+	Artifacts    []Artifact    `json:"artifacts,omitempty"`
+	ReleaseNotes *ReleaseNotes `json:"release_notes,omitempty"`
 }
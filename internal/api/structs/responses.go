@@ -9,6 +9,8 @@ type ServiceResponse struct {
 	Description  string    `json:"description"`
 	VersionCount int       `json:"version_count"`
 	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ServiceMetadata
 }
 
 type ServiceVersion struct {
@@ -25,14 +27,23 @@ type ServicePaginationResponse struct {
 	TotalRecords int64             `json:"total_records"`
 }
 
+type VersionPaginationResponse struct {
+	Versions     []ServiceVersion `json:"versions"`
+	TotalPages   int              `json:"total_pages"`
+	CurrentPage  int              `json:"current_page"`
+	TotalRecords int64            `json:"total_records"`
+}
+
 type ServiceResponseWithVersionPagination struct {
 	ID                  uint             `json:"id"`
 	Name                string           `json:"name"`
 	Description         string           `json:"description"`
 	VersionCount        int              `json:"version_count"`
 	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at"`
 	Versions            []ServiceVersion `json:"versions"`
 	TotalPages          int              `json:"total_pages"`
 	CurrentPage         int              `json:"current_page"`
 	TotalVersionRecords int64            `json:"total_version_records"`
+	ServiceMetadata
 }
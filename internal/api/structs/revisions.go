@@ -0,0 +1,41 @@
+package structs
+
+import "time"
+
+// service fields tracked by the history, stored with each revision
+type ServiceSnapshot struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ServiceMetadata
+}
+
+// From is null for the fields set when the service is created
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type ServiceRevisionResponse struct {
+	Revision  int           `json:"revision"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type ServiceHistoryResponse struct {
+	Revisions    []ServiceRevisionResponse `json:"revisions"`
+	TotalPages   int                       `json:"total_pages"`
+	CurrentPage  int                       `json:"current_page"`
+	TotalRecords int64                     `json:"total_records"`
+}
+
+// the service as it looked at a revision
+type ServiceAtRevisionResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Revision    int       `json:"revision"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ServiceMetadata
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"net/http"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetServiceAliases lists the previous names of a service, which keep
+// resolving to it
+func GetServiceAliases(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	aliases, err := controllers.GetServiceAliases(db, ctx.Param("serviceName"))
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, aliases)
+}
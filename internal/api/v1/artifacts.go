@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"net/http"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/artifacts"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetVersion(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	version, err := controllers.GetVersionWithArtifacts(db, ctx.Param("serviceName"), ctx.Param("versionName"))
+	if err != nil {
+		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND || err.Error() == constants.VERSION_RECORD_NOT_FOUND {
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		}
+		if err.Error() == constants.SERVICE_RENAMED {
+			return renames.Redirect(ctx, err)
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	releaseNotes, err := controllers.ReleaseNotes(*version)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := api.ServiceVersionWithArtifacts{
+		Name:         version.Name,
+		ServiceName:  version.Service.Name,
+		Description:  version.Description,
+		CreatedAt:    version.CreatedAt,
+		Artifacts:    []api.Artifact{},
+		ReleaseNotes: releaseNotes,
+	}
+	for _, artifact := range version.Artifacts {
+		response.Artifacts = append(response.Artifacts, api.Artifact{
+			Type:      artifact.Type,
+			Reference: artifact.Reference,
+			Digest:    artifact.Digest,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// LookupArtifact tells which service versions shipped an artifact, e.g. the
+// image of a running container
+func LookupArtifact(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	digests, err := artifacts.LookupDigests(ctx.Param("digest"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	matches, err := controllers.FindArtifactsByDigest(db, digests)
+	if err != nil {
+		if err.Error() == constants.ARTIFACT_RECORD_NOT_FOUND {
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, api.ArtifactLookupResponse{
+		Digest:  digests[0],
+		Matches: matches,
+	})
+}
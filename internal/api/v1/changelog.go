@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"net/http"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	"github.com/Prashansa-K/serviceCatalog/internal/changelog"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetChangelog aggregates the release notes of a range of versions, rendered
+// in the format asked for
+func GetChangelog(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = changelog.FORMAT_JSON
+	}
+	if !changelog.IsValidFormat(format) {
+		return ctx.JSON(http.StatusBadRequest, constants.INVALID_CHANGELOG_FORMAT)
+	}
+
+	response, err := controllers.GetChangelog(db, ctx.Param("serviceName"), ctx.QueryParam("from"), ctx.QueryParam("to"))
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.INVALID_VERSION_RANGE:
+			return ctx.JSON(http.StatusBadRequest, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	switch format {
+	case changelog.FORMAT_MARKDOWN:
+		return ctx.Blob(http.StatusOK, changelog.MARKDOWN_MIME+"; charset=UTF-8", []byte(changelog.Markdown(*response)))
+
+	case changelog.FORMAT_KEEP_A_CHANGELOG:
+		return ctx.Blob(http.StatusOK, changelog.MARKDOWN_MIME+"; charset=UTF-8", []byte(changelog.KeepAChangelog(*response)))
+
+	case changelog.FORMAT_HTML:
+		page, err := changelog.HTML(*response)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, err.Error())
+		}
+		return ctx.HTML(http.StatusOK, page)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
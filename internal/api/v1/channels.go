@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetChannels(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	channels, err := controllers.GetChannels(db, ctx.Param("serviceName"))
+	if err != nil {
+		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		}
+		if err.Error() == constants.SERVICE_RENAMED {
+			return renames.Redirect(ctx, err)
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, channels)
+}
+
+// SetChannel creates the channel, or moves it to another version
+func SetChannel(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	var channelRequest api.ChannelRequest
+	if err := ctx.Bind(&channelRequest); err != nil || channelRequest.Version == "" {
+		return ctx.JSON(http.StatusBadRequest, echo.Map{
+			"error": constants.INVALID_REQUEST_BODY,
+		})
+	}
+
+	var created bool
+	err = renames.Follow(ctx, func(serviceName string) error {
+		created, err = controllers.SetChannel(db, serviceName, ctx.Param("channel"), channelRequest)
+		return err
+	})
+	if err != nil {
+		switch err.Error() {
+		case constants.INVALID_CHANNEL_NAME:
+			return ctx.JSON(http.StatusBadRequest, echo.Map{
+				"error": err.Error(),
+			})
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.DUPLICATE_CHANNEL_ERROR:
+			return ctx.JSON(http.StatusConflict, err.Error())
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	if created {
+		return ctx.JSON(http.StatusCreated, echo.Map{
+			"message": constants.CHANNEL_CREATED,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, echo.Map{
+		"message": constants.CHANNEL_MOVED,
+	})
+}
+
+func DeleteChannel(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	if err := renames.Follow(ctx, func(serviceName string) error {
+		return controllers.DeleteChannel(db, serviceName, ctx.Param("channel"))
+	}); err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.CHANNEL_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, echo.Map{
+		"message": constants.CHANNEL_DELETED,
+	})
+}
+
+func GetChannelHistory(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	// get paging information
+	page, err := strconv.Atoi(ctx.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	totalMoves, moves, err := controllers.GetChannelHistory(db, page, ctx.Param("serviceName"), ctx.Param("channel"))
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.CHANNEL_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.INVALID_PAGE_NUMBER:
+			return ctx.JSON(http.StatusBadRequest, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, api.ChannelHistoryResponse{
+		Moves:        moves,
+		TotalPages:   int(math.Ceil(float64(totalMoves) / float64(constants.PAGE_SIZE))),
+		CurrentPage:  page,
+		TotalRecords: totalMoves,
+	})
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetEnvironments(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	environments, err := controllers.GetEnvironments(db)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := api.EnvironmentListResponse{Environments: []api.EnvironmentResponse{}}
+	for _, environment := range environments {
+		response.Environments = append(response.Environments, api.EnvironmentResponse{
+			Name:        environment.Name,
+			Description: environment.Description,
+			CreatedAt:   environment.CreatedAt,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func CreateEnvironment(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	var environmentRequest api.EnvironmentRequest
+	if err := ctx.Bind(&environmentRequest); err != nil {
+		return ctx.JSON(http.StatusBadRequest, echo.Map{
+			"error": constants.INVALID_REQUEST_BODY,
+		})
+	}
+
+	if err := controllers.CreateEnvironment(db, environmentRequest); err != nil {
+		switch err.Error() {
+		case constants.INVALID_ENVIRONMENT_NAME:
+			return ctx.JSON(http.StatusBadRequest, echo.Map{
+				"error": err.Error(),
+			})
+		case constants.DUPLICATE_ENVIRONMENT_ERROR:
+			return ctx.JSON(http.StatusConflict, err.Error())
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusCreated, echo.Map{
+		"message": constants.ENVIRONMENT_CREATED,
+	})
+}
+
+// GetEnvironmentServices lists the version of each service running in the
+// environment
+func GetEnvironmentServices(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	// get paging information
+	page, err := strconv.Atoi(ctx.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	environment := ctx.Param("environment")
+	totalServices, deployments, err := controllers.GetEnvironmentServices(db, page, environment)
+	if err != nil {
+		switch err.Error() {
+		case constants.ENVIRONMENT_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.INVALID_PAGE_NUMBER:
+			return ctx.JSON(http.StatusBadRequest, err.Error())
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, api.EnvironmentServicesResponse{
+		Environment:  environment,
+		Services:     deployments,
+		TotalPages:   int(math.Ceil(float64(totalServices) / float64(constants.PAGE_SIZE))),
+		CurrentPage:  page,
+		TotalRecords: totalServices,
+	})
+}
+
+func DeployVersion(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	var deploymentRequest api.DeploymentRequest
+	if err := ctx.Bind(&deploymentRequest); err != nil || deploymentRequest.Version == "" || deploymentRequest.Environment == "" {
+		return ctx.JSON(http.StatusBadRequest, echo.Map{
+			"error": constants.INVALID_REQUEST_BODY,
+		})
+	}
+
+	deploymentRequest.Actor = strings.TrimSpace(deploymentRequest.Actor)
+	if deploymentRequest.Actor == "" {
+		return ctx.JSON(http.StatusBadRequest, echo.Map{
+			"error": constants.MISSING_DEPLOYMENT_ACTOR,
+		})
+	}
+
+	if err := renames.Follow(ctx, func(serviceName string) error {
+		return controllers.DeployVersion(db, serviceName, deploymentRequest)
+	}); err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND, constants.ENVIRONMENT_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusCreated, echo.Map{
+		"message": constants.VERSION_DEPLOYED,
+	})
+}
+
+func GetDeploymentHistory(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	// get paging information
+	page, err := strconv.Atoi(ctx.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	totalDeployments, deployments, err := controllers.GetDeploymentHistory(db, page, ctx.Param("serviceName"), ctx.QueryParam("environment"))
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.ENVIRONMENT_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.INVALID_PAGE_NUMBER:
+			return ctx.JSON(http.StatusBadRequest, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, api.DeploymentHistoryResponse{
+		Deployments:  deployments,
+		TotalPages:   int(math.Ceil(float64(totalDeployments) / float64(constants.PAGE_SIZE))),
+		CurrentPage:  page,
+		TotalRecords: totalDeployments,
+	})
+}
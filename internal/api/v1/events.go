@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/events"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	LAST_EVENT_ID_HEADER = "Last-Event-ID"
+	EVENT_STREAM_MIME    = "text/event-stream"
+
+	// reconnection delay suggested to clients, in milliseconds
+	EVENT_STREAM_RETRY = 3000
+
+	EVENT_STREAM_HEARTBEAT = 15 * time.Second
+)
+
+func StreamEvents(ctx echo.Context) error {
+	// queries stop with the stream
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	filter, err := parseEventFilter(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	lastEventID, err := parseLastEventID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	// Subscribe before replaying so that nothing dispatched in between is
+	// lost. Duplicates are skipped using the position of the events.
+	subscription := events.Subscribe(filter)
+	defer events.Unsubscribe(subscription)
+
+	// without Last-Event-ID, the stream starts with the events to come
+	var position events.Position
+	if lastEventID > 0 {
+		position, err = controllers.GetEventPosition(db, lastEventID)
+		if err != nil {
+			if err.Error() == constants.UNKNOWN_LAST_EVENT_ID {
+				return ctx.JSON(http.StatusBadRequest, err.Error())
+			}
+			return ctx.JSON(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, EVENT_STREAM_MIME)
+	response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	response.Header().Set(echo.HeaderConnection, "keep-alive")
+	// disables response buffering in nginx-like proxies
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(response, "retry: %d\n\n", EVENT_STREAM_RETRY); err != nil {
+		return nil
+	}
+	response.Flush()
+
+	if lastEventID > 0 {
+		position, err = controllers.ReplayEvents(db, position, filter, func(event models.Event) error {
+			return writeEvent(response, event)
+		})
+		if err != nil {
+			if ctx.Request().Context().Err() == nil {
+				slog.ErrorContext(ctx.Request().Context(), "error replaying events", "error", err)
+			}
+			return nil
+		}
+	}
+
+	heartbeat := time.NewTicker(EVENT_STREAM_HEARTBEAT)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+
+		case event, ok := <-subscription.C:
+			if !ok {
+				// dropped for being too slow, the client resumes with Last-Event-ID
+				return nil
+			}
+
+			if !events.PositionOf(event).After(position) {
+				continue
+			}
+
+			if err := writeEvent(response, event); err != nil {
+				return nil
+			}
+			position = events.PositionOf(event)
+
+		case <-heartbeat.C:
+			// comment lines keep idle connections open through proxies
+			if _, err := fmt.Fprint(response, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			response.Flush()
+		}
+	}
+}
+
+func parseEventFilter(ctx echo.Context) (events.Filter, error) {
+	filter := events.Filter{
+		ServiceName: ctx.QueryParam("service_name"),
+	}
+
+	if types := ctx.QueryParam("type"); types != "" {
+		for _, eventType := range strings.Split(types, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if !events.IsValidEventType(eventType) {
+				return filter, errors.New(constants.INVALID_EVENT_TYPE)
+			}
+			filter.Types = append(filter.Types, eventType)
+		}
+	}
+
+	return filter, nil
+}
+
+func parseLastEventID(ctx echo.Context) (uint, error) {
+	value := ctx.Request().Header.Get(LAST_EVENT_ID_HEADER)
+	if value == "" {
+		return 0, nil
+	}
+
+	lastEventID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errors.New(constants.INVALID_LAST_EVENT_ID)
+	}
+
+	return uint(lastEventID), nil
+}
+
+func writeEvent(response *echo.Response, event models.Event) error {
+	payload, err := json.Marshal(events.ToResponse(event))
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(response, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload); err != nil {
+		return err
+	}
+	response.Flush()
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetServiceHistory(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	// get paging information
+	page, err := strconv.Atoi(ctx.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	totalRevisions, revisions, err := controllers.GetServiceHistory(db, page, ctx.Param("serviceName"))
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		case constants.INVALID_PAGE_NUMBER:
+			return ctx.JSON(http.StatusBadRequest, err.Error())
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := []api.ServiceRevisionResponse{}
+	for _, revision := range revisions {
+		var changes []api.FieldChange
+		if err := json.Unmarshal([]byte(revision.Changes), &changes); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		response = append(response, api.ServiceRevisionResponse{
+			Revision:  revision.Revision,
+			Changes:   changes,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	totalPages := int(math.Ceil(float64(totalRevisions) / float64(constants.PAGE_SIZE)))
+
+	return ctx.JSON(http.StatusOK, api.ServiceHistoryResponse{
+		Revisions:    response,
+		TotalPages:   totalPages,
+		CurrentPage:  page,
+		TotalRecords: totalRevisions,
+	})
+}
+
+func GetServiceRevision(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	revision, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil || revision < 1 {
+		return ctx.JSON(http.StatusBadRequest, constants.INVALID_REVISION)
+	}
+
+	service, err := controllers.GetServiceAtRevision(db, ctx.Param("serviceName"), revision)
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.REVISION_RECORD_NOT_FOUND:
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	serviceMetadata, err := controllers.ServiceMetadata(*service)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, api.ServiceAtRevisionResponse{
+		ID:              service.ID,
+		Name:            service.Name,
+		Description:     service.Description,
+		Revision:        service.Revision,
+		CreatedAt:       service.CreatedAt,
+		UpdatedAt:       service.UpdatedAt,
+		ServiceMetadata: serviceMetadata,
+	})
+}
@@ -1,21 +1,28 @@
 package v1
 
 import (
+	"errors"
 	"math"
 	"net/http"
 	"strconv"
 	"strings"
 
 	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
 	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/artifacts"
+	"github.com/Prashansa-K/serviceCatalog/internal/changelog"
+	"github.com/Prashansa-K/serviceCatalog/internal/consistency"
 	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
 	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
 
 	"github.com/labstack/echo/v4"
 )
 
 func GetServices(ctx echo.Context) error {
-	db, err := db.GetDB()
+	// served by a replica, unless the caller has to see its latest writes
+	db, err := consistency.ReadDB(ctx)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
@@ -33,19 +40,16 @@ func GetServices(ctx echo.Context) error {
 		sort = constants.ASC
 	}
 
-	// Per function tracing
-	// var services []models.Service
-	// var ok bool
-	// rawData := tracer.TraceServiceFunc(ctx, controllers.GetPaginatedServicesByFilters, db, page, sort, ctx.QueryParam("name"), ctx.QueryParam("description"))
-	// totalServices := rawData.([]reflect.Value)[0].Int()
-	// if services, ok = rawData.([]reflect.Value)[1].Interface().([]models.Service); !ok {
-	// 	services = nil
-	// }
-	// if rawData.([]reflect.Value)[2].Interface() != nil {
-	// 	err = rawData.([]reflect.Value)[2].Interface().(error)
-	// }
+	// filters on attributes, e.g. attributes[team.name]=payments
+	attributeFilters, err := metadata.ParseAttributeFilters(ctx.QueryParams())
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, err.Error())
+	}
 
-	totalServices, services, err := controllers.GetPaginatedServicesByFilters(db, page, sort, ctx.QueryParam("name"), ctx.QueryParam("description"))
+	totalServices, services, cacheStatus, err := controllers.CachedPaginatedServicesByFilters(db, page, sort, ctx.QueryParam("name"), ctx.QueryParam("description"), attributeFilters)
+	if cacheStatus != nil {
+		ctx.Response().Header().Set(constants.CACHE_STATUS_HEADER, cacheStatus.String())
+	}
 
 	if err != nil {
 		if err.Error() == constants.INVALID_PAGE_NUMBER {
@@ -56,11 +60,19 @@ func GetServices(ctx echo.Context) error {
 
 	var response []api.ServiceResponse
 	for _, service := range services {
+		serviceMetadata, err := controllers.ServiceMetadata(service)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, err.Error())
+		}
+
 		response = append(response, api.ServiceResponse{
-			ID:           service.ID,
-			Name:         service.Name,
-			Description:  service.Description,
-			VersionCount: service.VersionCount,
+			ID:              service.ID,
+			Name:            service.Name,
+			Description:     service.Description,
+			VersionCount:    service.VersionCount,
+			CreatedAt:       service.CreatedAt,
+			UpdatedAt:       service.UpdatedAt,
+			ServiceMetadata: serviceMetadata,
 		})
 	}
 
@@ -75,7 +87,8 @@ func GetServices(ctx echo.Context) error {
 }
 
 func GetService(ctx echo.Context) error {
-	db, err := db.GetDB()
+	// served by a replica, unless the caller has to see its latest writes
+	db, err := consistency.ReadDB(ctx)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
@@ -86,11 +99,17 @@ func GetService(ctx echo.Context) error {
 		page = 1
 	}
 
-	totalVersions, service, err := controllers.GetServiceByNameWithPaginatedVersions(db, page, ctx.Param("serviceName"))
+	totalVersions, service, cacheStatus, err := controllers.CachedServiceByNameWithPaginatedVersions(db, page, ctx.Param("serviceName"))
+	if cacheStatus != nil {
+		ctx.Response().Header().Set(constants.CACHE_STATUS_HEADER, cacheStatus.String())
+	}
 	if err != nil {
 		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
 			return ctx.JSON(http.StatusNotFound, err.Error())
 		}
+		if err.Error() == constants.SERVICE_RENAMED {
+			return renames.Redirect(ctx, err)
+		}
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
@@ -103,23 +122,30 @@ func GetService(ctx echo.Context) error {
 		})
 	}
 
-	totalPages := int((totalVersions + constants.PAGE_SIZE - 1) / constants.PAGE_SIZE)
+	serviceMetadata, err := controllers.ServiceMetadata(*service)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	totalPages := int((totalVersions + int64(constants.PAGE_SIZE) - 1) / int64(constants.PAGE_SIZE))
 
 	return ctx.JSON(http.StatusOK, api.ServiceResponseWithVersionPagination{
 		ID:                  service.ID,
 		Name:                service.Name,
 		Description:         service.Description,
 		CreatedAt:           service.CreatedAt,
+		UpdatedAt:           service.UpdatedAt,
 		VersionCount:        service.VersionCount,
 		Versions:            versions,
 		TotalPages:          totalPages,
 		CurrentPage:         page,
 		TotalVersionRecords: totalVersions,
+		ServiceMetadata:     serviceMetadata,
 	})
 }
 
 func CreateService(ctx echo.Context) error {
-	db, err := db.GetDB()
+	db, err := db.GetDBContext(ctx.Request().Context())
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
@@ -131,7 +157,23 @@ func CreateService(ctx echo.Context) error {
 		})
 	}
 
+	if err := metadata.Validate(serviceRequest); err != nil {
+		return ctx.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
 	if err := controllers.CreateService(db, serviceRequest); err != nil {
+		if err.Error() == constants.DUPLICATE_SERVICE_NAME_ERROR {
+			return ctx.JSON(http.StatusConflict, err.Error())
+		}
+
+		if errors.Is(err, metadata.ErrInvalidAttributes) {
+			return ctx.JSON(http.StatusBadRequest, echo.Map{
+				"error": err.Error(),
+			})
+		}
+
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
@@ -141,7 +183,7 @@ func CreateService(ctx echo.Context) error {
 }
 
 func CreateVersion(ctx echo.Context) error {
-	db, err := db.GetDB()
+	db, err := db.GetDBContext(ctx.Request().Context())
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
@@ -153,7 +195,32 @@ func CreateVersion(ctx echo.Context) error {
 		})
 	}
 
+	versionRequest.Artifacts, err = artifacts.Normalize(versionRequest.Artifacts)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if versionRequest.ReleaseNotes != nil {
+		releaseNotes, err := changelog.Normalize(*versionRequest.ReleaseNotes)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, echo.Map{
+				"error": err.Error(),
+			})
+		}
+		versionRequest.ReleaseNotes = &releaseNotes
+	}
+
 	if err := controllers.CreateVersion(db, versionRequest); err != nil {
+		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
+			return ctx.JSON(http.StatusNotFound, err.Error())
+		}
+
+		if err.Error() == constants.DUPLICATE_VERSION_RECORD_ERROR {
+			return ctx.JSON(http.StatusConflict, err.Error())
+		}
+
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
@@ -163,12 +230,14 @@ func CreateVersion(ctx echo.Context) error {
 }
 
 func DeleteService(ctx echo.Context) error {
-	db, err := db.GetDB()
+	db, err := db.GetDBContext(ctx.Request().Context())
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
-	if err := controllers.DeleteService(db, ctx.Param("serviceName")); err != nil {
+	if err := renames.Follow(ctx, func(serviceName string) error {
+		return controllers.DeleteService(db, serviceName)
+	}); err != nil {
 		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
 			return ctx.JSON(http.StatusNotFound, err.Error())
 		}
@@ -181,12 +250,17 @@ func DeleteService(ctx echo.Context) error {
 }
 
 func DeleteVersion(ctx echo.Context) error {
-	db, err := db.GetDB()
+	db, err := db.GetDBContext(ctx.Request().Context())
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
-	if err := controllers.DeleteVersion(db, ctx.Param("serviceName"), ctx.Param("versionName")); err != nil {
+	// a version currently deployed is only deleted when forced
+	force, _ := strconv.ParseBool(ctx.QueryParam("force"))
+
+	if err := renames.Follow(ctx, func(serviceName string) error {
+		return controllers.DeleteVersion(db, serviceName, ctx.Param("versionName"), force)
+	}); err != nil {
 		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
 			return ctx.JSON(http.StatusNotFound, err.Error())
 		}
@@ -195,6 +269,10 @@ func DeleteVersion(ctx echo.Context) error {
 			return ctx.JSON(http.StatusNotFound, err.Error())
 		}
 
+		if err.Error() == constants.VERSION_CURRENTLY_DEPLOYED || err.Error() == constants.VERSION_POINTED_TO_BY_CHANNEL {
+			return ctx.JSON(http.StatusConflict, err.Error())
+		}
+
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
@@ -204,7 +282,7 @@ func DeleteVersion(ctx echo.Context) error {
 }
 
 func UpdateService(ctx echo.Context) error {
-	db, err := db.GetDB()
+	db, err := db.GetDBContext(ctx.Request().Context())
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
@@ -216,15 +294,31 @@ func UpdateService(ctx echo.Context) error {
 		})
 	}
 
+	if err := metadata.Validate(serviceRequest); err != nil {
+		return ctx.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
 	if err := controllers.UpdateService(db, serviceRequest); err != nil {
 		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
 			return ctx.JSON(http.StatusNotFound, err.Error())
 		}
 
+		if err.Error() == constants.DUPLICATE_SERVICE_NAME_ERROR {
+			return ctx.JSON(http.StatusConflict, err.Error())
+		}
+
+		if errors.Is(err, metadata.ErrInvalidAttributes) {
+			return ctx.JSON(http.StatusBadRequest, echo.Map{
+				"error": err.Error(),
+			})
+		}
+
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
 	return ctx.JSON(http.StatusOK, echo.Map{
-		"message": http.StatusAccepted,
+		"message": constants.SERVICE_UPDATED,
 	})
 }
@@ -0,0 +1,287 @@
+// Package v2 serves services and their versions as resources, addressed by
+// name in the path, on top of the controllers of v1
+package v2
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func ListServices(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	page, err := strconv.Atoi(ctx.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	sort := strings.ToUpper(ctx.QueryParam("sort"))
+	if sort != constants.ASC && sort != constants.DESC {
+		sort = constants.ASC
+	}
+
+	attributeFilters, err := metadata.ParseAttributeFilters(ctx.QueryParams())
+	if err != nil {
+		return errorJSON(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	totalServices, services, cacheStatus, err := controllers.CachedPaginatedServicesByFilters(db, page, sort, ctx.QueryParam("name"), ctx.QueryParam("description"), attributeFilters)
+	if cacheStatus != nil {
+		ctx.Response().Header().Set(constants.CACHE_STATUS_HEADER, cacheStatus.String())
+	}
+	if err != nil {
+		if err.Error() == constants.INVALID_PAGE_NUMBER {
+			return errorJSON(ctx, http.StatusBadRequest, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	response := api.ServicePaginationResponse{
+		Services:     []api.ServiceResponse{},
+		TotalPages:   int(math.Ceil(float64(totalServices) / float64(constants.PAGE_SIZE))),
+		CurrentPage:  page,
+		TotalRecords: totalServices,
+	}
+	for _, service := range services {
+		serviceResponse, err := toServiceResponse(service)
+		if err != nil {
+			return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+		}
+		response.Services = append(response.Services, serviceResponse)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func CreateService(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	serviceRequest, err := bindServiceRequest(ctx)
+	if err != nil {
+		return errorJSON(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	if serviceRequest.Name == "" {
+		return errorJSON(ctx, http.StatusBadRequest, constants.MISSING_NAME)
+	}
+
+	if err := controllers.CreateService(db, serviceRequest); err != nil {
+		if err.Error() == constants.DUPLICATE_SERVICE_NAME_ERROR {
+			return errorJSON(ctx, http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, metadata.ErrInvalidAttributes) {
+			return errorJSON(ctx, http.StatusBadRequest, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return writeService(ctx, http.StatusCreated, serviceRequest.Name)
+}
+
+func GetService(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	_, service, cacheStatus, err := controllers.CachedServiceByNameWithPaginatedVersions(db, 1, ctx.Param("serviceName"))
+	if cacheStatus != nil {
+		ctx.Response().Header().Set(constants.CACHE_STATUS_HEADER, cacheStatus.String())
+	}
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	response, err := toServiceResponse(*service)
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ReplaceService creates the service named in the path, or replaces its
+// description and metadata. Renaming a service is done with PATCH.
+func ReplaceService(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	serviceRequest, err := bindServiceRequest(ctx)
+	if err != nil {
+		return errorJSON(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	if serviceRequest.Name != "" && serviceRequest.Name != ctx.Param("serviceName") {
+		return errorJSON(ctx, http.StatusBadRequest, constants.NAME_DOES_NOT_MATCH_PATH)
+	}
+
+	var created bool
+	var serviceName string
+	if err := renames.Follow(ctx, func(name string) (err error) {
+		serviceName = name
+		created, err = controllers.ReplaceService(db, name, serviceRequest)
+		return err
+	}); err != nil {
+		if err.Error() == constants.DUPLICATE_SERVICE_NAME_ERROR {
+			return errorJSON(ctx, http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, metadata.ErrInvalidAttributes) {
+			return errorJSON(ctx, http.StatusBadRequest, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	if created {
+		return writeService(ctx, http.StatusCreated, serviceName)
+	}
+
+	return writeService(ctx, http.StatusOK, serviceName)
+}
+
+// PatchService changes the fields sent, a name renames the service
+func PatchService(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	serviceRequest, err := bindServiceRequest(ctx)
+	if err != nil {
+		return errorJSON(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	var serviceName string
+	if err := renames.Follow(ctx, func(name string) error {
+		serviceName = name
+		return controllers.PatchService(db, name, serviceRequest)
+	}); err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		case constants.DUPLICATE_SERVICE_NAME_ERROR:
+			return errorJSON(ctx, http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, metadata.ErrInvalidAttributes) {
+			return errorJSON(ctx, http.StatusBadRequest, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	if serviceRequest.Name != "" {
+		serviceName = serviceRequest.Name
+	}
+
+	return writeService(ctx, http.StatusOK, serviceName)
+}
+
+// DeleteService soft deletes the service along with all its versions
+func DeleteService(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	if err := renames.Follow(ctx, func(serviceName string) error {
+		return controllers.DeleteService(db, serviceName)
+	}); err != nil {
+		if err.Error() == constants.SERVICE_RECORD_NOT_FOUND {
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// bindServiceRequest reads a service sent in the body, its id is ignored
+func bindServiceRequest(ctx echo.Context) (api.ServiceRequest, error) {
+	var serviceRequest api.ServiceRequest
+	if err := ctx.Bind(&serviceRequest); err != nil {
+		return serviceRequest, errors.New(constants.INVALID_REQUEST_BODY)
+	}
+	serviceRequest.ID = 0
+
+	if err := metadata.Validate(serviceRequest); err != nil {
+		return serviceRequest, err
+	}
+
+	return serviceRequest, nil
+}
+
+// writeService answers a mutation with the service as it is now, along with
+// its Location when it was created
+func writeService(ctx echo.Context, code int, serviceName string) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	_, service, err := controllers.GetServiceByNameWithPaginatedVersions(db, 1, serviceName)
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	response, err := toServiceResponse(*service)
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	if code == http.StatusCreated {
+		ctx.Response().Header().Set(echo.HeaderLocation, "/v2/services/"+url.PathEscape(service.Name))
+	}
+
+	return ctx.JSON(code, response)
+}
+
+func toServiceResponse(service models.Service) (api.ServiceResponse, error) {
+	serviceMetadata, err := controllers.ServiceMetadata(service)
+	if err != nil {
+		return api.ServiceResponse{}, err
+	}
+
+	return api.ServiceResponse{
+		ID:              service.ID,
+		Name:            service.Name,
+		Description:     service.Description,
+		VersionCount:    service.VersionCount,
+		CreatedAt:       service.CreatedAt,
+		UpdatedAt:       service.UpdatedAt,
+		ServiceMetadata: serviceMetadata,
+	}, nil
+}
+
+// errorJSON answers with the error as {"error": message}, the one shape of
+// the errors of v2
+func errorJSON(ctx echo.Context, code int, message string) error {
+	return ctx.JSON(code, echo.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,296 @@
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/renames"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/artifacts"
+	"github.com/Prashansa-K/serviceCatalog/internal/changelog"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListVersions lists the versions of a service - paginated
+func ListVersions(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	page, err := strconv.Atoi(ctx.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	totalVersions, service, cacheStatus, err := controllers.CachedServiceByNameWithPaginatedVersions(db, page, ctx.Param("serviceName"))
+	if cacheStatus != nil {
+		ctx.Response().Header().Set(constants.CACHE_STATUS_HEADER, cacheStatus.String())
+	}
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	response := api.VersionPaginationResponse{
+		Versions:     []api.ServiceVersion{},
+		TotalPages:   int((totalVersions + int64(constants.PAGE_SIZE) - 1) / int64(constants.PAGE_SIZE)),
+		CurrentPage:  page,
+		TotalRecords: totalVersions,
+	}
+	for _, version := range service.Versions {
+		response.Versions = append(response.Versions, api.ServiceVersion{
+			Name:        version.Name,
+			Description: version.Description,
+			CreatedAt:   version.CreatedAt,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func CreateVersion(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	versionRequest, err := bindVersionRequest(ctx)
+	if err != nil {
+		return errorJSON(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	if versionRequest.Name == "" {
+		return errorJSON(ctx, http.StatusBadRequest, constants.MISSING_NAME)
+	}
+
+	// a previous name of the service is followed by the controller
+	if err := controllers.CreateVersion(db, versionRequest); err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		case constants.DUPLICATE_VERSION_RECORD_ERROR:
+			return errorJSON(ctx, http.StatusConflict, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return writeVersion(ctx, http.StatusCreated, versionRequest.Name)
+}
+
+// GetVersion returns a version along with its artifacts and release notes
+func GetVersion(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	version, err := controllers.GetVersionWithArtifacts(db, ctx.Param("serviceName"), ctx.Param("versionName"))
+	if err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		case constants.SERVICE_RENAMED:
+			return renames.Redirect(ctx, err)
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	response, err := toVersionResponse(*version)
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ReplaceVersion creates the version named in the path, or replaces its
+// description, artifacts and release notes
+func ReplaceVersion(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	versionRequest, err := bindVersionRequest(ctx)
+	if err != nil {
+		return errorJSON(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	var created bool
+	if err := renames.Follow(ctx, func(serviceName string) (err error) {
+		created, err = controllers.ReplaceVersion(db, serviceName, ctx.Param("versionName"), versionRequest)
+		return err
+	}); err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		case constants.DUPLICATE_VERSION_RECORD_ERROR:
+			return errorJSON(ctx, http.StatusConflict, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	if created {
+		return writeVersion(ctx, http.StatusCreated, ctx.Param("versionName"))
+	}
+
+	return writeVersion(ctx, http.StatusOK, ctx.Param("versionName"))
+}
+
+// PatchVersion changes the description, artifacts or release notes sent
+func PatchVersion(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	versionRequest, err := bindVersionRequest(ctx)
+	if err != nil {
+		return errorJSON(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	if err := renames.Follow(ctx, func(serviceName string) error {
+		return controllers.PatchVersion(db, serviceName, ctx.Param("versionName"), versionRequest)
+	}); err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return writeVersion(ctx, http.StatusOK, ctx.Param("versionName"))
+}
+
+// DeleteVersion refuses to delete a version a channel points to, or one
+// currently deployed unless forced
+func DeleteVersion(ctx echo.Context) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	force, _ := strconv.ParseBool(ctx.QueryParam("force"))
+
+	if err := renames.Follow(ctx, func(serviceName string) error {
+		return controllers.DeleteVersion(db, serviceName, ctx.Param("versionName"), force)
+	}); err != nil {
+		switch err.Error() {
+		case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND:
+			return errorJSON(ctx, http.StatusNotFound, err.Error())
+		case constants.VERSION_CURRENTLY_DEPLOYED, constants.VERSION_POINTED_TO_BY_CHANNEL:
+			return errorJSON(ctx, http.StatusConflict, err.Error())
+		}
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// bindVersionRequest reads a version sent in the body, of the service in the
+// path. A name sent has to be the one in the path, if any, since names of
+// versions never change.
+func bindVersionRequest(ctx echo.Context) (api.ServiceVersionRequest, error) {
+	var versionRequest api.ServiceVersionRequest
+	if err := ctx.Bind(&versionRequest); err != nil {
+		return versionRequest, errors.New(constants.INVALID_REQUEST_BODY)
+	}
+
+	if versionRequest.ServiceName != "" && versionRequest.ServiceName != ctx.Param("serviceName") {
+		return versionRequest, errors.New(constants.NAME_DOES_NOT_MATCH_PATH)
+	}
+	versionRequest.ServiceName = ctx.Param("serviceName")
+
+	if versionName := ctx.Param("versionName"); versionName != "" {
+		if versionRequest.Name != "" && versionRequest.Name != versionName {
+			return versionRequest, errors.New(constants.NAME_DOES_NOT_MATCH_PATH)
+		}
+		versionRequest.Name = versionName
+	}
+
+	// artifacts left out are kept by PATCH, where an empty list clears them
+	if versionRequest.Artifacts != nil {
+		normalized, err := artifacts.Normalize(versionRequest.Artifacts)
+		if err != nil {
+			return versionRequest, err
+		}
+		versionRequest.Artifacts = normalized
+	}
+
+	if versionRequest.ReleaseNotes != nil {
+		releaseNotes, err := changelog.Normalize(*versionRequest.ReleaseNotes)
+		if err != nil {
+			return versionRequest, err
+		}
+		versionRequest.ReleaseNotes = &releaseNotes
+	}
+
+	return versionRequest, nil
+}
+
+// writeVersion answers a mutation with the version as it is now, along with
+// its Location when it was created
+func writeVersion(ctx echo.Context, code int, versionName string) error {
+	db, err := db.GetDBContext(ctx.Request().Context())
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	var version *models.Version
+	if err := controllers.FollowRename(ctx.Param("serviceName"), func(serviceName string) (err error) {
+		version, err = controllers.GetVersionWithArtifacts(db, serviceName, versionName)
+		return err
+	}); err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	response, err := toVersionResponse(*version)
+	if err != nil {
+		return errorJSON(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	if code == http.StatusCreated {
+		ctx.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/v2/services/%s/versions/%s", url.PathEscape(version.Service.Name), url.PathEscape(version.Name)))
+	}
+
+	return ctx.JSON(code, response)
+}
+
+func toVersionResponse(version models.Version) (api.ServiceVersionWithArtifacts, error) {
+	releaseNotes, err := controllers.ReleaseNotes(version)
+	if err != nil {
+		return api.ServiceVersionWithArtifacts{}, err
+	}
+
+	response := api.ServiceVersionWithArtifacts{
+		Name:         version.Name,
+		ServiceName:  version.Service.Name,
+		Description:  version.Description,
+		CreatedAt:    version.CreatedAt,
+		Artifacts:    []api.Artifact{},
+		ReleaseNotes: releaseNotes,
+	}
+	for _, artifact := range version.Artifacts {
+		response.Artifacts = append(response.Artifacts, api.Artifact{
+			Type:      artifact.Type,
+			Reference: artifact.Reference,
+			Digest:    artifact.Digest,
+		})
+	}
+
+	return response, nil
+}
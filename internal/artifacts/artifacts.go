@@ -0,0 +1,118 @@
+package artifacts
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+)
+
+const (
+	SHA256_PREFIX = "sha256:"
+)
+
+var Types = []string{
+	api.ARTIFACT_IMAGE,
+	api.ARTIFACT_BINARY,
+	api.ARTIFACT_COMMIT,
+}
+
+var (
+	sha256Hex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	// SHA-1 or SHA-256 object names
+	commitHash = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+	// repository, with an optional registry and tag, e.g. ghcr.io/acme/payments:1.2.0
+	imageName = regexp.MustCompile(`^[a-z0-9]+([._/:-][a-zA-Z0-9]+)*$`)
+)
+
+// Normalize validates the artifacts of a version and returns them with their
+// digests in a canonical form, so that lookups find them whatever the casing
+// or prefix they were sent with.
+func Normalize(artifacts []api.Artifact) ([]api.Artifact, error) {
+	normalized := make([]api.Artifact, 0, len(artifacts))
+
+	for _, artifact := range artifacts {
+		var err error
+
+		artifact.Reference = strings.TrimSpace(artifact.Reference)
+		digest := strings.ToLower(strings.TrimSpace(artifact.Digest))
+
+		switch artifact.Type {
+		case api.ARTIFACT_IMAGE:
+			// images can be sent pinned, as name@sha256:<hex>
+			if name, pinned, found := strings.Cut(artifact.Reference, "@"); found {
+				if digest != "" && digest != strings.ToLower(pinned) {
+					return nil, fmt.Errorf("image %q is pinned to a different digest than %q", artifact.Reference, artifact.Digest)
+				}
+				artifact.Reference, digest = name, strings.ToLower(pinned)
+			}
+
+			if !imageName.MatchString(artifact.Reference) {
+				return nil, fmt.Errorf("invalid image name %q", artifact.Reference)
+			}
+
+			if artifact.Digest, err = normalizeSHA256(digest); err != nil {
+				return nil, fmt.Errorf("invalid digest for image %q, expected sha256:<hex>", artifact.Reference)
+			}
+
+		case api.ARTIFACT_BINARY:
+			parsed, err := url.Parse(artifact.Reference)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return nil, fmt.Errorf("invalid binary URL %q, expected an absolute http(s) URL", artifact.Reference)
+			}
+
+			if artifact.Digest, err = normalizeSHA256(digest); err != nil {
+				return nil, fmt.Errorf("invalid SHA-256 for binary %q", artifact.Reference)
+			}
+
+		case api.ARTIFACT_COMMIT:
+			if !commitHash.MatchString(digest) {
+				return nil, fmt.Errorf("invalid commit hash %q, expected a full hash", artifact.Digest)
+			}
+			artifact.Digest = digest
+
+		default:
+			return nil, fmt.Errorf("invalid artifact type %q, expected one of %s", artifact.Type, strings.Join(Types, ", "))
+		}
+
+		normalized = append(normalized, artifact)
+	}
+
+	return normalized, nil
+}
+
+// LookupDigests returns the stored forms a digest looked up can match. A bare
+// 64 characters hash can be a sha256 digest or a SHA-256 commit.
+func LookupDigests(digest string) ([]string, error) {
+	digest = strings.ToLower(strings.TrimSpace(digest))
+
+	if hash, found := strings.CutPrefix(digest, SHA256_PREFIX); found {
+		if !sha256Hex.MatchString(hash) {
+			return nil, errors.New("invalid sha256 digest")
+		}
+		return []string{digest}, nil
+	}
+
+	if sha256Hex.MatchString(digest) {
+		return []string{digest, SHA256_PREFIX + digest}, nil
+	}
+
+	if commitHash.MatchString(digest) {
+		return []string{digest}, nil
+	}
+
+	return nil, errors.New("invalid digest, expected sha256:<hex> or a commit hash")
+}
+
+// normalizeSHA256 accepts sha256:<hex> as well as the bare hex checksum
+func normalizeSHA256(digest string) (string, error) {
+	hash := strings.TrimPrefix(digest, SHA256_PREFIX)
+	if !sha256Hex.MatchString(hash) {
+		return "", errors.New("invalid sha256 digest")
+	}
+
+	return SHA256_PREFIX + hash, nil
+}
@@ -0,0 +1,71 @@
+package artifacts
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	imageDigest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	checksum    = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+	commit      = "3f786850e387550fdab836ed7e6dc881de23001b"
+)
+
+func TestNormalize(t *testing.T) {
+	normalized, err := Normalize([]api.Artifact{
+		{Type: api.ARTIFACT_IMAGE, Reference: "ghcr.io/acme/payments:1.2.0", Digest: strings.ToUpper(imageDigest)},
+		{Type: api.ARTIFACT_IMAGE, Reference: "ghcr.io/acme/payments@" + imageDigest},
+		{Type: api.ARTIFACT_BINARY, Reference: "https://releases.example.com/payments", Digest: checksum},
+		{Type: api.ARTIFACT_COMMIT, Reference: "git@example.com:acme/payments.git", Digest: commit},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []api.Artifact{
+		{Type: api.ARTIFACT_IMAGE, Reference: "ghcr.io/acme/payments:1.2.0", Digest: imageDigest},
+		{Type: api.ARTIFACT_IMAGE, Reference: "ghcr.io/acme/payments", Digest: imageDigest},
+		{Type: api.ARTIFACT_BINARY, Reference: "https://releases.example.com/payments", Digest: "sha256:" + checksum},
+		{Type: api.ARTIFACT_COMMIT, Reference: "git@example.com:acme/payments.git", Digest: commit},
+	}, normalized)
+}
+
+func TestNormalize_Invalid(t *testing.T) {
+	for _, testCase := range []struct {
+		artifact api.Artifact
+		err      string
+	}{
+		{api.Artifact{Type: "jar", Digest: checksum}, `invalid artifact type "jar"`},
+		{api.Artifact{Type: api.ARTIFACT_IMAGE, Reference: "payments", Digest: "latest"}, "invalid digest"},
+		{api.Artifact{Type: api.ARTIFACT_IMAGE, Reference: "Payments Image", Digest: imageDigest}, "invalid image name"},
+		{api.Artifact{Type: api.ARTIFACT_IMAGE, Reference: "payments@" + imageDigest, Digest: "sha256:" + checksum}, "pinned to a different digest"},
+		{api.Artifact{Type: api.ARTIFACT_BINARY, Reference: "/payments", Digest: checksum}, "invalid binary URL"},
+		{api.Artifact{Type: api.ARTIFACT_BINARY, Reference: "https://releases.example.com/payments", Digest: "md5:abc"}, "invalid SHA-256"},
+		{api.Artifact{Type: api.ARTIFACT_COMMIT, Digest: commit[:7]}, "expected a full hash"},
+	} {
+		_, err := Normalize([]api.Artifact{testCase.artifact})
+		assert.ErrorContains(t, err, testCase.err)
+	}
+}
+
+func TestLookupDigests(t *testing.T) {
+	digests, err := LookupDigests(strings.ToUpper(imageDigest))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{imageDigest}, digests)
+
+	// a bare sha256 can be a checksum or a SHA-256 commit
+	digests, err = LookupDigests(checksum)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{checksum, "sha256:" + checksum}, digests)
+
+	digests, err = LookupDigests(commit)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{commit}, digests)
+
+	_, err = LookupDigests("sha256:abc")
+	assert.Error(t, err)
+
+	_, err = LookupDigests("latest")
+	assert.Error(t, err)
+}
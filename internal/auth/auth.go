@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+)
+
+const (
+	BEARER_SCHEME = "Bearer"
+)
+
+// IsValidKey is shared by every API surface (REST, GraphQL and gRPC)
+func IsValidKey(key string) bool {
+	expected := config.GetAuthConfig().Key
+	if key == "" || expected == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(key), []byte(expected)) == 1
+}
+
+// KeyID identifies a key where the key itself must not show up, e.g. in
+// metrics, by the start of its SHA-256
+func KeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:4])
+}
+
+// CertificateSubject is the subject of the client certificate verified by
+// the TLS handshake, e.g. CN=billing,O=Payments
+func CertificateSubject(request *http.Request) (string, bool) {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 {
+		return "", false
+	}
+
+	return request.TLS.VerifiedChains[0][0].Subject.String(), true
+}
+
+// IsValidSubject tells whether a verified certificate authenticates its
+// caller, any does unless auth.subjects lists the subjects allowed
+func IsValidSubject(subject string) bool {
+	allowed := config.GetAuthConfig().Subjects
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, candidate := range allowed {
+		if candidate == subject {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Caller identifies who made an authenticated request, by the subject of its
+// certificate or else by its key
+func Caller(request *http.Request) string {
+	if subject, ok := CertificateSubject(request); ok && IsValidSubject(subject) {
+		return subject
+	}
+
+	key := strings.TrimPrefix(request.Header.Get("Authorization"), BEARER_SCHEME+" ")
+	if key == "" {
+		return ""
+	}
+
+	return KeyID(key)
+}
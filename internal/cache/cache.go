@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// identifies this cache in the Cache-Status header
+	CACHE_STATUS_NAME = "serviceCatalog"
+)
+
+// Status describes how a lookup was served, it is reported to clients in the
+// Cache-Status header (RFC 9211).
+type Status struct {
+	Hit bool
+	// on a miss, whether the fetched value was stored
+	Stored bool
+	// on a hit, time left before the entry expires
+	TTL time.Duration
+}
+
+func (s Status) String() string {
+	if s.Hit {
+		return fmt.Sprintf("%s; hit; ttl=%d", CACHE_STATUS_NAME, int(s.TTL.Seconds()))
+	}
+
+	if s.Stored {
+		return fmt.Sprintf("%s; fwd=miss; stored", CACHE_STATUS_NAME)
+	}
+
+	return fmt.Sprintf("%s; fwd=miss", CACHE_STATUS_NAME)
+}
+
+type entry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+	// reports whether a change to the named service can change the value
+	dependsOn func(serviceName string) bool
+}
+
+// Cache is an LRU cache whose entries expire after a TTL and are dropped as
+// soon as a service they depend on changes.
+// Cached values are shared between readers and must not be modified.
+type Cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	// most recently used first
+	order *list.List
+	// bumped on every invalidation, so that a value fetched before a change
+	// is not stored after it
+	generation uint64
+}
+
+func New(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// Get returns the value stored under key, and the time left before it expires.
+// query labels the lookup in the hit and miss metrics.
+func (c *Cache) Get(query, key string) (interface{}, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		cacheMisses.WithLabelValues(query).Inc()
+		return nil, 0, false
+	}
+
+	cached := element.Value.(*entry)
+	ttl := time.Until(cached.expiresAt)
+	if ttl <= 0 {
+		c.remove(element)
+		cacheMisses.WithLabelValues(query).Inc()
+		return nil, 0, false
+	}
+
+	c.order.MoveToFront(element)
+	cacheHits.WithLabelValues(query).Inc()
+
+	return cached.value, ttl, true
+}
+
+// Generation must be read before fetching a value that is then passed to Set
+func (c *Cache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// Set stores value under key, unless an invalidation happened since generation
+// was read. It reports whether the value was stored.
+func (c *Cache) Set(generation uint64, key string, value interface{}, dependsOn func(serviceName string) bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return false
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{
+		key:       key,
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+		dependsOn: dependsOn,
+	})
+
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+		cacheEvictions.Inc()
+	}
+	cacheEntries.Set(float64(c.order.Len()))
+
+	return true
+}
+
+// Invalidate drops the entries depending on any of the named services
+func (c *Cache) Invalidate(serviceNames ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		cached := element.Value.(*entry)
+		for _, serviceName := range serviceNames {
+			if cached.dependsOn(serviceName) {
+				c.remove(element)
+				cacheInvalidations.Inc()
+				break
+			}
+		}
+		element = next
+	}
+	cacheEntries.Set(float64(c.order.Len()))
+}
+
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dependsOn(names ...string) func(string) bool {
+	return func(serviceName string) bool {
+		for _, name := range names {
+			if name == serviceName {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestCache_GetSet(t *testing.T) {
+	c := New(time.Minute, 10)
+
+	_, _, ok := c.Get("test", "a")
+	assert.False(t, ok)
+
+	assert.True(t, c.Set(c.Generation(), "a", 1, dependsOn("payments")))
+
+	value, ttl, ok := c.Get("test", "a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.InDelta(t, time.Minute.Seconds(), ttl.Seconds(), 1)
+}
+
+func TestCache_Expiry(t *testing.T) {
+	c := New(10*time.Millisecond, 10)
+	c.Set(c.Generation(), "a", 1, dependsOn("payments"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, _, ok := c.Get("test", "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(time.Minute, 2)
+	c.Set(c.Generation(), "a", 1, dependsOn())
+	c.Set(c.Generation(), "b", 2, dependsOn())
+
+	// a is now more recently used than b
+	c.Get("test", "a")
+	c.Set(c.Generation(), "c", 3, dependsOn())
+
+	assert.Equal(t, 2, c.Len())
+	_, _, ok := c.Get("test", "b")
+	assert.False(t, ok)
+	_, _, ok = c.Get("test", "a")
+	assert.True(t, ok)
+	_, _, ok = c.Get("test", "c")
+	assert.True(t, ok)
+}
+
+func TestCache_InvalidateOnlyDependentEntries(t *testing.T) {
+	c := New(time.Minute, 10)
+	c.Set(c.Generation(), "payments", 1, dependsOn("payments"))
+	c.Set(c.Generation(), "orders", 2, dependsOn("orders"))
+	c.Set(c.Generation(), "all", 3, func(string) bool { return true })
+
+	c.Invalidate("payments")
+
+	_, _, ok := c.Get("test", "payments")
+	assert.False(t, ok)
+	_, _, ok = c.Get("test", "all")
+	assert.False(t, ok)
+	_, _, ok = c.Get("test", "orders")
+	assert.True(t, ok)
+}
+
+func TestCache_SetAfterInvalidationIsDropped(t *testing.T) {
+	c := New(time.Minute, 10)
+
+	// the value was fetched before a change was committed
+	generation := c.Generation()
+	c.Invalidate("payments")
+
+	assert.False(t, c.Set(generation, "a", 1, dependsOn("payments")))
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestStatus_String(t *testing.T) {
+	assert.Equal(t, "serviceCatalog; hit; ttl=25", Status{Hit: true, TTL: 25 * time.Second}.String())
+	assert.Equal(t, "serviceCatalog; fwd=miss; stored", Status{Stored: true}.String())
+	assert.Equal(t, "serviceCatalog; fwd=miss", Status{}.String())
+}
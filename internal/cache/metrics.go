@@ -0,0 +1,46 @@
+package cache
+
+import (
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// registered with the default registry, which is what the metrics server exposes
+var (
+	cacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "cache",
+		Name:      "hits_total",
+		Help:      "Number of lookups served from the cache, per query.",
+	}, []string{"query"})
+
+	cacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "cache",
+		Name:      "misses_total",
+		Help:      "Number of lookups not found in the cache, per query.",
+	}, []string{"query"})
+
+	cacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "cache",
+		Name:      "evictions_total",
+		Help:      "Number of entries evicted to keep the cache within its size.",
+	})
+
+	cacheInvalidations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "cache",
+		Name:      "invalidations_total",
+		Help:      "Number of entries dropped because a service they depend on changed.",
+	})
+
+	cacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "cache",
+		Name:      "entries",
+		Help:      "Number of entries in the cache.",
+	})
+)
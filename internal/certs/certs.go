@@ -0,0 +1,182 @@
+// Package certs serves TLS from certificate files, reloading them when they
+// change on disk, e.g. when renewed or when a mounted secret is updated
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+)
+
+// Reloader holds the certificate and client CAs last loaded from the files
+type Reloader struct {
+	config *config.TLSConfig
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	// of the files when last loaded
+	stamps []stamp
+}
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the files once, failing when they are not usable
+func NewReloader(tlsConfig *config.TLSConfig) (*Reloader, error) {
+	if tlsConfig.CertFile == "" || tlsConfig.KeyFile == "" {
+		return nil, errors.New("TLS needs both a certificate and a key file")
+	}
+
+	if tlsConfig.RequireClientCert && tlsConfig.ClientCAFile == "" {
+		return nil, errors.New("client certificates can not be required without a client CA file")
+	}
+
+	reloader := &Reloader{config: tlsConfig}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// Reload reads the files again, keeping what was loaded before when they are
+// not usable, e.g. when the certificate is renewed before its key
+func (r *Reloader) Reload() error {
+	stamps, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		bundle, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("%s holds no PEM certificate", r.config.ClientCAFile)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.stamps = stamps
+
+	return nil
+}
+
+// Watch reloads the files whenever they change, until ctx is done
+func (r *Reloader) Watch(ctx context.Context) {
+	ticker := time.NewTicker(r.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+
+		// retried on the next tick, the files not being marked as loaded
+		if err := r.Reload(); err != nil {
+			slog.Error("error reloading the certificate, serving the previous one", "file", r.config.CertFile, "error", err)
+			continue
+		}
+		slog.Info("reloaded the certificate", "file", r.config.CertFile)
+	}
+}
+
+// TLSConfig serves the certificate last loaded, verifying client certificates
+// against the CAs last loaded when configured
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mutex.RLock()
+			defer r.mutex.RUnlock()
+
+			tlsConfig := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*r.certificate},
+			}
+
+			if r.clientCAs != nil {
+				tlsConfig.ClientCAs = r.clientCAs
+				tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+				if r.config.RequireClientCert {
+					tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+
+			return tlsConfig, nil
+		},
+	}
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+
+	return files
+}
+
+// stat follows symlinks, a mounted secret being updated by swapping them
+func (r *Reloader) stat() ([]stamp, error) {
+	var stamps []stamp
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, stamp{modTime: info.ModTime(), size: info.Size()})
+	}
+
+	return stamps, nil
+}
+
+func (r *Reloader) changed() bool {
+	stamps, err := r.stat()
+	if err != nil {
+		// being replaced, checked again on the next tick
+		return false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for i := range stamps {
+		if !stamps[i].modTime.Equal(r.stamps[i].modTime) || stamps[i].size != r.stamps[i].size {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,224 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type authority struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newAuthority(t *testing.T) authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return authority{certificate: certificate, key: key}
+}
+
+// issue returns the PEM certificate and key of a leaf signed by the authority
+func (a authority) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Payments"}},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.certificate, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (a authority) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: a.certificate.Raw})
+}
+
+func (a authority) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(a.certificate)
+	return pool
+}
+
+var writes int
+
+// writeFile moves the modification time forward, for changes within the
+// resolution of the filesystem to be seen
+func writeFile(t *testing.T, path string, data []byte) {
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	writes++
+	modTime := time.Now().Add(time.Duration(writes) * time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func writeServerFiles(t *testing.T, ca authority, commonName string) *config.TLSConfig {
+	dir := t.TempDir()
+	tlsConfig := &config.TLSConfig{
+		CertFile:       filepath.Join(dir, "tls.crt"),
+		KeyFile:        filepath.Join(dir, "tls.key"),
+		ClientCAFile:   filepath.Join(dir, "ca.crt"),
+		ReloadInterval: 10 * time.Millisecond,
+	}
+
+	certificate, key := ca.issue(t, commonName, x509.ExtKeyUsageServerAuth)
+	writeFile(t, tlsConfig.CertFile, certificate)
+	writeFile(t, tlsConfig.KeyFile, key)
+	writeFile(t, tlsConfig.ClientCAFile, ca.pem())
+
+	return tlsConfig
+}
+
+func servedCommonName(t *testing.T, reloader *Reloader) string {
+	tlsConfig, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestNewReloader_Invalid(t *testing.T) {
+	ca := newAuthority(t)
+	tlsConfig := writeServerFiles(t, ca, "localhost")
+
+	_, err := NewReloader(&config.TLSConfig{CertFile: tlsConfig.CertFile})
+	assert.Error(t, err)
+
+	_, err = NewReloader(&config.TLSConfig{CertFile: tlsConfig.CertFile, KeyFile: tlsConfig.KeyFile, RequireClientCert: true})
+	assert.Error(t, err)
+
+	_, err = NewReloader(&config.TLSConfig{CertFile: tlsConfig.CertFile, KeyFile: tlsConfig.ClientCAFile})
+	assert.Error(t, err, "the key does not match the certificate")
+}
+
+func TestReloader_VerifiesClientCertificates(t *testing.T) {
+	ca := newAuthority(t)
+	reloader, err := NewReloader(writeServerFiles(t, ca, "localhost"))
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		subject, _ := auth.CertificateSubject(request)
+		io.WriteString(writer, subject)
+	}))
+	server.TLS = reloader.TLSConfig()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	get := func(clientCertificates ...tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      ca.pool(),
+			Certificates: clientCertificates,
+		}}}
+		response, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		return string(body), err
+	}
+
+	certificate, key := ca.issue(t, "billing", x509.ExtKeyUsageClientAuth)
+	clientCertificate, err := tls.X509KeyPair(certificate, key)
+	require.NoError(t, err)
+
+	subject, err := get(clientCertificate)
+	require.NoError(t, err)
+	assert.Equal(t, "CN=billing,O=Payments", subject)
+
+	// clients without a certificate authenticate with a key
+	subject, err = get()
+	require.NoError(t, err)
+	assert.Empty(t, subject)
+
+	// certificates of other authorities are refused
+	other := newAuthority(t)
+	certificate, key = other.issue(t, "billing", x509.ExtKeyUsageClientAuth)
+	otherCertificate, err := tls.X509KeyPair(certificate, key)
+	require.NoError(t, err)
+
+	_, err = get(otherCertificate)
+	assert.Error(t, err)
+}
+
+func TestReloader_RequireClientCert(t *testing.T) {
+	ca := newAuthority(t)
+	tlsConfig := writeServerFiles(t, ca, "localhost")
+	tlsConfig.RequireClientCert = true
+
+	reloader, err := NewReloader(tlsConfig)
+	require.NoError(t, err)
+
+	served, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, served.ClientAuth)
+}
+
+func TestReloader_Watch(t *testing.T) {
+	ca := newAuthority(t)
+	tlsConfig := writeServerFiles(t, ca, "before")
+
+	reloader, err := NewReloader(tlsConfig)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go reloader.Watch(ctx)
+
+	// a certificate renewed before its key is not served
+	certificate, key := ca.issue(t, "after", x509.ExtKeyUsageServerAuth)
+	writeFile(t, tlsConfig.CertFile, certificate)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "before", servedCommonName(t, reloader))
+
+	writeFile(t, tlsConfig.KeyFile, key)
+	assert.Eventually(t, func() bool { return servedCommonName(t, reloader) == "after" }, time.Second, 10*time.Millisecond)
+}
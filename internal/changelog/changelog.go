@@ -0,0 +1,173 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+
+	"github.com/yuin/goldmark"
+	"golang.org/x/mod/semver"
+)
+
+// Formats the changelog is rendered in
+const (
+	FORMAT_JSON             = "json"
+	FORMAT_MARKDOWN         = "markdown"
+	FORMAT_HTML             = "html"
+	FORMAT_KEEP_A_CHANGELOG = "keep-a-changelog"
+
+	MARKDOWN_MIME = "text/markdown"
+
+	// longest entry of a section, in bytes
+	MAX_ENTRY_LENGTH = 4096
+
+	DATE_FORMAT = "2006-01-02"
+)
+
+var Formats = []string{
+	FORMAT_JSON,
+	FORMAT_MARKDOWN,
+	FORMAT_HTML,
+	FORMAT_KEEP_A_CHANGELOG,
+}
+
+type section struct {
+	title   string
+	entries []string
+}
+
+// sections lists the sections of the notes in the order they are rendered
+func sections(notes api.ReleaseNotes) []section {
+	return []section{
+		{"Added", notes.Added},
+		{"Changed", notes.Changed},
+		{"Fixed", notes.Fixed},
+		{"Security", notes.Security},
+	}
+}
+
+// Normalize validates release notes and returns them with their entries trimmed
+func Normalize(notes api.ReleaseNotes) (api.ReleaseNotes, error) {
+	normalized := map[string][]string{}
+
+	for _, section := range sections(notes) {
+		for i, entry := range section.entries {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				return api.ReleaseNotes{}, fmt.Errorf("entry %d of the %s release notes is empty", i+1, strings.ToLower(section.title))
+			}
+			if len(entry) > MAX_ENTRY_LENGTH {
+				return api.ReleaseNotes{}, fmt.Errorf("entry %d of the %s release notes is longer than %d bytes", i+1, strings.ToLower(section.title), MAX_ENTRY_LENGTH)
+			}
+			normalized[section.title] = append(normalized[section.title], entry)
+		}
+	}
+
+	return api.ReleaseNotes{
+		Added:    normalized["Added"],
+		Changed:  normalized["Changed"],
+		Fixed:    normalized["Fixed"],
+		Security: normalized["Security"],
+	}, nil
+}
+
+// IsValidFormat reports whether the changelog can be rendered in format
+func IsValidFormat(format string) bool {
+	for _, validFormat := range Formats {
+		if validFormat == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SortLatestFirst orders the versions of a service by semantic version when
+// all of them are one, v prefix optional, and by creation time otherwise
+func SortLatestFirst(versions []api.VersionReleaseNotes) {
+	bySemver := true
+	for _, version := range versions {
+		if !semver.IsValid(canonical(version.Name)) {
+			bySemver = false
+			break
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		if bySemver {
+			if order := semver.Compare(canonical(versions[i].Name), canonical(versions[j].Name)); order != 0 {
+				return order > 0
+			}
+		}
+		return versions[i].CreatedAt.After(versions[j].CreatedAt)
+	})
+}
+
+func canonical(versionName string) string {
+	if strings.HasPrefix(versionName, "v") {
+		return versionName
+	}
+	return "v" + versionName
+}
+
+// Markdown renders the changelog with the description of each version
+func Markdown(changelog api.ChangelogResponse) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "# Changelog of %s\n", changelog.ServiceName)
+	for _, version := range changelog.Versions {
+		fmt.Fprintf(&builder, "\n## %s (%s)\n", version.Name, version.CreatedAt.Format(DATE_FORMAT))
+		if version.Description != "" {
+			fmt.Fprintf(&builder, "\n%s\n", version.Description)
+		}
+		writeSections(&builder, version.ReleaseNotes)
+	}
+
+	return builder.String()
+}
+
+// KeepAChangelog renders the changelog as described by
+// https://keepachangelog.com/en/1.1.0/
+func KeepAChangelog(changelog api.ChangelogResponse) string {
+	var builder strings.Builder
+
+	builder.WriteString("# Changelog\n\n")
+	fmt.Fprintf(&builder, "All notable changes to %s are documented in this file.\n\n", changelog.ServiceName)
+	builder.WriteString("The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).\n")
+	for _, version := range changelog.Versions {
+		fmt.Fprintf(&builder, "\n## [%s] - %s\n", version.Name, version.CreatedAt.Format(DATE_FORMAT))
+		writeSections(&builder, version.ReleaseNotes)
+	}
+
+	return builder.String()
+}
+
+// HTML renders the Markdown changelog as a page. Raw HTML in the notes is
+// left out and so are dangerous links.
+func HTML(changelog api.ChangelogResponse) (string, error) {
+	var body bytes.Buffer
+	if err := goldmark.Convert([]byte(Markdown(changelog)), &body); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Changelog of %s</title>\n</head>\n<body>\n%s</body>\n</html>\n",
+		html.EscapeString(changelog.ServiceName), body.String()), nil
+}
+
+func writeSections(builder *strings.Builder, notes api.ReleaseNotes) {
+	for _, section := range sections(notes) {
+		if len(section.entries) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(builder, "\n### %s\n\n", section.title)
+		for _, entry := range section.entries {
+			// continuation lines are indented to stay in the list item
+			fmt.Fprintf(builder, "- %s\n", strings.ReplaceAll(entry, "\n", "\n  "))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func versionNames(versions []api.VersionReleaseNotes) []string {
+	names := []string{}
+	for _, version := range versions {
+		names = append(names, version.Name)
+	}
+	return names
+}
+
+func TestNormalize(t *testing.T) {
+	notes, err := Normalize(api.ReleaseNotes{
+		Added: []string{" Partial refunds \n"},
+		Fixed: []string{"Duplicate charges", "Rounding of `JPY` amounts"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, api.ReleaseNotes{
+		Added: []string{"Partial refunds"},
+		Fixed: []string{"Duplicate charges", "Rounding of `JPY` amounts"},
+	}, notes)
+
+	_, err = Normalize(api.ReleaseNotes{Security: []string{"CVE-2024-1234", " "}})
+	assert.EqualError(t, err, "entry 2 of the security release notes is empty")
+
+	_, err = Normalize(api.ReleaseNotes{Changed: []string{strings.Repeat("a", MAX_ENTRY_LENGTH+1)}})
+	assert.Error(t, err)
+}
+
+func TestSortLatestFirst(t *testing.T) {
+	now := time.Now()
+
+	// semantic versions, whatever the order they were created in
+	versions := []api.VersionReleaseNotes{
+		{Name: "1.10.0", CreatedAt: now.Add(-3 * time.Hour)},
+		{Name: "v1.2.0", CreatedAt: now.Add(-2 * time.Hour)},
+		{Name: "1.9.1", CreatedAt: now.Add(-time.Hour)},
+		{Name: "2.0.0-rc.1", CreatedAt: now},
+	}
+	SortLatestFirst(versions)
+	assert.Equal(t, []string{"2.0.0-rc.1", "1.10.0", "1.9.1", "v1.2.0"}, versionNames(versions))
+
+	// any other name falls back to creation time
+	versions = []api.VersionReleaseNotes{
+		{Name: "1.0.0", CreatedAt: now.Add(-2 * time.Hour)},
+		{Name: "spring-release", CreatedAt: now},
+		{Name: "0.9.0", CreatedAt: now.Add(-time.Hour)},
+	}
+	SortLatestFirst(versions)
+	assert.Equal(t, []string{"spring-release", "0.9.0", "1.0.0"}, versionNames(versions))
+}
+
+var testChangelog = api.ChangelogResponse{
+	ServiceName: "payments",
+	Versions: []api.VersionReleaseNotes{
+		{
+			Name:        "1.5.0",
+			Description: "Partial refunds",
+			CreatedAt:   time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+			ReleaseNotes: api.ReleaseNotes{
+				Added:    []string{"Refunds can be partial,\nsee the `amount` field"},
+				Security: []string{"Upgraded TLS library"},
+			},
+		},
+		{
+			Name:         "1.4.0",
+			CreatedAt:    time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			ReleaseNotes: api.ReleaseNotes{Fixed: []string{"Duplicate charges <script>alert(1)</script>"}},
+		},
+	},
+}
+
+func TestMarkdown(t *testing.T) {
+	assert.Equal(t, `# Changelog of payments
+
+## 1.5.0 (2024-03-02)
+
+Partial refunds
+
+### Added
+
+- Refunds can be partial,
+  see the `+"`amount`"+` field
+
+### Security
+
+- Upgraded TLS library
+
+## 1.4.0 (2024-01-15)
+
+### Fixed
+
+- Duplicate charges <script>alert(1)</script>
+`, Markdown(testChangelog))
+}
+
+func TestKeepAChangelog(t *testing.T) {
+	rendered := KeepAChangelog(testChangelog)
+
+	assert.True(t, strings.HasPrefix(rendered, "# Changelog\n\nAll notable changes to payments are documented in this file.\n"))
+	assert.Contains(t, rendered, "\n## [1.5.0] - 2024-03-02\n\n### Added\n\n- Refunds can be partial,\n")
+	assert.Contains(t, rendered, "\n## [1.4.0] - 2024-01-15\n\n### Fixed\n")
+	// descriptions are not part of the format
+	assert.NotContains(t, rendered, "Partial refunds")
+}
+
+func TestHTML(t *testing.T) {
+	page, err := HTML(api.ChangelogResponse{ServiceName: "<payments>", Versions: testChangelog.Versions})
+
+	assert.NoError(t, err)
+	assert.Contains(t, page, "<title>Changelog of &lt;payments&gt;</title>")
+	assert.Contains(t, page, "<h2>1.5.0 (2024-03-02)</h2>")
+	assert.Contains(t, page, "<code>amount</code>")
+	// raw HTML in the notes is not rendered
+	assert.NotContains(t, page, "<script>")
+}
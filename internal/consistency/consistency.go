@@ -0,0 +1,102 @@
+// Package consistency routes the reads of the REST API to a replica, unless
+// they have to see the latest writes: when asked with X-Consistency: strong,
+// or when their caller wrote recently
+package consistency
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/auth"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var (
+	writesMutex sync.Mutex
+	// when each caller last wrote
+	lastWrites = map[string]time.Time{}
+)
+
+// now is replaced by tests
+var now = time.Now
+
+// Middleware remembers when the caller of a successful mutation wrote, for
+// its reads to go to the primary for a while. It runs after auth, which
+// identifies the caller.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		err := next(ctx)
+
+		if err == nil && isWrite(ctx.Request().Method) && ctx.Response().Status < http.StatusBadRequest {
+			recordWrite(auth.Caller(ctx.Request()))
+		}
+
+		return err
+	}
+}
+
+// ReadDB is the database the reads of a request go to: the primary when they
+// have to see the latest writes, else a healthy replica if any
+func ReadDB(ctx echo.Context) (*gorm.DB, error) {
+	if isStrong(ctx.Request()) {
+		return db.GetDBContext(ctx.Request().Context())
+	}
+
+	database, err := db.GetReplicaDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return database.WithContext(ctx.Request().Context()), nil
+}
+
+func isStrong(request *http.Request) bool {
+	if strings.EqualFold(request.Header.Get(constants.CONSISTENCY_HEADER), constants.CONSISTENCY_STRONG) {
+		return true
+	}
+
+	return wroteRecently(auth.Caller(request))
+}
+
+func isWrite(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
+
+func recordWrite(caller string) {
+	if caller == "" {
+		return
+	}
+
+	writesMutex.Lock()
+	defer writesMutex.Unlock()
+
+	// the writes out of the window are forgotten, for the map to stay as
+	// small as the callers writing
+	window := config.GetDBConfig().ReadYourWritesWindow
+	for other, at := range lastWrites {
+		if now().Sub(at) >= window {
+			delete(lastWrites, other)
+		}
+	}
+
+	lastWrites[caller] = now()
+}
+
+func wroteRecently(caller string) bool {
+	if caller == "" {
+		return false
+	}
+
+	writesMutex.Lock()
+	defer writesMutex.Unlock()
+
+	at, ok := lastWrites[caller]
+	return ok && now().Sub(at) < config.GetDBConfig().ReadYourWritesWindow
+}
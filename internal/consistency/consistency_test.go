@@ -0,0 +1,84 @@
+package consistency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func mockDB(t *testing.T) *gorm.DB {
+	sqlDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	database, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	return database
+}
+
+// readDB is the session of the database a read by the caller with key goes
+// to
+func readDB(t *testing.T, key string, header http.Header) *gorm.DB {
+	request := httptest.NewRequest(http.MethodGet, "/v1/services", nil)
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Authorization", "Bearer "+key)
+
+	database, err := ReadDB(echo.New().NewContext(request, httptest.NewRecorder()))
+	require.NoError(t, err)
+
+	return database
+}
+
+// write runs a mutation by the caller with key through the middleware
+func write(t *testing.T, key string, status int) {
+	request := httptest.NewRequest(http.MethodPost, "/v1/services", nil)
+	request.Header.Set("Authorization", "Bearer "+key)
+
+	err := Middleware(func(ctx echo.Context) error {
+		return ctx.NoContent(status)
+	})(echo.New().NewContext(request, httptest.NewRecorder()))
+	require.NoError(t, err)
+}
+
+func TestReadDB(t *testing.T) {
+	primary, replica := mockDB(t), mockDB(t)
+
+	previous := db.DB
+	db.DB = primary
+	db.SetReplicas(replica)
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+
+	t.Cleanup(func() {
+		db.DB = previous
+		db.SetReplicas()
+		now = time.Now
+	})
+
+	assert.Equal(t, replica.ConnPool, readDB(t, "writer", nil).ConnPool)
+	assert.Equal(t, primary.ConnPool, readDB(t, "writer", http.Header{constants.CONSISTENCY_HEADER: {constants.CONSISTENCY_STRONG}}).ConnPool)
+
+	write(t, "writer", http.StatusBadRequest)
+	assert.Equal(t, replica.ConnPool, readDB(t, "writer", nil).ConnPool, "failed writes change nothing")
+
+	write(t, "writer", http.StatusCreated)
+	assert.Equal(t, primary.ConnPool, readDB(t, "writer", nil).ConnPool, "the writer reads its writes")
+	assert.Equal(t, replica.ConnPool, readDB(t, "reader", nil).ConnPool, "the others may not")
+
+	clock = clock.Add(config.GetDBConfig().ReadYourWritesWindow)
+	assert.Equal(t, replica.ConnPool, readDB(t, "writer", nil).ConnPool, "once replicated")
+}
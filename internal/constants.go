@@ -1,31 +1,93 @@
 package internal
 
+// PAGE_SIZE is set from the configuration at startup, before serving
+var PAGE_SIZE = 2
+
 const (
+	// prefix of all the metrics exposed by the service
+	METRICS_NAMESPACE = "serviceCatalog"
+
 	// Service related constants
-	PAGE_SIZE = 2
-	ASC       = "ASC"
-	DESC      = "DESC"
+	ASC  = "ASC"
+	DESC = "DESC"
+
+	// reports whether a read was served from the cache (RFC 9211)
+	CACHE_STATUS_HEADER = "Cache-Status"
+
+	// tells that a route, or the name a service was requested by, is deprecated (RFC 9745)
+	DEPRECATION_HEADER = "Deprecation"
+	// when a deprecated route stops being served (RFC 8594)
+	SUNSET_HEADER = "Sunset"
+
+	// reads sent with it set to strong see the latest writes, going to the
+	// primary rather than a replica
+	CONSISTENCY_HEADER = "X-Consistency"
+	CONSISTENCY_STRONG = "strong"
+
+	// ID of the trace of a request, for it to be looked up from a response
+	TRACE_ID_HEADER = "X-Trace-Id"
+
+	// Event types pushed on the change stream
+	EVENT_SERVICE_CREATED  = "service.created"
+	EVENT_SERVICE_UPDATED  = "service.updated"
+	EVENT_SERVICE_DELETED  = "service.deleted"
+	EVENT_VERSION_CREATED  = "version.created"
+	EVENT_VERSION_UPDATED  = "version.updated"
+	EVENT_VERSION_DELETED  = "version.deleted"
+	EVENT_VERSION_DEPLOYED = "version.deployed"
+	EVENT_CHANNEL_MOVED    = "channel.moved"
+	EVENT_CHANNEL_DELETED  = "channel.deleted"
 
 	// 200
 	SUCCESS                 = "Success"
+	SERVICE_UPDATED         = "Service Updated Successfully"
 	SERVICE_DELETED         = "Service Deleted Successfully"
 	SERVICE_VERSION_DELETED = "Service Version Deleted Successfully"
+	CHANNEL_MOVED           = "Channel Moved Successfully"
+	CHANNEL_DELETED         = "Channel Deleted Successfully"
 
 	// 201
 	SERVICE_CREATED         = "Service Created Successfully"
 	SERVICE_VERSION_CREATED = "Version Created Successfully"
+	ENVIRONMENT_CREATED     = "Environment Created Successfully"
+	VERSION_DEPLOYED        = "Deployment Recorded Successfully"
+	CHANNEL_CREATED         = "Channel Created Successfully"
 
-	// 202
-	ACCEPTED = "Accepted"
+	// 3xx
+	SERVICE_RENAMED = "service was renamed"
 
 	// 4xx
 	INVALID_REQUEST_BODY           = "invalid request body"
 	INVALID_PAGE_NUMBER            = "invalid page number"
+	INVALID_REVISION               = "invalid revision"
+	INVALID_LAST_EVENT_ID          = "invalid Last-Event-ID"
+	UNKNOWN_LAST_EVENT_ID          = "Last-Event-ID does not match any event"
+	INVALID_EVENT_TYPE             = "invalid event type"
+	INVALID_VERSION_RANGE          = "from must not be a later version than to"
+	INVALID_CHANGELOG_FORMAT       = "invalid format, expected one of json, markdown, html, keep-a-changelog"
+	INVALID_ENVIRONMENT_NAME       = "environment names are lowercase letters, digits and dashes, up to 32 characters"
+	INVALID_CHANNEL_NAME           = "channel names start with a lowercase letter followed by lowercase letters, digits and dashes, up to 32 characters"
+	MISSING_DEPLOYMENT_ACTOR       = "actor is required"
+	MISSING_NAME                   = "name is required"
+	NAME_DOES_NOT_MATCH_PATH       = "name in the body does not match the one in the path"
+	MISSING_GRAPHQL_QUERY          = "query is required"
 	SERVICE_RECORD_NOT_FOUND       = "service not found"
 	VERSION_RECORD_NOT_FOUND       = "version not found"
+	REVISION_RECORD_NOT_FOUND      = "revision not found"
+	ARTIFACT_RECORD_NOT_FOUND      = "no version ships an artifact with this digest"
+	ENVIRONMENT_RECORD_NOT_FOUND   = "environment not found"
+	CHANNEL_RECORD_NOT_FOUND       = "channel not found"
 	DUPLICATE_VERSION_RECORD_ERROR = "version with the same name already exists for this service"
+	DUPLICATE_ENVIRONMENT_ERROR    = "environment with the same name already exists"
+	DUPLICATE_SERVICE_NAME_ERROR   = "a service with the same name, current or previous, already exists"
+	DUPLICATE_CHANNEL_ERROR        = "channel was created concurrently, retry to move it"
+	VERSION_CURRENTLY_DEPLOYED     = "version is currently deployed, delete it with force=true"
+	VERSION_POINTED_TO_BY_CHANNEL  = "a channel points to the version, move or delete the channel first"
+	ROUTE_SUNSET                   = "this route is no longer served"
 
 	//5xx
+	SUBSCRIBER_TOO_SLOW    = "subscriber fell too far behind, resume from the last event received"
+	SHUTTING_DOWN          = "server is shutting down, resume from the last event received"
 	INTERNAL_SERVER_ERROR  = "internal server error"
 	ERROR_FETCHING_SERVICE = "error fetching service"
 )
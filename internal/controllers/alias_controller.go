@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+// ServiceRenamedError is returned when a service is looked up by one of its
+// previous names
+type ServiceRenamedError struct {
+	// current name of the service
+	Name string
+	// when the name looked up stopped being the current one
+	RenamedAt time.Time
+}
+
+func (e *ServiceRenamedError) Error() string {
+	return constants.SERVICE_RENAMED
+}
+
+// FollowRename calls fn with the service name and, when the service was
+// renamed since, once more with its current name
+func FollowRename(serviceName string, fn func(serviceName string) error) error {
+	err := fn(serviceName)
+
+	var renamed *ServiceRenamedError
+	if errors.As(err, &renamed) {
+		return fn(renamed.Name)
+	}
+
+	return err
+}
+
+// GetServiceAliases lists the previous names of a service, latest first
+func GetServiceAliases(db *gorm.DB, serviceName string) (*api.ServiceAliasListResponse, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var aliases []models.ServiceAlias
+	if err := db.Where("service_id = ?", service.ID).Order("id DESC").Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+
+	response := api.ServiceAliasListResponse{
+		ServiceName: service.Name,
+		Aliases:     []api.ServiceAliasResponse{},
+	}
+	for _, alias := range aliases {
+		response.Aliases = append(response.Aliases, api.ServiceAliasResponse{
+			Name:      alias.Name,
+			RenamedAt: alias.CreatedAt,
+		})
+	}
+
+	return &response, nil
+}
+
+// renamedService tells which service used to be named name, if any
+func renamedService(db *gorm.DB, name string) error {
+	var renamed struct {
+		Name      string
+		RenamedAt time.Time
+	}
+
+	result := db.Model(&models.ServiceAlias{}).
+		Select("services.name, service_aliases.created_at AS renamed_at").
+		Joins("JOIN services ON services.id = service_aliases.service_id AND services.deleted_at IS NULL").
+		Where("service_aliases.name = ?", name).
+		Limit(1).
+		Scan(&renamed)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New(constants.SERVICE_RECORD_NOT_FOUND)
+	}
+
+	return &ServiceRenamedError{Name: renamed.Name, RenamedAt: renamed.RenamedAt}
+}
+
+// recordRename keeps the previous name of a renamed service as an alias. A
+// service renamed back to one of its previous names drops that alias.
+func recordRename(tx *gorm.DB, service models.Service, previousName string) error {
+	if err := tx.Where("service_id = ? AND name = ?", service.ID, service.Name).Delete(&models.ServiceAlias{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.ServiceAlias{
+		ServiceID: service.ID,
+		Name:      previousName,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// claimServiceName makes sure that no other service is currently, or was
+// previously, named name. serviceID is 0 for a service being created.
+func claimServiceName(tx *gorm.DB, name string, serviceID uint) error {
+	var taken int64
+	if err := tx.Model(&models.Service{}).
+		Where("id <> ?", serviceID).
+		Where("name = ? OR id IN (?)", name, tx.Model(&models.ServiceAlias{}).Select("service_id").Where("name = ?", name)).
+		Count(&taken).Error; err != nil {
+		return err
+	}
+
+	if taken > 0 {
+		return errors.New(constants.DUPLICATE_SERVICE_NAME_ERROR)
+	}
+
+	return nil
+}
+
+// isDuplicateServiceName tells whether err is a violation of the uniqueness
+// of service names, by a concurrent request
+func isDuplicateServiceName(err error) bool {
+	return strings.Contains(err.Error(), "duplicate key value violates unique constraint")
+}
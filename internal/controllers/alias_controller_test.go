@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	serviceNameClaimQuery = `SELECT count(*) FROM "services" WHERE id <> $1 AND (name = $2 OR id IN (SELECT "service_id" FROM "service_aliases" WHERE name = $3)) AND "services"."deleted_at" IS NULL`
+	renamedServiceQuery   = `SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases" JOIN services ON services.id = service_aliases.service_id AND services.deleted_at IS NULL WHERE service_aliases.name = $1 LIMIT $2`
+)
+
+func expectServiceNameClaimed(name string, serviceID int) {
+	mock.ExpectQuery(regexp.QuoteMeta(serviceNameClaimQuery)).
+		WithArgs(serviceID, name, name).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+}
+
+func expectServiceNotRenamed(name string) {
+	mock.ExpectQuery(regexp.QuoteMeta(renamedServiceQuery)).
+		WithArgs(name, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}))
+}
+
+func expectAliasesDeleted(serviceID int) {
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "service_aliases" WHERE service_id = $1`)).
+		WithArgs(serviceID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectRenameRecorded(serviceID int, name, previousName string) {
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "service_aliases" WHERE service_id = $1 AND name = $2`)).
+		WithArgs(serviceID, name).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "service_aliases" ("service_id","name","created_at") VALUES ($1,$2,$3) RETURNING "id"`)).
+		WithArgs(serviceID, previousName, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+func TestGetServiceAliases_ByPreviousName(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	renamedAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(renamedServiceQuery)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}).AddRow("test-service-2", renamedAt))
+
+	_, err := GetServiceAliases(gormMockDB, "test-service")
+
+	var renamed *ServiceRenamedError
+	if assert.True(t, errors.As(err, &renamed)) {
+		assert.Equal(t, "test-service-2", renamed.Name)
+		assert.Equal(t, renamedAt, renamed.RenamedAt)
+	}
+	assert.EqualError(t, err, constants.SERVICE_RENAMED)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateService_NameTaken(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	// another service was named test-service before being renamed
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(serviceNameClaimQuery)).
+		WithArgs(0, "test-service", "test-service").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := CreateService(gormMockDB, api.ServiceRequest{Name: "test-service", Description: "Test service"})
+
+	assert.EqualError(t, err, constants.DUPLICATE_SERVICE_NAME_ERROR)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFollowRename(t *testing.T) {
+	var names []string
+	err := FollowRename("test-service", func(serviceName string) error {
+		names = append(names, serviceName)
+		if serviceName == "test-service" {
+			return &ServiceRenamedError{Name: "test-service-2"}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test-service", "test-service-2"}, names)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+// GetVersionWithArtifacts looks the version up by its name, or by the name of
+// a channel pointing to it
+func GetVersionWithArtifacts(db *gorm.DB, serviceName, versionName string) (*models.Version, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := findVersion(db, service.ID, versionName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("version_id = ?", version.ID).Order("id ASC").Find(&version.Artifacts).Error; err != nil {
+		return nil, err
+	}
+
+	version.Service = service
+
+	return version, nil
+}
+
+// FindArtifactsByDigest lists the versions, of services still in the catalog,
+// which shipped an artifact with any of the given digests, oldest first
+func FindArtifactsByDigest(db *gorm.DB, digests []string) ([]api.ArtifactMatch, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	var matches []api.ArtifactMatch
+	if err := db.Model(&models.Artifact{}).
+		Select("services.name AS service_name, versions.name AS version_name, artifacts.type, artifacts.reference, artifacts.digest, artifacts.created_at").
+		Joins("JOIN versions ON versions.id = artifacts.version_id AND versions.deleted_at IS NULL").
+		Joins("JOIN services ON services.id = versions.service_id AND services.deleted_at IS NULL").
+		Where("artifacts.digest IN ?", digests).
+		Order("artifacts.id ASC").
+		Scan(&matches).Error; err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		return nil, errors.New(constants.ARTIFACT_RECORD_NOT_FOUND)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+const findArtifactsQuery = `SELECT services.name AS service_name, versions.name AS version_name, artifacts.type, artifacts.reference, artifacts.digest, artifacts.created_at FROM "artifacts" JOIN versions ON versions.id = artifacts.version_id AND versions.deleted_at IS NULL JOIN services ON services.id = versions.service_id AND services.deleted_at IS NULL WHERE artifacts.digest IN ($1,$2) ORDER BY artifacts.id ASC`
+
+func TestFindArtifactsByDigest_Success(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	createdAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(findArtifactsQuery)).
+		WithArgs("abc", "sha256:abc").
+		WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "type", "reference", "digest", "created_at"}).
+			AddRow("payments", "v1", api.ARTIFACT_BINARY, "https://releases.example.com/payments", "sha256:abc", createdAt))
+
+	matches, err := FindArtifactsByDigest(gormMockDB, []string{"abc", "sha256:abc"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []api.ArtifactMatch{{
+		ServiceName: "payments",
+		VersionName: "v1",
+		Type:        api.ARTIFACT_BINARY,
+		Reference:   "https://releases.example.com/payments",
+		Digest:      "sha256:abc",
+		CreatedAt:   createdAt,
+	}}, matches)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindArtifactsByDigest_NotFound(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(findArtifactsQuery)).
+		WithArgs("abc", "sha256:abc").
+		WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "type", "reference", "digest", "created_at"}))
+
+	_, err := FindArtifactsByDigest(gormMockDB, []string{"abc", "sha256:abc"})
+
+	assert.EqualError(t, err, constants.ARTIFACT_RECORD_NOT_FOUND)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
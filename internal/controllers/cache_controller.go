@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/cache"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+const (
+	// query labels of the cache metrics
+	CACHE_QUERY_SERVICES = "services"
+	CACHE_QUERY_SERVICE  = "service"
+)
+
+// nil unless EnableCache is called, in which case reads are not cached
+var responseCache *cache.Cache
+
+// EnableCache puts c in front of the service lookups. Passing nil disables it.
+func EnableCache(c *cache.Cache) {
+	responseCache = c
+}
+
+type servicesPage struct {
+	totalServices int64
+	services      []models.Service
+}
+
+type serviceWithVersionsPage struct {
+	totalVersions int64
+	service       *models.Service
+}
+
+// CachedPaginatedServicesByFilters is GetPaginatedServicesByFilters behind the
+// cache. The returned status is nil when the cache is disabled.
+func CachedPaginatedServicesByFilters(db *gorm.DB, page int, sort, nameFilter, descriptionFilter string, attributeFilters map[string]string) (int64, []models.Service, *cache.Status, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	c := responseCache
+	if c == nil {
+		totalServices, services, err := GetPaginatedServicesByFilters(db, page, sort, nameFilter, descriptionFilter, attributeFilters)
+		return totalServices, services, nil, err
+	}
+
+	query := url.Values{
+		"page":        {strconv.Itoa(page)},
+		"sort":        {strings.ToUpper(sort)},
+		"name":        {nameFilter},
+		"description": {descriptionFilter},
+	}
+	for path, value := range attributeFilters {
+		query.Set(metadata.ATTRIBUTE_FILTER_PREFIX+path+metadata.ATTRIBUTE_FILTER_SUFFIX, value)
+	}
+	key := CACHE_QUERY_SERVICES + "?" + query.Encode()
+
+	if value, ttl, ok := c.Get(CACHE_QUERY_SERVICES, key); ok {
+		cached := value.(servicesPage)
+		return cached.totalServices, cached.services, &cache.Status{Hit: true, TTL: ttl}, nil
+	}
+
+	generation := c.Generation()
+	totalServices, services, err := GetPaginatedServicesByFilters(db, page, sort, nameFilter, descriptionFilter, attributeFilters)
+	if err != nil {
+		return totalServices, services, &cache.Status{}, err
+	}
+
+	// any service matching the name filter can move in or out of the page, or
+	// change the total
+	stored := !fromReplica(db) && c.Set(generation, key, servicesPage{totalServices, services}, func(serviceName string) bool {
+		return matchesNameFilter(serviceName, nameFilter)
+	})
+
+	return totalServices, services, &cache.Status{Stored: stored}, nil
+}
+
+// CachedServiceByNameWithPaginatedVersions is GetServiceByNameWithPaginatedVersions
+// behind the cache. The returned status is nil when the cache is disabled.
+func CachedServiceByNameWithPaginatedVersions(db *gorm.DB, page int, serviceName string) (int64, *models.Service, *cache.Status, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	c := responseCache
+	if c == nil {
+		totalVersions, service, err := GetServiceByNameWithPaginatedVersions(db, page, serviceName)
+		return totalVersions, service, nil, err
+	}
+
+	key := CACHE_QUERY_SERVICE + "?" + url.Values{
+		"page": {strconv.Itoa(page)},
+		"name": {serviceName},
+	}.Encode()
+
+	if value, ttl, ok := c.Get(CACHE_QUERY_SERVICE, key); ok {
+		cached := value.(serviceWithVersionsPage)
+		return cached.totalVersions, cached.service, &cache.Status{Hit: true, TTL: ttl}, nil
+	}
+
+	generation := c.Generation()
+	totalVersions, service, err := GetServiceByNameWithPaginatedVersions(db, page, serviceName)
+	if err != nil {
+		return totalVersions, service, &cache.Status{}, err
+	}
+
+	stored := !fromReplica(db) && c.Set(generation, key, serviceWithVersionsPage{totalVersions, service}, func(changedName string) bool {
+		return changedName == serviceName
+	})
+
+	return totalVersions, service, &cache.Status{Stored: stored}, nil
+}
+
+// fromReplica tells whether reads from tx may miss writes already committed,
+// the cache then not storing them: the invalidations of those writes are past
+// and would not drop them
+func fromReplica(tx *gorm.DB) bool {
+	return db.IsReplica(tx)
+}
+
+// matchesNameFilter mirrors the LIKE filter of GetPaginatedServicesByFilters.
+// Filters holding LIKE wildcards are assumed to match.
+func matchesNameFilter(serviceName, nameFilter string) bool {
+	if strings.ContainsAny(nameFilter, "%_") {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(serviceName), nameFilter)
+}
+
+// invalidateCache drops the cached reads affected by a committed mutation. A
+// renamed service affects the reads of both its previous and new names.
+func invalidateCache(event models.Event) {
+	c := responseCache
+	if c == nil {
+		return
+	}
+
+	serviceNames := []string{event.ServiceName}
+
+	if event.Type == constants.EVENT_SERVICE_UPDATED {
+		var data api.ServiceEventData
+		if err := json.Unmarshal([]byte(event.Data), &data); err == nil && data.PreviousName != "" {
+			serviceNames = append(serviceNames, data.PreviousName)
+		}
+	}
+
+	c.Invalidate(serviceNames...)
+}
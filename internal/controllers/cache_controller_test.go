@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/cache"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func expectServiceLookup(serviceName string, id int) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions" JOIN services ON versions.service_id = services.id WHERE services.name = $1 AND "versions"."deleted_at" IS NULL`)).
+		WithArgs(serviceName).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs(serviceName, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow(id, serviceName, "Test service", 0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE "versions"."service_id" = $1 AND "versions"."deleted_at" IS NULL LIMIT $2`)).
+		WithArgs(int64(id), constants.PAGE_SIZE).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description"}))
+}
+
+func TestCachedServiceByNameWithPaginatedVersions_HitAfterMiss(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	EnableCache(cache.New(time.Minute, 10))
+	defer EnableCache(nil)
+
+	// only the first lookup reaches the DB
+	expectServiceLookup("test-service", 123)
+
+	_, service, status, err := CachedServiceByNameWithPaginatedVersions(gormMockDB, 1, "test-service")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-service", service.Name)
+	assert.False(t, status.Hit)
+	assert.True(t, status.Stored)
+
+	_, service, status, err = CachedServiceByNameWithPaginatedVersions(gormMockDB, 1, "test-service")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-service", service.Name)
+	assert.True(t, status.Hit)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedServiceByNameWithPaginatedVersions_ReplicaReadsNotStored(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	EnableCache(cache.New(time.Minute, 10))
+	defer EnableCache(nil)
+
+	// the mock stands for a replica, which may lag behind the invalidations
+	db.SetReplicas(gormMockDB)
+	defer db.SetReplicas()
+
+	expectServiceLookup("test-service", 123)
+	expectServiceLookup("test-service", 123)
+
+	for i := 0; i < 2; i++ {
+		_, service, status, err := CachedServiceByNameWithPaginatedVersions(gormMockDB, 1, "test-service")
+		assert.NoError(t, err)
+		assert.Equal(t, "test-service", service.Name)
+		assert.False(t, status.Hit)
+		assert.False(t, status.Stored)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedPaginatedServicesByFilters_InvalidatedByMatchingMutation(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	EnableCache(cache.New(time.Minute, 10))
+	defer EnableCache(nil)
+
+	expectServicesPage := func() {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE LOWER(name) LIKE $1 AND "services"."deleted_at" IS NULL`)).
+			WithArgs("%pay%").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).
+				AddRow(1))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE LOWER(name) LIKE $1 AND "services"."deleted_at" IS NULL ORDER BY name ASC LIMIT $2`)).
+			WithArgs("%pay%", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+				AddRow(123, "payments", "Payments", 0))
+	}
+
+	expectServicesPage()
+	_, _, status, err := CachedPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "pay", "", nil)
+	assert.NoError(t, err)
+	assert.True(t, status.Stored)
+
+	// the sort order is normalized in the key
+	_, _, status, err = CachedPaginatedServicesByFilters(gormMockDB, 1, "asc", "pay", "", nil)
+	assert.NoError(t, err)
+	assert.True(t, status.Hit)
+
+	// a service outside of the name filter is created, the page is still valid
+	mock.ExpectBegin()
+	expectServiceNameClaimed("orders", 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services" ("name","description","created_at","updated_at","version_count","revision","links","contacts","attributes") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "deleted_at","id"`)).
+		WithArgs("orders", "Orders", sqlmock.AnyArg(), sqlmock.AnyArg(), 0, 1, "[]", "[]", "{}").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(456))
+	expectRevisionRecorded(456, 1)
+	expectEventRecorded("service.created", "orders", "")
+	mock.ExpectCommit()
+
+	assert.NoError(t, CreateService(gormMockDB, api.ServiceRequest{Name: "orders", Description: "Orders"}))
+
+	_, _, status, err = CachedPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "pay", "", nil)
+	assert.NoError(t, err)
+	assert.True(t, status.Hit)
+
+	// a matching service is created, the page is fetched again
+	mock.ExpectBegin()
+	expectServiceNameClaimed("payouts", 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services" ("name","description","created_at","updated_at","version_count","revision","links","contacts","attributes") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "deleted_at","id"`)).
+		WithArgs("payouts", "Payouts", sqlmock.AnyArg(), sqlmock.AnyArg(), 0, 1, "[]", "[]", "{}").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(789))
+	expectRevisionRecorded(789, 1)
+	expectEventRecorded("service.created", "payouts", "")
+	mock.ExpectCommit()
+
+	assert.NoError(t, CreateService(gormMockDB, api.ServiceRequest{Name: "payouts", Description: "Payouts"}))
+
+	expectServicesPage()
+	_, _, status, err = CachedPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "pay", "", nil)
+	assert.NoError(t, err)
+	assert.False(t, status.Hit)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedServiceByNameWithPaginatedVersions_InvalidatedByRename(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	EnableCache(cache.New(time.Minute, 10))
+	defer EnableCache(nil)
+
+	expectServiceLookup("test-service", 123)
+	_, _, _, err := CachedServiceByNameWithPaginatedVersions(gormMockDB, 1, "test-service")
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE id = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs(123, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow("123", "test-service", "Test service", 0))
+	expectServiceNameClaimed("test-service-2", 123)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"updated_at"=$4,"deleted_at"=$5,"version_count"=$6,"revision"=$7,"links"=$8,"contacts"=$9,"attributes"=$10 WHERE "services"."deleted_at" IS NULL AND "id" = $11`)).
+		WithArgs("test-service-2", "Test service", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 0, 1, "", "", "", 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRenameRecorded(123, "test-service-2", "test-service")
+	expectRevisionRecorded(123, 1)
+	expectEventRecorded("service.updated", "test-service-2", "")
+	mock.ExpectCommit()
+
+	assert.NoError(t, UpdateService(gormMockDB, api.ServiceRequest{ID: 123, Name: "test-service-2"}))
+
+	// the previous name is not served from the cache anymore
+	expectServiceLookup("test-service", 123)
+	_, _, status, err := CachedServiceByNameWithPaginatedVersions(gormMockDB, 1, "test-service")
+	assert.NoError(t, err)
+	assert.False(t, status.Hit)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
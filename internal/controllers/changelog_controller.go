@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/changelog"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+// ReleaseNotes decodes the release notes stored with a version
+func ReleaseNotes(version models.Version) (api.ReleaseNotes, error) {
+	var releaseNotes api.ReleaseNotes
+	if version.ReleaseNotes == "" {
+		return releaseNotes, nil
+	}
+
+	if err := json.Unmarshal([]byte(version.ReleaseNotes), &releaseNotes); err != nil {
+		return releaseNotes, err
+	}
+
+	return releaseNotes, nil
+}
+
+// GetChangelog returns the release notes of the versions of a service from
+// one version to another, both included and latest first. Either end of the
+// range defaults to the oldest, or latest, version, and may name a channel.
+func GetChangelog(db *gorm.DB, serviceName, from, to string) (*api.ChangelogResponse, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []models.Version
+	if err := db.Where("service_id = ?", service.ID).Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	notes := make([]api.VersionReleaseNotes, 0, len(versions))
+	for _, version := range versions {
+		releaseNotes, err := ReleaseNotes(version)
+		if err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, api.VersionReleaseNotes{
+			Name:         version.Name,
+			Description:  version.Description,
+			CreatedAt:    version.CreatedAt,
+			ReleaseNotes: releaseNotes,
+		})
+	}
+	changelog.SortLatestFirst(notes)
+
+	toIndex, fromIndex := 0, len(notes)-1
+	if to != "" {
+		if toIndex, err = rangeBound(db, service.ID, notes, to); err != nil {
+			return nil, err
+		}
+	}
+	if from != "" {
+		if fromIndex, err = rangeBound(db, service.ID, notes, from); err != nil {
+			return nil, err
+		}
+	}
+
+	// a service without versions has an empty changelog
+	if len(notes) > 0 && fromIndex < toIndex {
+		return nil, errors.New(constants.INVALID_VERSION_RANGE)
+	}
+
+	return &api.ChangelogResponse{
+		ServiceName: service.Name,
+		From:        from,
+		To:          to,
+		Versions:    notes[toIndex : fromIndex+1],
+	}, nil
+}
+
+// rangeBound finds the index of a bound of the range, which is a version name
+// or the name of a channel pointing to a version
+func rangeBound(db *gorm.DB, serviceID uint, versions []api.VersionReleaseNotes, name string) (int, error) {
+	if index := versionIndex(versions, name); index >= 0 {
+		return index, nil
+	}
+
+	version, err := findVersion(db, serviceID, name)
+	if err != nil {
+		return -1, err
+	}
+
+	if index := versionIndex(versions, version.Name); index >= 0 {
+		return index, nil
+	}
+
+	return -1, errors.New(constants.VERSION_RECORD_NOT_FOUND)
+}
+
+func versionIndex(versions []api.VersionReleaseNotes, name string) int {
+	for i, version := range versions {
+		if version.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
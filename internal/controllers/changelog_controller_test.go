@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func expectChangelogVersions() {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(123, "test-service"))
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE service_id = $1 AND "versions"."deleted_at" IS NULL`)).
+		WithArgs(123).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at", "release_notes"}).
+			AddRow(1, "1.2.0", now.Add(-4*time.Hour), `{"added":["Refunds"]}`).
+			AddRow(2, "1.10.0", now.Add(-3*time.Hour), `{"fixed":["Rounding"]}`).
+			AddRow(3, "1.3.0", now.Add(-2*time.Hour), `{}`).
+			AddRow(4, "1.4.0", now.Add(-time.Hour), `{"security":["TLS"]}`))
+}
+
+func TestGetChangelog_Range(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	expectChangelogVersions()
+
+	changelog, err := GetChangelog(gormMockDB, "test-service", "1.3.0", "1.10.0")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test-service", changelog.ServiceName)
+	if assert.Len(t, changelog.Versions, 3) {
+		assert.Equal(t, "1.10.0", changelog.Versions[0].Name)
+		assert.Equal(t, api.ReleaseNotes{Fixed: []string{"Rounding"}}, changelog.Versions[0].ReleaseNotes)
+		assert.Equal(t, "1.4.0", changelog.Versions[1].Name)
+		assert.Equal(t, "1.3.0", changelog.Versions[2].Name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChangelog_InvalidRange(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	expectChangelogVersions()
+	_, err := GetChangelog(gormMockDB, "test-service", "1.4.0", "1.2.0")
+	assert.EqualError(t, err, constants.INVALID_VERSION_RANGE)
+
+	expectChangelogVersions()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE (service_id = $1 AND name = $2) AND "versions"."deleted_at" IS NULL ORDER BY "versions"."id" LIMIT $3`)).
+		WithArgs(123, "1.1.0", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectChannelLookup("1.1.0", 0)
+	_, err = GetChangelog(gormMockDB, "test-service", "1.1.0", "")
+	assert.EqualError(t, err, constants.VERSION_RECORD_NOT_FOUND)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
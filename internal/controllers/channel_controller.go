@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+// channel names start with a letter so that they never look like a semantic
+// version
+var channelNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// GetChannels lists the channels of a service along with the versions they
+// point to
+func GetChannels(db *gorm.DB, serviceName string) (*api.ChannelListResponse, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := []api.ChannelResponse{}
+	if err := db.Model(&models.Channel{}).
+		Select("channels.name, versions.name AS version_name, channels.updated_at").
+		Joins("JOIN versions ON versions.id = channels.version_id").
+		Where("channels.service_id = ?", service.ID).
+		Order("channels.name ASC").
+		Scan(&channels).Error; err != nil {
+		return nil, err
+	}
+
+	return &api.ChannelListResponse{
+		ServiceName: service.Name,
+		Channels:    channels,
+	}, nil
+}
+
+// SetChannel points the channel to a version, creating the channel when it
+// does not exist yet. It reports whether the channel was created. Pointing a
+// channel to the version it already points to changes nothing.
+func SetChannel(db *gorm.DB, serviceName, channelName string, channelRequest api.ChannelRequest) (bool, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	if !channelNamePattern.MatchString(channelName) {
+		err := errors.New(constants.INVALID_CHANNEL_NAME)
+		countMutation(db.Statement.Context, RESOURCE_CHANNEL, OPERATION_REPLACE, err)
+		return false, err
+	}
+
+	created := false
+	err := mutate(db, RESOURCE_CHANNEL, OPERATION_REPLACE, func(tx *gorm.DB) (*models.Event, error) {
+		// not to point to a version being deleted
+		service, err := lockService(tx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		version, err := findVersion(tx, service.ID, channelRequest.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		move := models.ChannelMove{
+			ServiceID:   service.ID,
+			Channel:     channelName,
+			ToVersionID: &version.ID,
+			MovedAt:     time.Now(),
+		}
+		data := api.ChannelEventData{
+			ServiceName: service.Name,
+			Channel:     channelName,
+			ToVersion:   version.Name,
+		}
+
+		var channel models.Channel
+		err = tx.Where("service_id = ? AND name = ?", service.ID, channelName).First(&channel).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			channel = models.Channel{
+				ServiceID: service.ID,
+				Name:      channelName,
+				VersionID: version.ID,
+				CreatedAt: move.MovedAt,
+				UpdatedAt: move.MovedAt,
+			}
+			if err := tx.Create(&channel).Error; err != nil {
+				// another request created it since it was looked up
+				if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+					return nil, errors.New(constants.DUPLICATE_CHANNEL_ERROR)
+				}
+
+				return nil, err
+			}
+			created = true
+
+		case err != nil:
+			return nil, err
+
+		case channel.VersionID == version.ID:
+			return nil, nil
+
+		default:
+			var from models.Version
+			if err := tx.Unscoped().Where("id = ?", channel.VersionID).First(&from).Error; err != nil {
+				return nil, err
+			}
+			move.FromVersionID = &from.ID
+			data.FromVersion = from.Name
+
+			channel.VersionID = version.ID
+			channel.UpdatedAt = move.MovedAt
+			if err := tx.Save(&channel).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		if err := tx.Create(&move).Error; err != nil {
+			return nil, err
+		}
+
+		return recordEvent(tx, constants.EVENT_CHANNEL_MOVED, service.Name, version.Name, data)
+	})
+
+	return created, err
+}
+
+// DeleteChannel deletes the channel, its moves are kept
+func DeleteChannel(db *gorm.DB, serviceName, channelName string) error {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_CHANNEL, OPERATION_DELETE, func(tx *gorm.DB) (*models.Event, error) {
+		service, err := getService(tx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		var channel models.Channel
+		if err := tx.Where("service_id = ? AND name = ?", service.ID, channelName).First(&channel).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, errors.New(constants.CHANNEL_RECORD_NOT_FOUND)
+			}
+			return nil, err
+		}
+
+		var from models.Version
+		if err := tx.Where("id = ?", channel.VersionID).First(&from).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(&channel).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Create(&models.ChannelMove{
+			ServiceID:     service.ID,
+			Channel:       channel.Name,
+			FromVersionID: &from.ID,
+			MovedAt:       time.Now(),
+		}).Error; err != nil {
+			return nil, err
+		}
+
+		return recordEvent(tx, constants.EVENT_CHANNEL_DELETED, service.Name, from.Name, api.ChannelEventData{
+			ServiceName: service.Name,
+			Channel:     channel.Name,
+			FromVersion: from.Name,
+		})
+	})
+}
+
+// GetChannelHistory lists the moves of a channel, latest first. The history
+// of a deleted channel is kept.
+func GetChannelHistory(db *gorm.DB, page int, serviceName, channelName string) (int64, []api.ChannelMoveResponse, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	query := func() *gorm.DB {
+		return db.Model(&models.ChannelMove{}).
+			Where("channel_moves.service_id = ? AND channel_moves.channel = ?", service.ID, channelName)
+	}
+
+	var totalMoves int64
+	if err := query().Count(&totalMoves).Error; err != nil {
+		return -1, nil, err
+	}
+
+	if totalMoves == 0 {
+		return -1, nil, errors.New(constants.CHANNEL_RECORD_NOT_FOUND)
+	}
+
+	totalPages := int(math.Ceil(float64(totalMoves) / float64(constants.PAGE_SIZE)))
+	if page > totalPages && page != 1 {
+		return -1, nil, errors.New(constants.INVALID_PAGE_NUMBER)
+	}
+
+	// versions are joined even when deleted since they remain part of the
+	// history
+	moves := []api.ChannelMoveResponse{}
+	if err := query().
+		Select("channel_moves.channel, from_versions.name AS from_version, to_versions.name AS to_version, channel_moves.moved_at").
+		Joins("LEFT JOIN versions AS from_versions ON from_versions.id = channel_moves.from_version_id").
+		Joins("LEFT JOIN versions AS to_versions ON to_versions.id = channel_moves.to_version_id").
+		Order("channel_moves.id DESC").
+		Offset((page - 1) * constants.PAGE_SIZE).
+		Limit(constants.PAGE_SIZE).
+		Scan(&moves).Error; err != nil {
+		return -1, nil, err
+	}
+
+	return totalMoves, moves, nil
+}
+
+// findVersion looks a version of the service up by its name, or by the name
+// of a channel pointing to it. Version names take precedence over channels.
+// It serves reads and the versions referred to, e.g. deployed, while a version
+// being changed is looked up with getVersion, never through a channel.
+func findVersion(db *gorm.DB, serviceID uint, name string) (*models.Version, error) {
+	version, err := getVersion(db, serviceID, name)
+	if err == nil || err.Error() != constants.VERSION_RECORD_NOT_FOUND {
+		return version, err
+	}
+
+	var channel models.Channel
+	if err := db.Where("service_id = ? AND name = ?", serviceID, name).First(&channel).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(constants.VERSION_RECORD_NOT_FOUND)
+		}
+		return nil, err
+	}
+
+	var pointedTo models.Version
+	if err := db.Where("id = ?", channel.VersionID).First(&pointedTo).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(constants.VERSION_RECORD_NOT_FOUND)
+		}
+		return nil, err
+	}
+
+	return &pointedTo, nil
+}
+
+// versionChannels lists the channels pointing to the version
+func versionChannels(db *gorm.DB, versionID uint) ([]string, error) {
+	var channels []string
+	if err := db.Model(&models.Channel{}).
+		Where("version_id = ?", versionID).
+		Order("name ASC").
+		Pluck("name", &channels).Error; err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	channelQuery         = `SELECT * FROM "channels" WHERE service_id = $1 AND name = $2 ORDER BY "channels"."id" LIMIT $3`
+	versionChannelsQuery = `SELECT "name" FROM "channels" WHERE version_id = $1 ORDER BY name ASC`
+)
+
+func expectChannelLookup(name string, versionID int) {
+	query := mock.ExpectQuery(regexp.QuoteMeta(channelQuery)).
+		WithArgs(123, name, 1)
+
+	if versionID == 0 {
+		query.WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		return
+	}
+
+	query.WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "name", "version_id", "created_at", "updated_at"}).
+		AddRow(3, 123, name, versionID, time.Now(), time.Now()))
+}
+
+func TestSetChannel_Create(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectLockedServiceAndVersionFound()
+	expectChannelLookup("stable", 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channels" ("service_id","name","version_id","created_at","updated_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs(123, "stable", 7, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channel_moves"`)).
+		WithArgs(123, "stable", sqlmock.AnyArg(), 7).
+		WillReturnRows(sqlmock.NewRows([]string{"from_version_id", "id"}).AddRow(nil, 1))
+	expectEventRecorded(constants.EVENT_CHANNEL_MOVED, "test-service", "v1")
+	mock.ExpectCommit()
+
+	created, err := SetChannel(gormMockDB, "test-service", "stable", api.ChannelRequest{Version: "v1"})
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetChannel_CreatedConcurrently(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectServiceAndVersionFound()
+	expectChannelLookup("stable", 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channels"`)).
+		WillReturnError(errors.New(`ERROR: duplicate key value violates unique constraint "unique_service_channel" (SQLSTATE 23505)`))
+	mock.ExpectRollback()
+
+	created, err := SetChannel(gormMockDB, "test-service", "stable", api.ChannelRequest{Version: "v1"})
+
+	assert.EqualError(t, err, constants.DUPLICATE_CHANNEL_ERROR)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetChannel_Move(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectServiceAndVersionFound()
+	expectChannelLookup("stable", 6)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE id = $1 ORDER BY "versions"."id" LIMIT $2`)).
+		WithArgs(6, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(6, "v0", 123))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "channels" SET "service_id"=$1,"name"=$2,"version_id"=$3,"created_at"=$4,"updated_at"=$5 WHERE "id" = $6`)).
+		WithArgs(123, "stable", 7, sqlmock.AnyArg(), sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channel_moves"`)).
+		WithArgs(123, "stable", sqlmock.AnyArg(), 6, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	expectEventRecorded(constants.EVENT_CHANNEL_MOVED, "test-service", "v1")
+	mock.ExpectCommit()
+
+	created, err := SetChannel(gormMockDB, "test-service", "stable", api.ChannelRequest{Version: "v1"})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetChannel_Unchanged(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectServiceAndVersionFound()
+	expectChannelLookup("stable", 7)
+	mock.ExpectCommit()
+
+	created, err := SetChannel(gormMockDB, "test-service", "stable", api.ChannelRequest{Version: "v1"})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetChannel_InvalidName(t *testing.T) {
+	_, err := SetChannel(gormMockDB, "test-service", "1.0", api.ChannelRequest{Version: "v1"})
+	assert.EqualError(t, err, constants.INVALID_CHANNEL_NAME)
+}
+
+func TestDeleteChannel_Success(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(123, "test-service"))
+	expectChannelLookup("stable", 7)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE id = $1 AND "versions"."deleted_at" IS NULL ORDER BY "versions"."id" LIMIT $2`)).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(7, "v1", 123))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "channels" WHERE "channels"."id" = $1`)).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channel_moves"`)).
+		WithArgs(123, "stable", sqlmock.AnyArg(), 7).
+		WillReturnRows(sqlmock.NewRows([]string{"to_version_id", "id"}).AddRow(nil, 3))
+	expectEventRecorded(constants.EVENT_CHANNEL_DELETED, "test-service", "v1")
+	mock.ExpectCommit()
+
+	err := DeleteChannel(gormMockDB, "test-service", "stable")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteVersion_PointedToByChannel(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectLockedServiceAndVersionFound()
+	mock.ExpectQuery(regexp.QuoteMeta(versionChannelsQuery)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("stable"))
+	mock.ExpectRollback()
+
+	// forcing only skips the deployments check
+	err := DeleteVersion(gormMockDB, "test-service", "v1", true)
+
+	assert.EqualError(t, err, constants.VERSION_POINTED_TO_BY_CHANNEL)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetVersionWithArtifacts_ByChannel(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(123, "test-service"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE (service_id = $1 AND name = $2) AND "versions"."deleted_at" IS NULL ORDER BY "versions"."id" LIMIT $3`)).
+		WithArgs(123, "stable", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectChannelLookup("stable", 7)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE id = $1 AND "versions"."deleted_at" IS NULL ORDER BY "versions"."id" LIMIT $2`)).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(7, "v1", 123))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "artifacts" WHERE version_id = $1 ORDER BY id ASC`)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version_id"}))
+
+	version, err := GetVersionWithArtifacts(gormMockDB, "test-service", "stable")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", version.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchVersion_NotByChannel(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(123, "test-service"))
+	// the channel is not looked up
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE (service_id = $1 AND name = $2) AND "versions"."deleted_at" IS NULL ORDER BY "versions"."id" LIMIT $3`)).
+		WithArgs(123, "stable", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err := PatchVersion(gormMockDB, "test-service", "stable", api.ServiceVersionRequest{Description: "Stable"})
+
+	assert.EqualError(t, err, constants.VERSION_RECORD_NOT_FOUND)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
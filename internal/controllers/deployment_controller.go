@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+const deploymentColumns = "services.name AS service_name, versions.name AS version_name, environments.name AS environment, deployments.actor, deployments.deployed_at"
+
+var environmentNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+func GetEnvironments(db *gorm.DB) ([]models.Environment, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	var environments []models.Environment
+	if err := db.Order("id ASC").Find(&environments).Error; err != nil {
+		return nil, err
+	}
+
+	return environments, nil
+}
+
+// CreateEnvironment records an environment versions can be deployed to. It
+// publishes no event, but is counted with the other mutations.
+func CreateEnvironment(db *gorm.DB, environmentRequest api.EnvironmentRequest) (err error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	defer func() { countMutation(db.Statement.Context, RESOURCE_ENVIRONMENT, OPERATION_CREATE, err) }()
+
+	if !environmentNamePattern.MatchString(environmentRequest.Name) {
+		return errors.New(constants.INVALID_ENVIRONMENT_NAME)
+	}
+
+	environment := models.Environment{
+		Name:        environmentRequest.Name,
+		Description: environmentRequest.Description,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := db.Create(&environment).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return errors.New(constants.DUPLICATE_ENVIRONMENT_ERROR)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// DeployVersion records a version of the service being deployed to an
+// environment, where it replaces whichever version was deployed before
+func DeployVersion(db *gorm.DB, serviceName string, deploymentRequest api.DeploymentRequest) error {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_DEPLOYMENT, OPERATION_CREATE, func(tx *gorm.DB) (*models.Event, error) {
+		// not to deploy a version being deleted
+		service, err := lockService(tx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		version, err := findVersion(tx, service.ID, deploymentRequest.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		environment, err := getEnvironment(tx, deploymentRequest.Environment)
+		if err != nil {
+			return nil, err
+		}
+
+		deployment := models.Deployment{
+			ServiceID:     service.ID,
+			VersionID:     version.ID,
+			EnvironmentID: environment.ID,
+			Actor:         deploymentRequest.Actor,
+			DeployedAt:    time.Now(),
+		}
+
+		if err := tx.Create(&deployment).Error; err != nil {
+			return nil, err
+		}
+
+		return recordEvent(tx, constants.EVENT_VERSION_DEPLOYED, service.Name, version.Name, api.DeploymentEventData{
+			ServiceName: service.Name,
+			VersionName: version.Name,
+			Environment: environment.Name,
+			Actor:       deployment.Actor,
+		})
+	})
+}
+
+// GetDeploymentHistory lists the deployments of a service, latest first,
+// optionally only those to an environment
+func GetDeploymentHistory(db *gorm.DB, page int, serviceName, environmentName string) (int64, []api.DeploymentResponse, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	if environmentName != "" {
+		if _, err := getEnvironment(db, environmentName); err != nil {
+			return -1, nil, err
+		}
+	}
+
+	query := func() *gorm.DB {
+		query := deploymentsQuery(db).Where("deployments.service_id = ?", service.ID)
+		if environmentName != "" {
+			query = query.Where("environments.name = ?", environmentName)
+		}
+		return query
+	}
+
+	return paginateDeployments(query, page, "deployments.id DESC")
+}
+
+// GetEnvironmentServices lists the version of every service currently deployed
+// to the environment, which is the one of its latest deployment there.
+// Services deployed with a version deleted since still list it.
+func GetEnvironmentServices(db *gorm.DB, page int, environmentName string) (int64, []api.DeploymentResponse, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	environment, err := getEnvironment(db, environmentName)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	query := func() *gorm.DB {
+		latest := db.Model(&models.Deployment{}).
+			Select("MAX(id)").
+			Where("environment_id = ?", environment.ID).
+			Group("service_id")
+
+		return deploymentsQuery(db).
+			Where("services.deleted_at IS NULL").
+			Where("deployments.id IN (?)", latest)
+	}
+
+	return paginateDeployments(query, page, "services.name ASC")
+}
+
+// deployedEnvironments lists the environments where the version is the one
+// currently deployed
+func deployedEnvironments(db *gorm.DB, serviceID, versionID uint) ([]string, error) {
+	latest := db.Model(&models.Deployment{}).
+		Select("MAX(id)").
+		Where("service_id = ?", serviceID).
+		Group("environment_id")
+
+	var environments []string
+	if err := db.Model(&models.Deployment{}).
+		Joins("JOIN environments ON environments.id = deployments.environment_id").
+		Where("deployments.id IN (?) AND deployments.version_id = ?", latest, versionID).
+		Order("environments.name ASC").
+		Pluck("environments.name", &environments).Error; err != nil {
+		return nil, err
+	}
+
+	return environments, nil
+}
+
+func getEnvironment(db *gorm.DB, name string) (*models.Environment, error) {
+	var environment models.Environment
+	if err := db.Where("name = ?", name).First(&environment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(constants.ENVIRONMENT_RECORD_NOT_FOUND)
+		}
+		return nil, err
+	}
+
+	return &environment, nil
+}
+
+// deploymentsQuery joins the names deployments are listed with. Versions are
+// joined even when deleted since they remain part of the history.
+func deploymentsQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Deployment{}).
+		Joins("JOIN services ON services.id = deployments.service_id").
+		Joins("JOIN versions ON versions.id = deployments.version_id").
+		Joins("JOIN environments ON environments.id = deployments.environment_id")
+}
+
+// paginateDeployments counts and fetches a page of the deployments selected by
+// query, which is called once per statement
+func paginateDeployments(query func() *gorm.DB, page int, order string) (int64, []api.DeploymentResponse, error) {
+	var totalDeployments int64
+	if err := query().Count(&totalDeployments).Error; err != nil {
+		return -1, nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(totalDeployments) / float64(constants.PAGE_SIZE)))
+	if page > totalPages && page != 1 {
+		return -1, nil, errors.New(constants.INVALID_PAGE_NUMBER)
+	}
+
+	deployments := []api.DeploymentResponse{}
+	if err := query().
+		Select(deploymentColumns).
+		Order(order).
+		Offset((page - 1) * constants.PAGE_SIZE).
+		Limit(constants.PAGE_SIZE).
+		Scan(&deployments).Error; err != nil {
+		return -1, nil, err
+	}
+
+	return totalDeployments, deployments, nil
+}
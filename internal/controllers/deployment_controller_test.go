@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const deployedEnvironmentsQuery = `SELECT "environments"."name" FROM "deployments" JOIN environments ON environments.id = deployments.environment_id WHERE deployments.id IN (SELECT MAX(id) FROM "deployments" WHERE service_id = $1 GROUP BY "environment_id") AND deployments.version_id = $2 ORDER BY environments.name ASC`
+
+func expectServiceAndVersionFound() {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow(123, "test-service", "Test service", 1))
+	expectVersionFound()
+}
+
+// expectLockedServiceAndVersionFound is expectServiceAndVersionFound for the
+// mutations locking the service
+func expectLockedServiceAndVersionFound() {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
+			AddRow(123, "test-service", "Test service", 1))
+	expectVersionFound()
+}
+
+func expectVersionFound() {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE (service_id = $1 AND name = $2) AND "versions"."deleted_at" IS NULL ORDER BY "versions"."id" LIMIT $3`)).
+		WithArgs(123, "v1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).
+			AddRow(7, "v1", 123))
+}
+
+func expectEnvironmentLookup(name string, found bool) {
+	query := mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "environments" WHERE name = $1 ORDER BY "environments"."id" LIMIT $2`)).
+		WithArgs(name, 1)
+
+	if found {
+		query.WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, name))
+	} else {
+		query.WillReturnError(gorm.ErrRecordNotFound)
+	}
+}
+
+func TestDeployVersion_Success(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectLockedServiceAndVersionFound()
+	expectEnvironmentLookup("prod", true)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "deployments" ("service_id","version_id","environment_id","actor","deployed_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs(123, 7, 3, "ci", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	expectEventRecorded(constants.EVENT_VERSION_DEPLOYED, "test-service", "v1")
+	mock.ExpectCommit()
+
+	err := DeployVersion(gormMockDB, "test-service", api.DeploymentRequest{Version: "v1", Environment: "prod", Actor: "ci"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeployVersion_EnvironmentNotFound(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectServiceAndVersionFound()
+	expectEnvironmentLookup("qa", false)
+	mock.ExpectRollback()
+
+	err := DeployVersion(gormMockDB, "test-service", api.DeploymentRequest{Version: "v1", Environment: "qa", Actor: "ci"})
+
+	assert.EqualError(t, err, constants.ENVIRONMENT_RECORD_NOT_FOUND)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteVersion_CurrentlyDeployed(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectLockedServiceAndVersionFound()
+	mock.ExpectQuery(regexp.QuoteMeta(versionChannelsQuery)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	mock.ExpectQuery(regexp.QuoteMeta(deployedEnvironmentsQuery)).
+		WithArgs(123, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("prod"))
+	mock.ExpectRollback()
+
+	err := DeleteVersion(gormMockDB, "test-service", "v1", false)
+
+	assert.EqualError(t, err, constants.VERSION_CURRENTLY_DEPLOYED)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEnvironmentServices_Success(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	expectEnvironmentLookup("prod", true)
+
+	latest := `deployments.id IN (SELECT MAX(id) FROM "deployments" WHERE environment_id = $1 GROUP BY "service_id")`
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "deployments" JOIN services ON services.id = deployments.service_id JOIN versions ON versions.id = deployments.version_id JOIN environments ON environments.id = deployments.environment_id WHERE services.deleted_at IS NULL AND ` + latest)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	deployedAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name AS service_name, versions.name AS version_name, environments.name AS environment, deployments.actor, deployments.deployed_at FROM "deployments" JOIN services ON services.id = deployments.service_id JOIN versions ON versions.id = deployments.version_id JOIN environments ON environments.id = deployments.environment_id WHERE services.deleted_at IS NULL AND `+latest+` ORDER BY services.name ASC LIMIT $2`)).
+		WithArgs(3, constants.PAGE_SIZE).
+		WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "environment", "actor", "deployed_at"}).
+			AddRow("test-service", "v1", "prod", "ci", deployedAt))
+
+	total, deployments, err := GetEnvironmentServices(gormMockDB, 1, "prod")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []api.DeploymentResponse{{
+		ServiceName: "test-service",
+		VersionName: "v1",
+		Environment: "prod",
+		Actor:       "ci",
+		DeployedAt:  deployedAt,
+	}}, deployments)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateEnvironment_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "Prod", "-prod", "prod east", "a-very-long-environment-name-over-32"} {
+		err := CreateEnvironment(gormMockDB, api.EnvironmentRequest{Name: name})
+		assert.EqualError(t, err, constants.INVALID_ENVIRONMENT_NAME, name)
+	}
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/events"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+const (
+	// number of persisted events fetched per query while replaying
+	EVENT_REPLAY_BATCH_SIZE = 100
+
+	// events are also dispatched this often, for the ones recorded while
+	// older transactions were still running when notified
+	EVENT_DISPATCH_INTERVAL = time.Second
+
+	// the oldest transaction still running, all the ones before it are done
+	OLDEST_RUNNING_XID = "pg_snapshot_xmin(pg_current_snapshot())::text::bigint"
+)
+
+// GetEventsAfter returns the events after position in the order they are
+// streamed, leaving out the ones of transactions which others started before
+// could still commit before.
+func GetEventsAfter(db *gorm.DB, position events.Position, filter events.Filter, limit int) ([]models.Event, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	db = db.Where("(xid, id) > (?, ?)", position.Xid, position.ID).
+		Where("xid < " + OLDEST_RUNNING_XID)
+
+	if filter.ServiceName != "" {
+		db = db.Where("service_name = ?", filter.ServiceName)
+	}
+
+	if len(filter.Types) > 0 {
+		db = db.Where("type IN ?", filter.Types)
+	}
+
+	var records []models.Event
+	if err := db.Order("xid ASC, id ASC").Limit(limit).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// GetEventPosition returns the position of a streamed event, for streams to
+// resume after it
+func GetEventPosition(db *gorm.DB, eventID uint) (events.Position, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	var event models.Event
+	if err := db.Select("xid", "id").First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return events.Position{}, errors.New(constants.UNKNOWN_LAST_EVENT_ID)
+		}
+		return events.Position{}, err
+	}
+
+	return events.PositionOf(event), nil
+}
+
+// ReplayEvents calls fn, in order, for every persisted event after position
+// matching the filter. It returns the position of the last event replayed.
+func ReplayEvents(db *gorm.DB, position events.Position, filter events.Filter, fn func(models.Event) error) (events.Position, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	for {
+		backlog, err := GetEventsAfter(db, position, filter, EVENT_REPLAY_BATCH_SIZE)
+		if err != nil {
+			return position, err
+		}
+
+		for _, event := range backlog {
+			if err := fn(event); err != nil {
+				return position, err
+			}
+			position = events.PositionOf(event)
+		}
+
+		if len(backlog) < EVENT_REPLAY_BATCH_SIZE {
+			return position, nil
+		}
+	}
+}
+
+// EventDispatcher publishes the recorded events to the live streams. It reads
+// them once for all the streams, which only send the ones after their own
+// position.
+type EventDispatcher struct {
+	db       *gorm.DB
+	position events.Position
+}
+
+// NewEventDispatcher starts from the events to come. It must be created
+// before the streams are served, for those resuming not to miss any event.
+func NewEventDispatcher(db *gorm.DB) (*EventDispatcher, error) {
+	var oldestRunningXid uint64
+	if err := db.Raw("SELECT " + OLDEST_RUNNING_XID).Scan(&oldestRunningXid).Error; err != nil {
+		return nil, err
+	}
+
+	return &EventDispatcher{
+		db:       db,
+		position: events.Position{Xid: oldestRunningXid},
+	}, nil
+}
+
+// Run dispatches the events whenever notified of recorded ones, and every
+// EVENT_DISPATCH_INTERVAL, until the context is cancelled
+func (d *EventDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(EVENT_DISPATCH_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events.Notifications():
+		case <-ticker.C:
+		}
+
+		if err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("error dispatching events", "error", err)
+		}
+	}
+}
+
+// Dispatch publishes the events recorded since the last call, in order
+func (d *EventDispatcher) Dispatch(ctx context.Context) error {
+	var err error
+	d.position, err = ReplayEvents(d.db.WithContext(ctx), d.position, events.Filter{}, func(event models.Event) error {
+		events.Publish(event)
+		return nil
+	})
+
+	return err
+}
+
+// mutate runs a controller mutation in a transaction, so that the change, its
+// event and its outbox message are committed together. The dispatcher is
+// notified of the event, and the cached reads it affects are dropped, only
+// once the transaction is committed. A mutation which changed nothing returns
+// a nil event. Mutations are counted per resource, operation and outcome, the
+// unexpected failures being logged with the request.
+func mutate(db *gorm.DB, resource, operation string, fn func(tx *gorm.DB) (*models.Event, error)) error {
+	var event *models.Event
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		event, err = fn(tx)
+		return err
+	})
+	countMutation(db.Statement.Context, resource, operation, err)
+	if err != nil || event == nil {
+		return err
+	}
+
+	invalidateCache(*event)
+	events.Notify()
+
+	return nil
+}
+
+// recordEvent must be called with the transaction of the mutation it describes
+func recordEvent(db *gorm.DB, eventType, serviceName, versionName string, data interface{}) (*models.Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	event := models.Event{
+		Type:        eventType,
+		ServiceName: serviceName,
+		VersionName: versionName,
+		Data:        string(payload),
+		CreatedAt:   time.Now(),
+	}
+
+	if err := db.Create(&event).Error; err != nil {
+		return nil, err
+	}
+
+	message, err := json.Marshal(events.ToResponse(event))
+	if err != nil {
+		return nil, err
+	}
+
+	outboxMessage := models.OutboxMessage{
+		EventID:       event.ID,
+		Type:          event.Type,
+		Payload:       string(message),
+		CreatedAt:     event.CreatedAt,
+		NextAttemptAt: event.CreatedAt,
+	}
+
+	if err := db.Create(&outboxMessage).Error; err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func serviceEventData(service models.Service, previousName string) api.ServiceEventData {
+	return api.ServiceEventData{
+		ID:           service.ID,
+		Name:         service.Name,
+		Description:  service.Description,
+		VersionCount: service.VersionCount,
+		PreviousName: previousName,
+	}
+}
+
+func versionEventData(service models.Service, version models.Version) api.VersionEventData {
+	return api.VersionEventData{
+		Name:        version.Name,
+		Description: version.Description,
+		ServiceName: service.Name,
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventDispatcher_DispatchesInTransactionOrder(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`)).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_snapshot_xmin"}).
+			AddRow(700))
+
+	dispatcher, err := NewEventDispatcher(gormMockDB)
+	require.NoError(t, err)
+
+	all := events.Subscribe(events.Filter{})
+	defer events.Unsubscribe(all)
+	payments := events.Subscribe(events.Filter{ServiceName: "payments"})
+	defer events.Unsubscribe(payments)
+
+	// read once for both streams, 12 having been recorded by the older transaction
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "events" WHERE (xid, id) > ($1, $2) AND xid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint ORDER BY xid ASC, id ASC LIMIT $3`)).
+		WithArgs(700, 0, EVENT_REPLAY_BATCH_SIZE).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "xid", "type", "service_name", "data", "created_at"}).
+			AddRow(12, 700, "service.created", "payments", `{}`, time.Now()).
+			AddRow(11, 701, "service.created", "orders", `{}`, time.Now()))
+	// then from the last one dispatched
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "events" WHERE (xid, id) > ($1, $2)`)).
+		WithArgs(701, 11, EVENT_REPLAY_BATCH_SIZE).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "xid", "type", "service_name", "data", "created_at"}))
+
+	assert.NoError(t, dispatcher.Dispatch(context.Background()))
+	assert.NoError(t, dispatcher.Dispatch(context.Background()))
+
+	assert.Equal(t, uint(12), (<-all.C).ID)
+	assert.Equal(t, uint(11), (<-all.C).ID)
+	assert.Equal(t, uint(12), (<-payments.C).ID)
+	assert.Empty(t, payments.C)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+func GetServiceHistory(db *gorm.DB, page int, serviceName string) (int64, []models.ServiceRevision, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	var totalRevisions int64
+	if err := db.Model(&models.ServiceRevision{}).Where("service_id = ?", service.ID).Count(&totalRevisions).Error; err != nil {
+		return -1, nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(totalRevisions) / float64(constants.PAGE_SIZE)))
+	if page > totalPages && page != 1 {
+		return -1, nil, errors.New(constants.INVALID_PAGE_NUMBER)
+	}
+
+	// latest revisions first
+	var revisions []models.ServiceRevision
+	if err := db.Where("service_id = ?", service.ID).
+		Order("revision DESC").
+		Offset((page - 1) * constants.PAGE_SIZE).
+		Limit(constants.PAGE_SIZE).
+		Find(&revisions).Error; err != nil {
+		return -1, nil, err
+	}
+
+	return totalRevisions, revisions, nil
+}
+
+// GetServiceAtRevision returns the service as it looked at the given revision,
+// with UpdatedAt set to the time of that revision
+func GetServiceAtRevision(db *gorm.DB, serviceName string, revision int) (*models.Service, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	service, err := getService(db, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var serviceRevision models.ServiceRevision
+	if err := db.Where("service_id = ? AND revision = ?", service.ID, revision).First(&serviceRevision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(constants.REVISION_RECORD_NOT_FOUND)
+		}
+		return nil, err
+	}
+
+	var snapshot api.ServiceSnapshot
+	if err := json.Unmarshal([]byte(serviceRevision.Snapshot), &snapshot); err != nil {
+		return nil, err
+	}
+
+	service.Name = snapshot.Name
+	service.Description = snapshot.Description
+	service.Revision = serviceRevision.Revision
+	service.UpdatedAt = serviceRevision.CreatedAt
+
+	// revisions recorded before metadata existed have none
+	service.Links, service.Contacts, service.Attributes = "", "", ""
+	if err := setServiceMetadata(service, snapshot.Links, snapshot.Contacts, snapshot.Attributes); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func serviceSnapshot(service models.Service) (api.ServiceSnapshot, error) {
+	serviceMetadata, err := ServiceMetadata(service)
+	if err != nil {
+		return api.ServiceSnapshot{}, err
+	}
+
+	return api.ServiceSnapshot{
+		Name:            service.Name,
+		Description:     service.Description,
+		ServiceMetadata: serviceMetadata,
+	}, nil
+}
+
+// diffSnapshots lists the fields changed between two revisions of a service.
+// before is nil when the service is created, then only the fields set are
+// listed.
+func diffSnapshots(before *api.ServiceSnapshot, after api.ServiceSnapshot) []api.FieldChange {
+	var previous api.ServiceSnapshot
+	if before != nil {
+		previous = *before
+	}
+
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"name", previous.Name, after.Name},
+		{"description", previous.Description, after.Description},
+		{"links", previous.Links, after.Links},
+		{"contacts", previous.Contacts, after.Contacts},
+		{"attributes", previous.Attributes, after.Attributes},
+	}
+
+	var changes []api.FieldChange
+	for _, field := range fields {
+		if before == nil {
+			if !isEmpty(field.to) {
+				changes = append(changes, api.FieldChange{Field: field.name, From: nil, To: field.to})
+			}
+		} else if !isSame(field.from, field.to) {
+			changes = append(changes, api.FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+
+	return changes
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	switch reflected := reflect.ValueOf(value); reflected.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return reflected.Len() == 0
+	}
+	return false
+}
+
+// isSame treats empty and missing metadata alike
+func isSame(a, b interface{}) bool {
+	if isEmpty(a) && isEmpty(b) {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// recordRevision must be called with the transaction of the mutation, once the
+// service is saved with its new revision number
+func recordRevision(db *gorm.DB, service models.Service, snapshot api.ServiceSnapshot, changes []api.FieldChange) error {
+	changesData, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	snapshotData, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(&models.ServiceRevision{
+		ServiceID: service.ID,
+		Revision:  service.Revision,
+		Changes:   string(changesData),
+		Snapshot:  string(snapshotData),
+		CreatedAt: service.UpdatedAt,
+	}).Error
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	created := diffSnapshots(nil, api.ServiceSnapshot{Name: "payments", Description: "Payments"})
+	assert.Equal(t, []api.FieldChange{
+		{Field: "name", From: nil, To: "payments"},
+		{Field: "description", From: nil, To: "Payments"},
+	}, created)
+
+	before := api.ServiceSnapshot{Name: "payments", Description: "Payments"}
+	updated := diffSnapshots(&before, api.ServiceSnapshot{Name: "payments", Description: "Payments v2"})
+	assert.Equal(t, []api.FieldChange{
+		{Field: "description", From: "Payments", To: "Payments v2"},
+	}, updated)
+
+	assert.Empty(t, diffSnapshots(&before, before))
+
+	// metadata changes are listed, missing and empty metadata are alike
+	links := []api.ServiceLink{{Type: api.LINK_RUNBOOK, URL: "https://wiki.example.com/payments"}}
+	withLinks := api.ServiceSnapshot{Name: "payments", Description: "Payments", ServiceMetadata: api.ServiceMetadata{
+		Links:      links,
+		Contacts:   []api.ServiceContact{},
+		Attributes: map[string]interface{}{},
+	}}
+	assert.Equal(t, []api.FieldChange{
+		{Field: "links", From: []api.ServiceLink(nil), To: links},
+	}, diffSnapshots(&before, withLinks))
+}
+
+func TestGetServiceAtRevision_Success(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	revisedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "revision"}).
+			AddRow(123, "payments", "Payments v2", 2))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "service_revisions" WHERE service_id = $1 AND revision = $2 ORDER BY "service_revisions"."id" LIMIT $3`)).
+		WithArgs(123, 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "revision", "changes", "snapshot", "created_at"}).
+			AddRow(1, 123, 1, `[]`, `{"name":"payments","description":"Payments"}`, revisedAt))
+
+	service, err := GetServiceAtRevision(gormMockDB, "payments", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Payments", service.Description)
+	assert.Equal(t, 1, service.Revision)
+	assert.Equal(t, revisedAt, service.UpdatedAt)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetServiceAtRevision_RevisionNotFound(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(123, "payments"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "service_revisions" WHERE service_id = $1 AND revision = $2`)).
+		WithArgs(123, 9, 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := GetServiceAtRevision(gormMockDB, "payments", 9)
+
+	assert.EqualError(t, err, constants.REVISION_RECORD_NOT_FOUND)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"gorm.io/gorm"
+)
+
+// ServiceMetadata decodes the links, contacts and attributes of a service.
+// Missing metadata decodes to empty lists and an empty object.
+func ServiceMetadata(service models.Service) (api.ServiceMetadata, error) {
+	serviceMetadata := api.ServiceMetadata{
+		Links:      []api.ServiceLink{},
+		Contacts:   []api.ServiceContact{},
+		Attributes: map[string]interface{}{},
+	}
+
+	for _, field := range []struct {
+		data  string
+		value interface{}
+	}{
+		{service.Links, &serviceMetadata.Links},
+		{service.Contacts, &serviceMetadata.Contacts},
+		{service.Attributes, &serviceMetadata.Attributes},
+	} {
+		if field.data == "" {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(field.data), field.value); err != nil {
+			return serviceMetadata, err
+		}
+	}
+
+	// null is stored as such by nothing but hand-written rows
+	if serviceMetadata.Links == nil {
+		serviceMetadata.Links = []api.ServiceLink{}
+	}
+	if serviceMetadata.Contacts == nil {
+		serviceMetadata.Contacts = []api.ServiceContact{}
+	}
+	if serviceMetadata.Attributes == nil {
+		serviceMetadata.Attributes = map[string]interface{}{}
+	}
+
+	return serviceMetadata, nil
+}
+
+// setServiceMetadata encodes the metadata fields that are set into the service
+func setServiceMetadata(service *models.Service, links []api.ServiceLink, contacts []api.ServiceContact, attributes map[string]interface{}) error {
+	for _, field := range []struct {
+		value interface{}
+		isSet bool
+		data  *string
+	}{
+		{links, links != nil, &service.Links},
+		{contacts, contacts != nil, &service.Contacts},
+		{attributes, attributes != nil, &service.Attributes},
+	} {
+		if !field.isSet {
+			continue
+		}
+
+		encoded, err := json.Marshal(field.value)
+		if err != nil {
+			return err
+		}
+		*field.data = string(encoded)
+	}
+
+	return nil
+}
+
+// filterByAttributes narrows a services query down to the services whose
+// attribute at each path, as text, equals the filter value
+func filterByAttributes(db *gorm.DB, attributeFilters map[string]string) *gorm.DB {
+	for _, path := range metadata.SortedPaths(attributeFilters) {
+		attribute, args := attributeText(path)
+		db = db.Where(attribute+" = ?", append(args, attributeFilters[path])...)
+	}
+
+	return db
+}
+
+// attributeText is the SQL expression of the attribute of services at path, as
+// text, and its arguments. It is NULL for the services without it.
+func attributeText(path string) (string, []interface{}) {
+	segments := strings.Split(path, metadata.ATTRIBUTE_PATH_SEPARATOR)
+
+	args := make([]interface{}, 0, len(segments)+1)
+	for _, segment := range segments {
+		args = append(args, segment)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(segments)), ", ")
+	return "jsonb_extract_path_text(attributes, " + placeholders + ")", args
+}
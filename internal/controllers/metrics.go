@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// labels of the mutations metric
+const (
+	RESOURCE_SERVICE     = "service"
+	RESOURCE_VERSION     = "version"
+	RESOURCE_CHANNEL     = "channel"
+	RESOURCE_DEPLOYMENT  = "deployment"
+	RESOURCE_ENVIRONMENT = "environment"
+
+	OPERATION_CREATE = "create"
+	OPERATION_UPDATE = "update"
+	// creates or updates, e.g. PUT
+	OPERATION_REPLACE = "replace"
+	OPERATION_DELETE  = "delete"
+
+	OUTCOME_SUCCESS   = "success"
+	OUTCOME_INVALID   = "invalid"
+	OUTCOME_NOT_FOUND = "not_found"
+	OUTCOME_CONFLICT  = "conflict"
+	OUTCOME_ERROR     = "error"
+)
+
+// registered with the default registry, which is what the metrics server exposes
+var mutations = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: constants.METRICS_NAMESPACE,
+	Subsystem: "catalog",
+	Name:      "mutations_total",
+	Help:      "Number of mutations of the catalog, per resource, operation and outcome.",
+}, []string{"resource", "operation", "outcome"})
+
+// countMutation counts a mutation once it has its outcome, the unexpected
+// failures being logged with the request. A lookup by a previous name of the
+// service is not one, FollowRename running it again with the current name.
+func countMutation(ctx context.Context, resource, operation string, err error) {
+	var renamed *ServiceRenamedError
+	if errors.As(err, &renamed) {
+		return
+	}
+
+	outcome := mutationOutcome(err)
+	mutations.WithLabelValues(resource, operation, outcome).Inc()
+
+	if outcome == OUTCOME_ERROR {
+		slog.ErrorContext(ctx, "mutation failed", "resource", resource, "operation", operation, "error", err)
+	}
+}
+
+// mutationOutcome classes the errors of the controllers the way the APIs map
+// them to statuses
+func mutationOutcome(err error) string {
+	if err == nil {
+		return OUTCOME_SUCCESS
+	}
+
+	if errors.Is(err, metadata.ErrInvalidAttributes) {
+		return OUTCOME_INVALID
+	}
+
+	switch err.Error() {
+	case constants.INVALID_REQUEST_BODY, constants.INVALID_ENVIRONMENT_NAME, constants.INVALID_CHANNEL_NAME:
+		return OUTCOME_INVALID
+	case constants.SERVICE_RECORD_NOT_FOUND, constants.VERSION_RECORD_NOT_FOUND, constants.ENVIRONMENT_RECORD_NOT_FOUND, constants.CHANNEL_RECORD_NOT_FOUND:
+		return OUTCOME_NOT_FOUND
+	case constants.DUPLICATE_SERVICE_NAME_ERROR, constants.DUPLICATE_VERSION_RECORD_ERROR, constants.DUPLICATE_ENVIRONMENT_ERROR,
+		constants.DUPLICATE_CHANNEL_ERROR, constants.VERSION_CURRENTLY_DEPLOYED, constants.VERSION_POINTED_TO_BY_CHANNEL:
+		return OUTCOME_CONFLICT
+	}
+
+	return OUTCOME_ERROR
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -9,11 +10,17 @@ import (
 
 	constants "github.com/Prashansa-K/serviceCatalog/internal"
 	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
 	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func GetPaginatedServicesByFilters(db *gorm.DB, page int, sort, nameFilter, descriptionFilter string) (int64, []models.Service, error) {
+func GetPaginatedServicesByFilters(db *gorm.DB, page int, sort, nameFilter, descriptionFilter string, attributeFilters map[string]string) (int64, []models.Service, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
 	if nameFilter != "" {
 		db = db.Where("LOWER(name) LIKE ?", "%"+nameFilter+"%")
 	}
@@ -22,6 +29,8 @@ func GetPaginatedServicesByFilters(db *gorm.DB, page int, sort, nameFilter, desc
 		db = db.Where("LOWER(description) LIKE ?", "%"+descriptionFilter+"%")
 	}
 
+	db = filterByAttributes(db, attributeFilters)
+
 	db = db.Model(&models.Service{})
 
 	// Find the total count of all services with the above name and description
@@ -48,6 +57,9 @@ func GetPaginatedServicesByFilters(db *gorm.DB, page int, sort, nameFilter, desc
 }
 
 func GetServiceByNameWithPaginatedVersions(db *gorm.DB, page int, serviceName string) (int64, *models.Service, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
 	var totalVersions int64
 	if err := db.Model(&models.Version{}).
 		Joins("JOIN services ON versions.service_id = services.id").
@@ -61,7 +73,7 @@ func GetServiceByNameWithPaginatedVersions(db *gorm.DB, page int, serviceName st
 		return db.Offset((page - 1) * constants.PAGE_SIZE).Limit(constants.PAGE_SIZE)
 	}).First(&service).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return -1, nil, errors.New(constants.SERVICE_RECORD_NOT_FOUND)
+			return -1, nil, renamedService(db, serviceName)
 		}
 		return -1, nil, err
 	}
@@ -70,127 +82,514 @@ func GetServiceByNameWithPaginatedVersions(db *gorm.DB, page int, serviceName st
 }
 
 func CreateService(db *gorm.DB, serviceRequest api.ServiceRequest) error {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_SERVICE, OPERATION_CREATE, func(tx *gorm.DB) (*models.Event, error) {
+		return createService(tx, serviceRequest)
+	})
+}
+
+func createService(tx *gorm.DB, serviceRequest api.ServiceRequest) (*models.Event, error) {
+	now := time.Now()
 	service := models.Service{
 		Name:         serviceRequest.Name,
 		Description:  serviceRequest.Description,
 		VersionCount: 0, // Initial version count is 0
-		CreatedAt:    time.Now(),
+		Revision:     1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	// services are created with all their metadata, empty if not sent
+	links, contacts, attributes := serviceRequest.Links, serviceRequest.Contacts, serviceRequest.Attributes
+	if links == nil {
+		links = []api.ServiceLink{}
+	}
+	if contacts == nil {
+		contacts = []api.ServiceContact{}
+	}
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	// checked as stored, for the schema's required attributes to be sent
+	if err := metadata.ValidateAttributes(attributes); err != nil {
+		return nil, err
+	}
+	if err := setServiceMetadata(&service, links, contacts, attributes); err != nil {
+		return nil, err
+	}
+
+	if err := claimServiceName(tx, service.Name, 0); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(&service).Error; err != nil {
+		if isDuplicateServiceName(err) {
+			return nil, errors.New(constants.DUPLICATE_SERVICE_NAME_ERROR)
+		}
+		return nil, err
+	}
+
+	snapshot, err := serviceSnapshot(service)
+	if err != nil {
+		return nil, err
 	}
 
-	return db.Create(&service).Error
+	if err := recordRevision(tx, service, snapshot, diffSnapshots(nil, snapshot)); err != nil {
+		return nil, err
+	}
+
+	return recordEvent(tx, constants.EVENT_SERVICE_CREATED, service.Name, "", serviceEventData(service, ""))
 }
 
 func CreateVersion(db *gorm.DB, versionRequest api.ServiceVersionRequest) error {
-	var service models.Service
-	if err := db.Where("name = ?", versionRequest.ServiceName).First(&service).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(constants.SERVICE_RECORD_NOT_FOUND)
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_VERSION, OPERATION_CREATE, func(tx *gorm.DB) (*models.Event, error) {
+		// the service is named in the body, where a previous name can not be
+		// redirected, so it is followed instead
+		var service *models.Service
+		if err := FollowRename(versionRequest.ServiceName, func(serviceName string) (err error) {
+			service, err = getService(tx, serviceName)
+			return err
+		}); err != nil {
+			return nil, err
 		}
 
-		return err
+		return createVersion(tx, service, versionRequest)
+	})
+}
+
+func createVersion(tx *gorm.DB, service *models.Service, versionRequest api.ServiceVersionRequest) (*models.Event, error) {
+	releaseNotes := api.ReleaseNotes{}
+	if versionRequest.ReleaseNotes != nil {
+		releaseNotes = *versionRequest.ReleaseNotes
+	}
+
+	releaseNotesJSON, err := json.Marshal(releaseNotes)
+	if err != nil {
+		return nil, err
 	}
 
 	version := models.Version{
-		Name:        versionRequest.Name,
-		ServiceID:   service.ID,
-		Description: versionRequest.Description,
-		CreatedAt:   time.Now(),
+		Name:         versionRequest.Name,
+		ServiceID:    service.ID,
+		Description:  versionRequest.Description,
+		ReleaseNotes: string(releaseNotesJSON),
+		CreatedAt:    time.Now(),
 	}
 
-	if err := db.Create(&version).Error; err != nil {
+	if err := tx.Create(&version).Error; err != nil {
 		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			return errors.New(constants.DUPLICATE_VERSION_RECORD_ERROR)
+			return nil, errors.New(constants.DUPLICATE_VERSION_RECORD_ERROR)
 		}
 
-		return err
+		return nil, err
+	}
+
+	if err := createArtifacts(tx, version, versionRequest.Artifacts); err != nil {
+		return nil, err
 	}
 
 	// Increment the version count for the service
 	service.VersionCount++
-	if err := db.Save(&service).Error; err != nil {
-		return err
+	if err := tx.Save(service).Error; err != nil {
+		return nil, err
 	}
 
-	return nil
+	data := versionEventData(*service, version)
+	data.Artifacts = versionRequest.Artifacts
+	data.ReleaseNotes = versionRequest.ReleaseNotes
+
+	return recordEvent(tx, constants.EVENT_VERSION_CREATED, service.Name, version.Name, data)
 }
 
-func DeleteService(db *gorm.DB, serviceName string) error {
-	var service models.Service
-	if err := db.Where("name = ?", serviceName).First(&service).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(constants.SERVICE_RECORD_NOT_FOUND)
+// ReplaceVersion creates the version of the service, or replaces its
+// description, artifacts and release notes, those not sent being cleared
+func ReplaceVersion(db *gorm.DB, serviceName, versionName string, versionRequest api.ServiceVersionRequest) (created bool, err error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	err = mutate(db, RESOURCE_VERSION, OPERATION_REPLACE, func(tx *gorm.DB) (*models.Event, error) {
+		service, err := getService(tx, serviceName)
+		if err != nil {
+			return nil, err
 		}
 
-		return err
-	}
+		version, err := getVersion(tx, service.ID, versionName)
+		if err != nil {
+			if err.Error() != constants.VERSION_RECORD_NOT_FOUND {
+				return nil, err
+			}
 
-	// Soft deleting versions
-	if err := db.Where("service_id = ?", service.ID).Delete(&models.Version{}).Error; err != nil {
-		return err
-	}
+			created = true
+			versionRequest.Name = versionName
+			return createVersion(tx, service, versionRequest)
+		}
 
-	// Soft delete the service
-	if err := db.Delete(&service).Error; err != nil {
-		return err
-	}
+		return updateVersion(tx, *service, *version, versionRequest, true)
+	})
 
-	return nil
+	return created, err
 }
 
-func DeleteVersion(db *gorm.DB, serviceName, versionName string) error {
-	var service models.Service
-	if err := db.Where("name = ?", serviceName).First(&service).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(constants.SERVICE_RECORD_NOT_FOUND)
+// PatchVersion changes the description, artifacts or release notes of a
+// version, those sent
+func PatchVersion(db *gorm.DB, serviceName, versionName string, versionRequest api.ServiceVersionRequest) error {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_VERSION, OPERATION_UPDATE, func(tx *gorm.DB) (*models.Event, error) {
+		service, err := getService(tx, serviceName)
+		if err != nil {
+			return nil, err
 		}
 
-		return errors.New(constants.ERROR_FETCHING_SERVICE)
+		version, err := getVersion(tx, service.ID, versionName)
+		if err != nil {
+			return nil, err
+		}
+
+		return updateVersion(tx, *service, *version, versionRequest, false)
+	})
+}
+
+// updateVersion sets the fields sent, or all of them when replacing. Names of
+// versions never change.
+func updateVersion(tx *gorm.DB, service models.Service, version models.Version, versionRequest api.ServiceVersionRequest, replace bool) (*models.Event, error) {
+	if replace || versionRequest.Description != "" {
+		version.Description = versionRequest.Description
 	}
 
-	var version models.Version
-	if err := db.Where("service_id = ? AND name = ?", service.ID, versionName).First(&version).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(constants.VERSION_RECORD_NOT_FOUND)
+	if replace || versionRequest.ReleaseNotes != nil {
+		releaseNotes := api.ReleaseNotes{}
+		if versionRequest.ReleaseNotes != nil {
+			releaseNotes = *versionRequest.ReleaseNotes
 		}
 
-		return errors.New(constants.ERROR_FETCHING_SERVICE)
+		releaseNotesJSON, err := json.Marshal(releaseNotes)
+		if err != nil {
+			return nil, err
+		}
+		version.ReleaseNotes = string(releaseNotesJSON)
 	}
 
-	// Soft delete the version
-	if err := db.Delete(&version).Error; err != nil {
-		return err
+	if err := tx.Save(&version).Error; err != nil {
+		return nil, err
 	}
 
-	// Decrement the version count for the service
-	service.VersionCount--
-	if err := db.Save(&service).Error; err != nil {
-		return err
+	if replace || versionRequest.Artifacts != nil {
+		if err := tx.Where("version_id = ?", version.ID).Delete(&models.Artifact{}).Error; err != nil {
+			return nil, err
+		}
+
+		if err := createArtifacts(tx, version, versionRequest.Artifacts); err != nil {
+			return nil, err
+		}
+	}
+
+	data := versionEventData(service, version)
+	data.Artifacts = versionRequest.Artifacts
+	data.ReleaseNotes = versionRequest.ReleaseNotes
+
+	return recordEvent(tx, constants.EVENT_VERSION_UPDATED, service.Name, version.Name, data)
+}
+
+func createArtifacts(tx *gorm.DB, version models.Version, artifacts []api.Artifact) error {
+	for _, artifact := range artifacts {
+		if err := tx.Create(&models.Artifact{
+			VersionID: version.ID,
+			Type:      artifact.Type,
+			Reference: artifact.Reference,
+			Digest:    artifact.Digest,
+			CreatedAt: time.Now(),
+		}).Error; err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+func DeleteService(db *gorm.DB, serviceName string) error {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_SERVICE, OPERATION_DELETE, func(tx *gorm.DB) (*models.Event, error) {
+		service, err := getService(tx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Soft deleting versions
+		if err := tx.Where("service_id = ?", service.ID).Delete(&models.Version{}).Error; err != nil {
+			return nil, err
+		}
+
+		// channels are deleted along, their moves being kept
+		if err := tx.Where("service_id = ?", service.ID).Delete(&models.Channel{}).Error; err != nil {
+			return nil, err
+		}
+
+		// previous names of the service are free to be used again
+		if err := tx.Where("service_id = ?", service.ID).Delete(&models.ServiceAlias{}).Error; err != nil {
+			return nil, err
+		}
+
+		// Soft delete the service
+		if err := tx.Delete(service).Error; err != nil {
+			return nil, err
+		}
+
+		return recordEvent(tx, constants.EVENT_SERVICE_DELETED, service.Name, "", serviceEventData(*service, ""))
+	})
+}
+
+// DeleteVersion refuses to delete a version currently deployed to an
+// environment, unless forced, and a version a channel points to
+func DeleteVersion(db *gorm.DB, serviceName, versionName string, force bool) error {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_VERSION, OPERATION_DELETE, func(tx *gorm.DB) (*models.Event, error) {
+		service, err := lockService(tx, serviceName)
+		if err != nil {
+			if err.Error() == constants.SERVICE_RECORD_NOT_FOUND || err.Error() == constants.SERVICE_RENAMED {
+				return nil, err
+			}
+
+			return nil, errors.New(constants.ERROR_FETCHING_SERVICE)
+		}
+
+		version, err := getVersion(tx, service.ID, versionName)
+		if err != nil {
+			if err.Error() == constants.VERSION_RECORD_NOT_FOUND {
+				return nil, err
+			}
+
+			return nil, errors.New(constants.ERROR_FETCHING_SERVICE)
+		}
+
+		// channels are never left dangling, even when forced
+		channels, err := versionChannels(tx, version.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(channels) > 0 {
+			return nil, errors.New(constants.VERSION_POINTED_TO_BY_CHANNEL)
+		}
+
+		if !force {
+			environments, err := deployedEnvironments(tx, service.ID, version.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(environments) > 0 {
+				return nil, errors.New(constants.VERSION_CURRENTLY_DEPLOYED)
+			}
+		}
+
+		// Soft delete the version
+		if err := tx.Delete(version).Error; err != nil {
+			return nil, err
+		}
+
+		// Decrement the version count for the service
+		service.VersionCount--
+		if err := tx.Save(service).Error; err != nil {
+			return nil, err
+		}
+
+		return recordEvent(tx, constants.EVENT_VERSION_DELETED, service.Name, version.Name, versionEventData(*service, *version))
+	})
+}
+
+// UpdateService keeps the previous name of a renamed service as an alias,
+// which no other service can be named after
 func UpdateService(db *gorm.DB, serviceRequest api.ServiceRequest) error {
-	var service models.Service
-	if err := db.Model(&models.Service{}).Where("id = ?", serviceRequest.ID).First(&service).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(constants.SERVICE_RECORD_NOT_FOUND)
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_SERVICE, OPERATION_UPDATE, func(tx *gorm.DB) (*models.Event, error) {
+		// locked, for concurrent updates not to take the same revision
+		var service models.Service
+		if err := tx.Model(&models.Service{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", serviceRequest.ID).First(&service).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, errors.New(constants.SERVICE_RECORD_NOT_FOUND)
+			}
+
+			return nil, err
 		}
 
-		return err
+		return updateService(tx, service, serviceRequest, false)
+	})
+}
+
+// PatchService is UpdateService for a service named rather than identified
+func PatchService(db *gorm.DB, serviceName string, serviceRequest api.ServiceRequest) error {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	return mutate(db, RESOURCE_SERVICE, OPERATION_UPDATE, func(tx *gorm.DB) (*models.Event, error) {
+		service, err := lockService(tx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		return updateService(tx, *service, serviceRequest, false)
+	})
+}
+
+// ReplaceService creates the service named serviceName, or replaces its
+// description and metadata, those not sent being cleared
+func ReplaceService(db *gorm.DB, serviceName string, serviceRequest api.ServiceRequest) (created bool, err error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	serviceRequest.Name = serviceName
+
+	err = mutate(db, RESOURCE_SERVICE, OPERATION_REPLACE, func(tx *gorm.DB) (*models.Event, error) {
+		service, err := lockService(tx, serviceName)
+		if err != nil {
+			if err.Error() != constants.SERVICE_RECORD_NOT_FOUND {
+				return nil, err
+			}
+
+			created = true
+			return createService(tx, serviceRequest)
+		}
+
+		return updateService(tx, *service, serviceRequest, true)
+	})
+
+	return created, err
+}
+
+// updateService sets the fields sent, or all of them when replacing
+func updateService(tx *gorm.DB, service models.Service, serviceRequest api.ServiceRequest, replace bool) (*models.Event, error) {
+	previousName := service.Name
+	before, err := serviceSnapshot(service)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := serviceRequest.Name != "" && serviceRequest.Name != service.Name
+	if renamed {
+		if err := claimServiceName(tx, serviceRequest.Name, service.ID); err != nil {
+			return nil, err
+		}
 	}
 
 	if serviceRequest.Name != "" {
 		service.Name = serviceRequest.Name
 	}
 
-	if serviceRequest.Description != "" {
+	if replace || serviceRequest.Description != "" {
 		service.Description = serviceRequest.Description
 	}
 
-	if err := db.Save(service).Error; err != nil {
-		return err
+	links, contacts, attributes := serviceRequest.Links, serviceRequest.Contacts, serviceRequest.Attributes
+	if replace {
+		if links == nil {
+			links = []api.ServiceLink{}
+		}
+		if contacts == nil {
+			contacts = []api.ServiceContact{}
+		}
+		if attributes == nil {
+			attributes = map[string]interface{}{}
+		}
+	}
+	if attributes != nil {
+		if err := metadata.ValidateAttributes(attributes); err != nil {
+			return nil, err
+		}
+	}
+	if err := setServiceMetadata(&service, links, contacts, attributes); err != nil {
+		return nil, err
+	}
+
+	after, err := serviceSnapshot(service)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	// an update changing nothing keeps updated_at and records no event
+	changes := diffSnapshots(&before, after)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	service.Revision++
+
+	if err := tx.Save(&service).Error; err != nil {
+		if isDuplicateServiceName(err) {
+			return nil, errors.New(constants.DUPLICATE_SERVICE_NAME_ERROR)
+		}
+		return nil, err
+	}
+
+	if renamed {
+		if err := recordRename(tx, service, previousName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordRevision(tx, service, after, changes); err != nil {
+		return nil, err
+	}
+
+	if previousName == service.Name {
+		previousName = ""
+	}
+
+	return recordEvent(tx, constants.EVENT_SERVICE_UPDATED, service.Name, "", serviceEventData(service, previousName))
+}
+
+// getService looks a service up by its current name. Looking it up by a
+// previous name is a ServiceRenamedError.
+func getService(db *gorm.DB, name string) (*models.Service, error) {
+	var service models.Service
+	if err := db.Where("name = ?", name).First(&service).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, renamedService(db, name)
+		}
+		return nil, err
+	}
+
+	return &service, nil
+}
+
+// lockService is getService locking the row of the service until the
+// transaction ends. The mutations checking the versions of a service before
+// changing them take it, for their checks to hold until they commit, since
+// versions are soft deleted and their foreign keys never fire. So do the
+// updates, for concurrent ones not to take the same revision.
+func lockService(tx *gorm.DB, name string) (*models.Service, error) {
+	var service models.Service
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).First(&service).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, renamedService(tx, name)
+		}
+		return nil, err
+	}
+
+	return &service, nil
+}
+
+// getVersion looks a version of the service up by its name only, for the
+// version to be changed. findVersion also follows channels.
+func getVersion(db *gorm.DB, serviceID uint, name string) (*models.Version, error) {
+	var version models.Version
+	if err := db.Where("service_id = ? AND name = ?", serviceID, name).First(&version).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(constants.VERSION_RECORD_NOT_FOUND)
+		}
+		return nil, err
+	}
+
+	return &version, nil
 }
@@ -8,7 +8,10 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	constants "github.com/Prashansa-K/serviceCatalog/internal"
 	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/metadata"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -38,6 +41,34 @@ func initMockDB() error {
 	return nil
 }
 
+// changes to a service's fields are recorded as a revision
+func expectRevisionRecorded(serviceID uint, revision int) {
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "service_revisions" ("service_id","revision","changes","snapshot","created_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs(serviceID, revision, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(1))
+}
+
+// mutations record their event and outbox message in the same transaction
+func expectEventRecorded(eventType, serviceName, versionName string) {
+	if versionName == "" {
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "events" ("type","service_name","data","created_at") VALUES ($1,$2,$3,$4) RETURNING "version_name","id"`)).
+			WithArgs(eventType, serviceName, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(1))
+	} else {
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "events" ("type","service_name","data","created_at","version_name") VALUES ($1,$2,$3,$4,$5) RETURNING "version_name","id"`)).
+			WithArgs(eventType, serviceName, sqlmock.AnyArg(), sqlmock.AnyArg(), versionName).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(1))
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox" ("event_id","type","payload","attempts","created_at","next_attempt_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "last_error","published_at","id"`)).
+		WithArgs(1, eventType, sqlmock.AnyArg(), 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(1))
+}
+
 func TestGetServiceByNameWithPaginatedVersions_Success(t *testing.T) {
 	if (gormMockDB == nil) || (mock == nil) {
 		// Setup the mock DB
@@ -95,7 +126,7 @@ func TestGetPaginatedServicesByFilters_NoFilters_Success(t *testing.T) {
 			AddRow(456, "test-service-2", "Test check 2", 1))
 
 	// Create the controller and call the method
-	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "", "")
+	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "", "", nil)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -132,7 +163,7 @@ func TestGetPaginatedServicesByFilters_NameFilters_Success(t *testing.T) {
 			AddRow(456, "test-service-2", "Test check 2", 1))
 
 	// Create the controller and call the method
-	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "test", "")
+	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "test", "", nil)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -169,7 +200,7 @@ func TestGetPaginatedServicesByFilters_DescriptionFilters_Success(t *testing.T)
 			AddRow(456, "test-service-2", "Test check 2", 1))
 
 	// Create the controller and call the method
-	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "", "check")
+	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "", "check", nil)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -206,7 +237,7 @@ func TestGetPaginatedServicesByFilters_AllFilters_Success(t *testing.T) {
 			AddRow(456, "test-service-2", "Test check 2", 1))
 
 	// Create the controller and call the method
-	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "test", "check")
+	totalServices, services, err := GetPaginatedServicesByFilters(gormMockDB, 1, constants.ASC, "test", "check", nil)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -232,10 +263,14 @@ func TestCreateService_Success(t *testing.T) {
 
 	// Expect the query to be executed
 	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services" ("name","description","created_at","version_count") VALUES ($1,$2,$3,$4) RETURNING "deleted_at","id"`)).
-		WithArgs("test-service", "Test service", sqlmock.AnyArg(), 0).
+	expectServiceNameClaimed("test-service", 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services" ("name","description","created_at","updated_at","version_count","revision","links","contacts","attributes") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "deleted_at","id"`)).
+		WithArgs("test-service", "Test service", sqlmock.AnyArg(), sqlmock.AnyArg(), 0, 1, "[]", "[]", "{}").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).
 			AddRow("123"))
+
+	expectRevisionRecorded(123, 1)
+	expectEventRecorded("service.created", "test-service", "")
 	mock.ExpectCommit()
 
 	serviceRequest := api.ServiceRequest{
@@ -251,6 +286,30 @@ func TestCreateService_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateService_MissingRequiredAttribute(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	require.NoError(t, metadata.SetAttributesSchema([]byte(`{"type": "object", "required": ["tier"]}`)))
+	defer metadata.SetAttributesSchema(nil)
+
+	// checked before anything is written
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := CreateService(gormMockDB, api.ServiceRequest{
+		Name:        "test-service",
+		Description: "Test service",
+	})
+
+	assert.ErrorIs(t, err, metadata.ErrInvalidAttributes)
+	assert.ErrorContains(t, err, "tier")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCreateVersion_Success(t *testing.T) {
 	if (gormMockDB == nil) || (mock == nil) {
 		// Setup the mock DB
@@ -258,23 +317,23 @@ func TestCreateVersion_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
 		WithArgs("test-service", 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
 			AddRow("123", "test-service", "Test service", 1))
 
 	// Expect the query to be executed
-	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions" ("service_id","name","created_at","description") VALUES ($1,$2,$3,$4) RETURNING "deleted_at","description","id"`)).
-		WithArgs(123, "v1", sqlmock.AnyArg(), "Version 1").
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions" ("service_id","name","created_at","release_notes","description") VALUES ($1,$2,$3,$4,$5) RETURNING "deleted_at","description","id"`)).
+		WithArgs(123, "v1", sqlmock.AnyArg(), "{}", "Version 1").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).
 			AddRow(1))
-	mock.ExpectCommit()
 
-	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"deleted_at"=$4,"version_count"=$5 WHERE "services"."deleted_at" IS NULL AND "id" = $6`)).
-		WithArgs("test-service", "Test service", sqlmock.AnyArg(), nil, 2, 123).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"updated_at"=$4,"deleted_at"=$5,"version_count"=$6,"revision"=$7,"links"=$8,"contacts"=$9,"attributes"=$10 WHERE "services"."deleted_at" IS NULL AND "id" = $11`)).
+		WithArgs("test-service", "Test service", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, 0, "", "", "", 123).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	expectEventRecorded("version.created", "test-service", "v1")
 	mock.ExpectCommit()
 
 	versionRequest := api.ServiceVersionRequest{
@@ -298,21 +357,27 @@ func TestCreateVersion_ServiceNotFound(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
 		WithArgs("test-non-existing-service", 1).
 		WillReturnError(gorm.ErrRecordNotFound)
+	expectServiceNotRenamed("test-non-existing-service")
+	mock.ExpectRollback()
 
 	versionRequest := api.ServiceVersionRequest{
 		Name:        "v1",
 		ServiceName: "test-non-existing-service",
 		Description: "Version 1",
 	}
+	notFound := mutations.WithLabelValues(RESOURCE_VERSION, OPERATION_CREATE, OUTCOME_NOT_FOUND)
+	before := testutil.ToFloat64(notFound)
 
 	err := CreateVersion(gormMockDB, versionRequest)
 
 	// Assert the results
 	assert.Error(t, err)
 	assert.Equal(t, "service not found", err.Error())
+	assert.Equal(t, before+1, testutil.ToFloat64(notFound))
 
 	// Ensure all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -325,15 +390,16 @@ func TestCreateVersion_DuplicateVersions(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
 		WithArgs("test-service", 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
 			AddRow("123", "test-service", "Test service", 1))
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions" ("service_id","name","created_at","description") VALUES ($1,$2,$3,$4) RETURNING "deleted_at","description","id"`)).
-		WithArgs(123, "v1", sqlmock.AnyArg(), "Version 1").
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions" ("service_id","name","created_at","release_notes","description") VALUES ($1,$2,$3,$4,$5) RETURNING "deleted_at","description","id"`)).
+		WithArgs(123, "v1", sqlmock.AnyArg(), "{}", "Version 1").
 		WillReturnError(errors.New("duplicate key value violates unique constraint \"versions_service_id_name_key\""))
+	mock.ExpectRollback()
 
 	versionRequest := api.ServiceVersionRequest{
 		Name:        "v1",
@@ -341,11 +407,15 @@ func TestCreateVersion_DuplicateVersions(t *testing.T) {
 		Description: "Version 1",
 	}
 
+	conflict := mutations.WithLabelValues(RESOURCE_VERSION, OPERATION_CREATE, OUTCOME_CONFLICT)
+	before := testutil.ToFloat64(conflict)
+
 	err := CreateVersion(gormMockDB, versionRequest)
 
 	// Assert the results
 	assert.Error(t, err)
 	assert.Equal(t, "version with the same name already exists for this service", err.Error())
+	assert.Equal(t, before+1, testutil.ToFloat64(conflict))
 
 	// Ensure all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -358,15 +428,16 @@ func TestCreateVersion_ErrorInVersionCreation(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
 		WithArgs("test-service", 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
 			AddRow("123", "test-service", "Test service", 1))
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions" ("service_id","name","created_at","description") VALUES ($1,$2,$3,$4) RETURNING "deleted_at","description","id"`)).
-		WithArgs(123, "v1", sqlmock.AnyArg(), "Version 1").
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions" ("service_id","name","created_at","release_notes","description") VALUES ($1,$2,$3,$4,$5) RETURNING "deleted_at","description","id"`)).
+		WithArgs(123, "v1", sqlmock.AnyArg(), "{}", "Version 1").
 		WillReturnError(errors.New("some other error"))
+	mock.ExpectRollback()
 
 	versionRequest := api.ServiceVersionRequest{
 		Name:        "v1",
@@ -391,22 +462,26 @@ func TestDeleteService_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
 		WithArgs("test-service", 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
 			AddRow("123", "test-service", "Test service", 1))
 
 	// Expect the query to be executed
-	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions" SET "deleted_at"=$1 WHERE service_id = $2 AND "versions"."deleted_at" IS NULL`)).
 		WithArgs(sqlmock.AnyArg(), 123).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "channels" WHERE service_id = $1`)).
+		WithArgs(123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAliasesDeleted(123)
 
-	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "deleted_at"=$1 WHERE "services"."id" = $2 AND "services"."deleted_at" IS NULL`)).
 		WithArgs(sqlmock.AnyArg(), 123).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	expectEventRecorded("service.deleted", "test-service", "")
 	mock.ExpectCommit()
 
 	// Create the controller and call the method
@@ -427,6 +502,7 @@ func TestDeleteVersionInDB_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
 		WithArgs("test-service", 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
@@ -437,22 +513,29 @@ func TestDeleteVersionInDB_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
 			AddRow("123", "test-service", "Test service", 1))
 
+	mock.ExpectQuery(regexp.QuoteMeta(versionChannelsQuery)).
+		WithArgs(123).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+	mock.ExpectQuery(regexp.QuoteMeta(deployedEnvironmentsQuery)).
+		WithArgs(123, 123).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
 	// Expect the query to be executed
-	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions" SET "deleted_at"=$1 WHERE "versions"."id" = $2 AND "versions"."deleted_at" IS NULL`)).
 		WithArgs(sqlmock.AnyArg(), 123).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectCommit()
 
-	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"deleted_at"=$4,"version_count"=$5 WHERE "services"."deleted_at" IS NULL AND "id" = $6`)).
-		WithArgs("test-service", "Test service", sqlmock.AnyArg(), nil, 0, 123).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"updated_at"=$4,"deleted_at"=$5,"version_count"=$6,"revision"=$7,"links"=$8,"contacts"=$9,"attributes"=$10 WHERE "services"."deleted_at" IS NULL AND "id" = $11`)).
+		WithArgs("test-service", "Test service", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 0, 0, "", "", "", 123).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	expectEventRecorded("version.deleted", "test-service", "test-service")
 	mock.ExpectCommit()
 
 	// Create the controller and call the method
 
-	err := DeleteVersion(gormMockDB, "test-service", "v1")
+	err := DeleteVersion(gormMockDB, "test-service", "v1", false)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -468,16 +551,21 @@ func TestUpdateService_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE id = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE id = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2 FOR UPDATE`)).
 		WithArgs(123, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count"}).
 			AddRow("123", "test-service", "Test service", 1))
+	expectServiceNameClaimed("test-service-2", 123)
 
 	// Expect the query to be executed
-	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "id"=$1,"name"=$2,"description"=$3,"created_at"=$4,"deleted_at"=$5,"version_count"=$6 WHERE "services"."deleted_at" IS NULL AND "id" = $7`)).
-		WithArgs(123, "test-service-2", "Test service 2", sqlmock.AnyArg(), nil, 1, 123).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"updated_at"=$4,"deleted_at"=$5,"version_count"=$6,"revision"=$7,"links"=$8,"contacts"=$9,"attributes"=$10 WHERE "services"."deleted_at" IS NULL AND "id" = $11`)).
+		WithArgs("test-service-2", "Test service 2", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, "", "", "", 123).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRenameRecorded(123, "test-service-2", "test-service")
+
+	expectRevisionRecorded(123, 1)
+	expectEventRecorded("service.updated", "test-service-2", "")
 	mock.ExpectCommit()
 
 	serviceRequest := api.ServiceRequest{
@@ -493,3 +581,185 @@ func TestUpdateService_Success(t *testing.T) {
 	// Ensure all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestReplaceService_CreatesMissingService(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs("test-service", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	expectServiceNotRenamed("test-service")
+	expectServiceNameClaimed("test-service", 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services" ("name","description","created_at","updated_at","version_count","revision","links","contacts","attributes") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "deleted_at","id"`)).
+		WithArgs("test-service", "Test service", sqlmock.AnyArg(), sqlmock.AnyArg(), 0, 1, "[]", "[]", "{}").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow("123"))
+	expectRevisionRecorded(123, 1)
+	expectEventRecorded("service.created", "test-service", "")
+	mock.ExpectCommit()
+
+	created, err := ReplaceService(gormMockDB, "test-service", api.ServiceRequest{
+		Description: "Test service",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceService_ClearsFieldsLeftOut(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "revision", "attributes"}).
+			AddRow(123, "test-service", "Test service", 1, 1, `{"tier":"critical"}`))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"updated_at"=$4,"deleted_at"=$5,"version_count"=$6,"revision"=$7,"links"=$8,"contacts"=$9,"attributes"=$10 WHERE "services"."deleted_at" IS NULL AND "id" = $11`)).
+		WithArgs("test-service", "", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, "[]", "[]", "{}", 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRevisionRecorded(123, 2)
+	expectEventRecorded("service.updated", "test-service", "")
+	mock.ExpectCommit()
+
+	created, err := ReplaceService(gormMockDB, "test-service", api.ServiceRequest{})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchService_KeepsFieldsLeftOut(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "revision", "attributes"}).
+			AddRow(123, "test-service", "Test service", 1, 1, `{"tier":"critical"}`))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET "name"=$1,"description"=$2,"created_at"=$3,"updated_at"=$4,"deleted_at"=$5,"version_count"=$6,"revision"=$7,"links"=$8,"contacts"=$9,"attributes"=$10 WHERE "services"."deleted_at" IS NULL AND "id" = $11`)).
+		WithArgs("test-service", "Test service 2", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, "", "", `{"tier":"critical"}`, 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRevisionRecorded(123, 2)
+	expectEventRecorded("service.updated", "test-service", "")
+	mock.ExpectCommit()
+
+	err := PatchService(gormMockDB, "test-service", api.ServiceRequest{
+		Description: "Test service 2",
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchService_ConcurrentlyTakesTheNextRevision(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	// the service is read once the concurrent update committed revision 3
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "revision", "attributes"}).
+			AddRow(123, "test-service", "Test service", 1, 3, `{}`))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services" SET`)).
+		WithArgs("test-service", "Test service 2", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 4, "", "", `{}`, 123).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRevisionRecorded(123, 4)
+	expectEventRecorded("service.updated", "test-service", "")
+	mock.ExpectCommit()
+
+	err := PatchService(gormMockDB, "test-service", api.ServiceRequest{
+		Description: "Test service 2",
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchService_NoChangeIsNotSaved(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs("test-service", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "revision", "attributes"}).
+			AddRow(123, "test-service", "Test service", 1, 1, `{"tier":"critical"}`))
+	// neither saved nor recorded
+	mock.ExpectCommit()
+
+	err := PatchService(gormMockDB, "test-service", api.ServiceRequest{
+		Description: "Test service",
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceVersion_ReplacesArtifacts(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectServiceAndVersionFound()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions" SET "service_id"=$1,"name"=$2,"created_at"=$3,"deleted_at"=$4,"description"=$5,"release_notes"=$6 WHERE "versions"."deleted_at" IS NULL AND "id" = $7`)).
+		WithArgs(123, "v1", sqlmock.AnyArg(), nil, "", "{}", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "artifacts" WHERE version_id = $1`)).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEventRecorded("version.updated", "test-service", "v1")
+	mock.ExpectCommit()
+
+	created, err := ReplaceVersion(gormMockDB, "test-service", "v1", api.ServiceVersionRequest{})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchVersion_KeepsArtifactsLeftOut(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectBegin()
+	expectServiceAndVersionFound()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions" SET "service_id"=$1,"name"=$2,"created_at"=$3,"deleted_at"=$4,"description"=$5,"release_notes"=$6 WHERE "versions"."deleted_at" IS NULL AND "id" = $7`)).
+		WithArgs(123, "v1", sqlmock.AnyArg(), nil, "Version 1", sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEventRecorded("version.updated", "test-service", "v1")
+	mock.ExpectCommit()
+
+	err := PatchVersion(gormMockDB, "test-service", "v1", api.ServiceVersionRequest{
+		Description: "Version 1",
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
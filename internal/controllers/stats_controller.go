@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"gorm.io/gorm"
+)
+
+// CatalogStats is what the catalog holds, deleted services and versions left
+// out
+type CatalogStats struct {
+	Services int64
+	Versions int64
+	// per attribute path, then per value of the attribute, as text
+	ServicesByAttribute map[string]map[string]int64
+	VersionsByAttribute map[string]map[string]int64
+	// services whose version_count differs from their number of versions,
+	// which mutations keep equal
+	DriftedServices []string
+}
+
+// GetCatalogStats counts the services and versions, overall and per value of
+// the attributes at attributePaths, and checks their version_count
+func GetCatalogStats(db *gorm.DB, attributePaths []string) (*CatalogStats, error) {
+	db, span := tracing.Start(db)
+	defer span.End()
+
+	stats := CatalogStats{
+		ServicesByAttribute: map[string]map[string]int64{},
+		VersionsByAttribute: map[string]map[string]int64{},
+		DriftedServices:     []string{},
+	}
+
+	if err := db.Model(&models.Service{}).Count(&stats.Services).Error; err != nil {
+		return nil, err
+	}
+
+	if err := liveVersions(db).Count(&stats.Versions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, path := range attributePaths {
+		services, err := countByAttribute(db.Model(&models.Service{}), path)
+		if err != nil {
+			return nil, err
+		}
+		stats.ServicesByAttribute[path] = services
+
+		versions, err := countByAttribute(liveVersions(db), path)
+		if err != nil {
+			return nil, err
+		}
+		stats.VersionsByAttribute[path] = versions
+	}
+
+	if err := db.Model(&models.Service{}).
+		Joins("LEFT JOIN versions ON versions.service_id = services.id AND versions.deleted_at IS NULL").
+		Group("services.id").
+		Having("count(versions.id) <> services.version_count").
+		Order("services.name").
+		Pluck("services.name", &stats.DriftedServices).Error; err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// liveVersions queries the versions of the services not deleted
+func liveVersions(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Version{}).
+		Joins("JOIN services ON services.id = versions.service_id AND services.deleted_at IS NULL")
+}
+
+// countByAttribute counts the rows of query per value of the attribute of
+// their service at path, those without it left out
+func countByAttribute(query *gorm.DB, path string) (map[string]int64, error) {
+	attribute, args := attributeText(path)
+
+	var rows []struct {
+		Value string
+		Count int64
+	}
+	if err := query.
+		Select(attribute+" AS value, count(*) AS count", args...).
+		Where(attribute+" IS NOT NULL", args...).
+		Group("value").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCatalogStats(t *testing.T) {
+	if (gormMockDB == nil) || (mock == nil) {
+		// Setup the mock DB
+		err := initMockDB()
+		assert.NoError(t, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE "services"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions" JOIN services ON services.id = versions.service_id AND services.deleted_at IS NULL WHERE "versions"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT jsonb_extract_path_text(attributes, $1, $2) AS value, count(*) AS count FROM "services" WHERE jsonb_extract_path_text(attributes, $3, $4) IS NOT NULL AND "services"."deleted_at" IS NULL GROUP BY "value"`)).
+		WithArgs("owner", "team", "owner", "team").
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("payments", 2).AddRow("search", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT jsonb_extract_path_text(attributes, $1, $2) AS value, count(*) AS count FROM "versions" JOIN services ON services.id = versions.service_id AND services.deleted_at IS NULL WHERE jsonb_extract_path_text(attributes, $3, $4) IS NOT NULL AND "versions"."deleted_at" IS NULL GROUP BY "value"`)).
+		WithArgs("owner", "team", "owner", "team").
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("payments", 4))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "services"."name" FROM "services" LEFT JOIN versions ON versions.service_id = services.id AND versions.deleted_at IS NULL WHERE "services"."deleted_at" IS NULL GROUP BY "services"."id" HAVING count(versions.id) <> services.version_count ORDER BY services.name`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("search-api"))
+
+	stats, err := GetCatalogStats(gormMockDB, []string{"owner.team"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &CatalogStats{
+		Services:            3,
+		Versions:            5,
+		ServicesByAttribute: map[string]map[string]int64{"owner.team": {"payments": 2, "search": 1}},
+		VersionsByAttribute: map[string]map[string]int64{"owner.team": {"payments": 4}},
+		DriftedServices:     []string{"search-api"},
+	}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
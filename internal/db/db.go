@@ -1,24 +1,55 @@
 package db
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/logging"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
+)
+
+const (
+	// waited after the first failed attempt, doubling after each one
+	INITIAL_BACKOFF = 500 * time.Millisecond
+	MAX_BACKOFF     = 10 * time.Second
 )
 
 var DB *gorm.DB
 
+// replaced by tests
+var (
+	open = func(dsn string) (*gorm.DB, error) {
+		return gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newLogger()})
+	}
+	now   = time.Now
+	sleep = time.Sleep
+)
+
 func connect() error {
 	// Get the database configuration from the config.go file
 	dbConfig := config.GetDBConfig()
 
-	// Connect to the database
-	db, err := gorm.Open(postgres.Open(dbConfig.DSN), &gorm.Config{})
+	// Connect to the database, which may start after the service, e.g. when
+	// both are deployed together
+	db, err := openWithRetry(dbConfig)
 	if err != nil {
-		log.Fatal("Failed to connect to database: ", err)
+		return err
+	}
+
+	if err := instrument(db, dbConfig, dbConfig.DBName); err != nil {
+		return err
+	}
 
+	// Reads that can lag behind go to them, see GetReplicaDB
+	if err := connectReplicas(dbConfig); err != nil {
 		return err
 	}
 
@@ -27,6 +58,63 @@ func connect() error {
 	return nil
 }
 
+// instrument sizes the pool of db, exposes its statistics as go_sql_* on the
+// metrics server, labelled with name, times its queries and traces them
+func instrument(db *gorm.DB, dbConfig *config.DBConfig, name string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+
+	if err := prometheus.Register(collectors.NewDBStatsCollector(sqlDB, name)); err != nil {
+		slog.Warn("error registering the database pool metrics", "db", name, "error", err)
+	}
+
+	if err := observeQueries(db, name); err != nil {
+		return err
+	}
+
+	// the values of the queries are left out, holding the data of the users
+	return db.Use(otelgorm.NewPlugin(
+		otelgorm.WithTracerProvider(tracing.ChildSpans()),
+		otelgorm.WithDBName(name),
+		otelgorm.WithoutQueryVariables(),
+		otelgorm.WithoutMetrics(),
+	))
+}
+
+// openWithRetry backs off exponentially between attempts, until the connect
+// timeout would be exceeded
+func openWithRetry(dbConfig *config.DBConfig) (*gorm.DB, error) {
+	deadline := now().Add(dbConfig.ConnectTimeout)
+	backoff := INITIAL_BACKOFF
+
+	for {
+		db, err := open(dbConfig.DSN)
+		if err == nil {
+			return db, nil
+		}
+
+		if now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("giving up after %s: %w", dbConfig.ConnectTimeout, err)
+		}
+
+		slog.Warn("failed to connect to database, retrying", "backoff", backoff, "error", err)
+		sleep(backoff)
+
+		backoff = min(2*backoff, MAX_BACKOFF)
+	}
+}
+
+// newLogger logs the queries with the request they ran for, if any
+func newLogger() *logging.GormLogger {
+	return logging.NewGormLogger(config.GetLogConfig().SlowQueryThreshold)
+}
+
 func isConnected() bool {
 	return DB != nil
 }
@@ -44,3 +132,14 @@ func GetDB() (*gorm.DB, error) {
 
 	return DB, nil
 }
+
+// GetDBContext is GetDB for queries run on behalf of ctx, e.g. traced as part
+// of its request
+func GetDBContext(ctx context.Context) (*gorm.DB, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.WithContext(ctx), nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// fakeClock makes sleeping move the clock, returning the sleeps
+func fakeClock(t *testing.T) *[]time.Duration {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+
+	now = func() time.Time { return clock }
+	sleep = func(duration time.Duration) {
+		sleeps = append(sleeps, duration)
+		clock = clock.Add(duration)
+	}
+	t.Cleanup(func() {
+		now = time.Now
+		sleep = time.Sleep
+	})
+
+	return &sleeps
+}
+
+// failingOpen fails the first attempts, then connects to a mock
+func failingOpen(t *testing.T, failures int) *int {
+	sqlDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	attempts := 0
+	previous := open
+	open = func(dsn string) (*gorm.DB, error) {
+		attempts++
+		if attempts <= failures {
+			return nil, errors.New("connection refused")
+		}
+		return gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	}
+	t.Cleanup(func() { open = previous })
+
+	return &attempts
+}
+
+func TestOpenWithRetry_BacksOff(t *testing.T) {
+	sleeps := fakeClock(t)
+	attempts := failingOpen(t, 6)
+
+	db, err := openWithRetry(&config.DBConfig{ConnectTimeout: time.Minute})
+	require.NoError(t, err)
+	assert.NotNil(t, db)
+	assert.Equal(t, 7, *attempts)
+	assert.Equal(t, []time.Duration{
+		500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, MAX_BACKOFF,
+	}, *sleeps)
+}
+
+func TestOpenWithRetry_GivesUp(t *testing.T) {
+	sleeps := fakeClock(t)
+	failingOpen(t, 100)
+
+	_, err := openWithRetry(&config.DBConfig{ConnectTimeout: 5 * time.Second})
+	assert.EqualError(t, err, "giving up after 5s: connection refused")
+	// the next backoff would have ended past the timeout
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second}, *sleeps)
+}
+
+// queriesObserved is the number of queries timed for the database and operation
+func queriesObserved(t *testing.T, name, operation string) uint64 {
+	var metric dto.Metric
+	require.NoError(t, queryDuration.WithLabelValues(name, operation).(prometheus.Histogram).Write(&metric))
+
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestObserveQueries_PerOperation(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, observeQueries(db, "observed"))
+
+	mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("UPDATE services").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("VACUUM").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var count int64
+	require.NoError(t, db.Raw("SELECT count(*) FROM services").Scan(&count).Error)
+	require.NoError(t, db.Exec("UPDATE services SET version_count = 0").Error)
+	require.NoError(t, db.Exec("VACUUM services").Error)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, uint64(1), queriesObserved(t, "observed", "select"))
+	assert.Equal(t, uint64(1), queriesObserved(t, "observed", "update"))
+	assert.Equal(t, uint64(1), queriesObserved(t, "observed", OTHER_OPERATION))
+	assert.Equal(t, uint64(0), queriesObserved(t, "observed", "insert"))
+}
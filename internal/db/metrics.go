@@ -0,0 +1,91 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const (
+	// callbacks of the query histogram, and the statement setting they share
+	QUERY_METRICS_CALLBACK = "metrics:query_duration"
+	QUERY_START_SETTING    = "metrics:query_start"
+
+	// operation of the statements which are none of select, insert, update
+	// and delete
+	OTHER_OPERATION = "other"
+)
+
+// registered with the default registry, which is what the metrics server exposes
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: constants.METRICS_NAMESPACE,
+	Subsystem: "db",
+	Name:      "query_duration_seconds",
+	Help:      "Time taken by the queries, per database and operation.",
+	// most queries take milliseconds, a few seconds
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"db", "operation"})
+
+// observeQueries times each query run with db, labelled with name and the
+// operation of its statement, e.g. select
+func observeQueries(db *gorm.DB, name string) error {
+	start := func(tx *gorm.DB) {
+		tx.InstanceSet(QUERY_START_SETTING, now())
+	}
+	observe := func(tx *gorm.DB) {
+		started, ok := tx.InstanceGet(QUERY_START_SETTING)
+		if !ok {
+			return
+		}
+		queryDuration.WithLabelValues(name, queryOperation(tx)).Observe(now().Sub(started.(time.Time)).Seconds())
+	}
+
+	// each query runs the callbacks of one of these processors
+	callback := db.Callback()
+	for _, hook := range []struct {
+		callback interface {
+			Register(name string, fn func(*gorm.DB)) error
+		}
+		name string
+		fn   func(*gorm.DB)
+	}{
+		{callback.Create().Before("*"), "create:start", start},
+		{callback.Create().After("*"), "create:observe", observe},
+		{callback.Query().Before("*"), "query:start", start},
+		{callback.Query().After("*"), "query:observe", observe},
+		{callback.Update().Before("*"), "update:start", start},
+		{callback.Update().After("*"), "update:observe", observe},
+		{callback.Delete().Before("*"), "delete:start", start},
+		{callback.Delete().After("*"), "delete:observe", observe},
+		{callback.Row().Before("*"), "row:start", start},
+		{callback.Row().After("*"), "row:observe", observe},
+		{callback.Raw().Before("*"), "raw:start", start},
+		{callback.Raw().After("*"), "raw:observe", observe},
+	} {
+		if err := hook.callback.Register(QUERY_METRICS_CALLBACK+":"+hook.name, hook.fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryOperation is the first keyword of the statement, raw ones included
+func queryOperation(tx *gorm.DB) string {
+	fields := strings.Fields(tx.Statement.SQL.String())
+	if len(fields) == 0 {
+		return OTHER_OPERATION
+	}
+
+	switch operation := strings.ToLower(fields[0]); operation {
+	case "select", "insert", "update", "delete":
+		return operation
+	}
+
+	return OTHER_OPERATION
+}
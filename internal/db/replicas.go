@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// replica serves the reads that can lag behind the primary while healthy
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+var (
+	replicasMutex sync.RWMutex
+	replicas      []*replica
+
+	// replicas are taken in turns
+	nextReplica atomic.Uint64
+)
+
+// connectReplicas opens the replicas without waiting for them, each being
+// used once a check found it healthy
+func connectReplicas(dbConfig *config.DBConfig) error {
+	var connected []*replica
+	for i, dsn := range dbConfig.Replicas {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newLogger(), DisableAutomaticPing: true})
+		if err != nil {
+			return fmt.Errorf("replica %d: %w", i, err)
+		}
+
+		name := fmt.Sprintf("%s_replica_%d", dbConfig.DBName, i)
+		if err := instrument(db, dbConfig, name); err != nil {
+			return fmt.Errorf("replica %d: %w", i, err)
+		}
+
+		connected = append(connected, &replica{name: name, db: db})
+	}
+
+	replicasMutex.Lock()
+	defer replicasMutex.Unlock()
+
+	replicas = connected
+
+	return nil
+}
+
+// SetReplicas replaces the replicas, which are healthy until checked
+func SetReplicas(dbs ...*gorm.DB) {
+	var set []*replica
+	for i, db := range dbs {
+		r := &replica{name: fmt.Sprintf("replica_%d", i), db: db}
+		r.healthy.Store(true)
+		set = append(set, r)
+	}
+
+	replicasMutex.Lock()
+	defer replicasMutex.Unlock()
+
+	replicas = set
+}
+
+// GetReplicaDB is a healthy replica, or the primary when there is none
+func GetReplicaDB() (*gorm.DB, error) {
+	primary, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	replicasMutex.RLock()
+	defer replicasMutex.RUnlock()
+
+	for range replicas {
+		r := replicas[nextReplica.Add(1)%uint64(len(replicas))]
+		if r.healthy.Load() {
+			return r.db, nil
+		}
+	}
+
+	return primary, nil
+}
+
+// IsReplica tells whether db, or the session it was derived from, was
+// returned by GetReplicaDB for a replica, reads from which may miss the latest
+// writes
+func IsReplica(db *gorm.DB) bool {
+	replicasMutex.RLock()
+	defer replicasMutex.RUnlock()
+
+	// sessions share the pool of their connection
+	for _, r := range replicas {
+		if r.db.ConnPool == db.ConnPool {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckReplicas pings the replicas right away then every interval, until ctx
+// is done
+func CheckReplicas(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkReplicas(ctx, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkReplicas(ctx context.Context, timeout time.Duration) {
+	replicasMutex.RLock()
+	checked := replicas
+	replicasMutex.RUnlock()
+
+	for _, r := range checked {
+		err := ping(ctx, r.db, timeout)
+
+		// transitions only, for the log not to repeat itself
+		if healthy := err == nil; r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				slog.Info("replica is healthy, reading from it", "replica", r.name)
+			} else {
+				slog.Warn("replica is unhealthy, reading from the others or the primary", "replica", r.name, "error", err)
+			}
+		}
+	}
+}
+
+func ping(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
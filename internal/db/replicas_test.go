@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// mockDB returns a database whose pings are expected
+func mockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	return db, mock
+}
+
+func withPrimary(t *testing.T) *gorm.DB {
+	primary, _ := mockDB(t)
+
+	previous := DB
+	DB = primary
+	t.Cleanup(func() {
+		DB = previous
+		SetReplicas()
+	})
+
+	return primary
+}
+
+func TestGetReplicaDB_TakesHealthyReplicasInTurns(t *testing.T) {
+	primary := withPrimary(t)
+	first, firstMock := mockDB(t)
+	second, secondMock := mockDB(t)
+	SetReplicas(first, second)
+
+	reads := map[*gorm.DB]int{}
+	for i := 0; i < 4; i++ {
+		db, err := GetReplicaDB()
+		require.NoError(t, err)
+		reads[db]++
+	}
+	assert.Equal(t, map[*gorm.DB]int{first: 2, second: 2}, reads)
+	assert.True(t, IsReplica(first))
+	assert.False(t, IsReplica(primary))
+
+	// the unhealthy replica is skipped
+	firstMock.ExpectPing()
+	secondMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	checkReplicas(context.Background(), time.Second)
+
+	for i := 0; i < 2; i++ {
+		db, err := GetReplicaDB()
+		require.NoError(t, err)
+		assert.Equal(t, first, db)
+	}
+}
+
+func TestGetReplicaDB_FallsBackToPrimary(t *testing.T) {
+	primary := withPrimary(t)
+
+	db, err := GetReplicaDB()
+	require.NoError(t, err)
+	assert.Equal(t, primary, db, "without replicas")
+
+	replica, mock := mockDB(t)
+	SetReplicas(replica)
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	checkReplicas(context.Background(), time.Second)
+
+	db, err = GetReplicaDB()
+	require.NoError(t, err)
+	assert.Equal(t, primary, db, "without healthy replicas")
+
+	mock.ExpectPing()
+	checkReplicas(context.Background(), time.Second)
+
+	db, err = GetReplicaDB()
+	require.NoError(t, err)
+	assert.Equal(t, replica, db, "once the replica recovered")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,177 @@
+// Package deprecation tells clients of the REST API which routes are
+// deprecated and when they stop being served, from a registry the admin
+// configures
+package deprecation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LINK_HEADER points to the documentation of a deprecation (RFC 8288)
+const LINK_HEADER = "Link"
+
+// Route is a deprecated route, as registered with echo, e.g.
+// /v1/service/:serviceName
+type Route struct {
+	// every method of the path when empty
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path"`
+	// when the route was, or will be, deprecated
+	DeprecatedAt time.Time `json:"deprecated_at"`
+	// when the route stops being served, if planned
+	Sunset *time.Time `json:"sunset,omitempty"`
+	// documentation of the deprecation, e.g. how to migrate
+	Link string `json:"link,omitempty"`
+	// answer with a 410 from the sunset on, instead of serving the route
+	GoneAfterSunset bool `json:"gone_after_sunset,omitempty"`
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string]Route{}
+)
+
+// now is replaced by tests
+var now = time.Now
+
+// LoadRoutes reads the deprecated routes from a JSON file holding a list of
+// routes. An empty path deprecates none.
+func LoadRoutes(path string) error {
+	if path == "" {
+		return SetRoutes(nil)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var routes []Route
+	if err := decoder.Decode(&routes); err != nil {
+		return err
+	}
+
+	return SetRoutes(routes)
+}
+
+// SetRoutes replaces the deprecated routes, as a whole
+func SetRoutes(routes []Route) error {
+	next := map[string]Route{}
+	for _, route := range routes {
+		route.Method = strings.ToUpper(route.Method)
+
+		if err := validate(route); err != nil {
+			return fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+		}
+
+		key := registryKey(route.Method, route.Path)
+		if _, ok := next[key]; ok {
+			return fmt.Errorf("%s %s: deprecated more than once", route.Method, route.Path)
+		}
+		next[key] = route
+	}
+
+	registryMutex.Lock()
+	registry = next
+	registryMutex.Unlock()
+
+	return nil
+}
+
+// Routes lists the deprecated routes
+func Routes() []Route {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	routes := make([]Route, 0, len(registry))
+	for _, route := range registry {
+		routes = append(routes, route)
+	}
+
+	return routes
+}
+
+// Middleware adds the Deprecation, Sunset and Link headers to the responses
+// of deprecated routes, counting the calls made to them, and answers with a
+// 410 once a route gone after its sunset is past it. It has to run after
+// the routing, and after the authentication to count calls per caller.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		route, ok := lookup(ctx.Request().Method, ctx.Path())
+		if !ok {
+			return next(ctx)
+		}
+
+		// a deprecation to come is announced the same way (RFC 9745)
+		header := ctx.Response().Header()
+		header.Set(constants.DEPRECATION_HEADER, fmt.Sprintf("@%d", route.DeprecatedAt.Unix()))
+		if route.Sunset != nil {
+			header.Set(constants.SUNSET_HEADER, route.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if route.Link != "" {
+			header.Add(LINK_HEADER, fmt.Sprintf(`<%s>; rel="deprecation"`, route.Link))
+		}
+
+		gone := route.GoneAfterSunset && route.Sunset != nil && !now().Before(*route.Sunset)
+
+		deprecatedCalls.WithLabelValues(ctx.Request().Method, ctx.Path(), auth.Caller(ctx.Request()), fmt.Sprint(gone)).Inc()
+
+		if gone {
+			return ctx.JSON(http.StatusGone, constants.ROUTE_SUNSET)
+		}
+
+		return next(ctx)
+	}
+}
+
+func lookup(method, path string) (Route, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	if route, ok := registry[registryKey(method, path)]; ok {
+		return route, true
+	}
+
+	route, ok := registry[registryKey("", path)]
+	return route, ok
+}
+
+func registryKey(method, path string) string {
+	return method + " " + path
+}
+
+func validate(route Route) error {
+	if !strings.HasPrefix(route.Path, "/") {
+		return errors.New("path must start with /")
+	}
+
+	if route.DeprecatedAt.IsZero() {
+		return errors.New("deprecated_at is required")
+	}
+
+	if route.Sunset != nil && route.Sunset.Before(route.DeprecatedAt) {
+		return errors.New("sunset must not be before deprecated_at")
+	}
+
+	if route.GoneAfterSunset && route.Sunset == nil {
+		return errors.New("gone_after_sunset needs a sunset")
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package deprecation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/internal/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	deprecatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset       = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func setNow(t *testing.T, current time.Time) {
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func setRoutes(t *testing.T, routes []Route) {
+	require.NoError(t, SetRoutes(routes))
+	t.Cleanup(func() { SetRoutes(nil) })
+}
+
+func serve(method, path string) *httptest.ResponseRecorder {
+	app := echo.New()
+	app.Use(Middleware)
+	app.GET("/v1/service/:serviceName", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, "served")
+	})
+	app.PATCH("/v1/service", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, "served")
+	})
+
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set(echo.HeaderAuthorization, "Bearer test-key")
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestMiddleware_SignalsDeprecatedRoutes(t *testing.T) {
+	setNow(t, deprecatedAt.Add(time.Hour))
+	setRoutes(t, []Route{{
+		Method:       "get",
+		Path:         "/v1/service/:serviceName",
+		DeprecatedAt: deprecatedAt,
+		Sunset:       &sunset,
+		Link:         "https://example.com/migrating-to-v2",
+	}})
+	calls := deprecatedCalls.WithLabelValues(http.MethodGet, "/v1/service/:serviceName", auth.KeyID("test-key"), "false")
+	before := testutil.ToFloat64(calls)
+
+	recorder := serve(http.MethodGet, "/v1/service/payments")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "@1767225600", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", recorder.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrating-to-v2>; rel="deprecation"`, recorder.Header().Get("Link"))
+	assert.Equal(t, before+1, testutil.ToFloat64(calls))
+
+	// other methods of the path are not deprecated
+	recorder = serve(http.MethodPatch, "/v1/service")
+	assert.Empty(t, recorder.Header().Get("Deprecation"))
+}
+
+func TestMiddleware_GoneAfterSunset(t *testing.T) {
+	setRoutes(t, []Route{{
+		Path:            "/v1/service",
+		DeprecatedAt:    deprecatedAt,
+		Sunset:          &sunset,
+		GoneAfterSunset: true,
+	}})
+
+	setNow(t, sunset.Add(-time.Second))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPatch, "/v1/service").Code)
+
+	setNow(t, sunset)
+	recorder := serve(http.MethodPatch, "/v1/service")
+	assert.Equal(t, http.StatusGone, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Sunset"))
+}
+
+func TestMiddleware_ServedPastSunsetUnlessGone(t *testing.T) {
+	setNow(t, sunset.Add(time.Hour))
+	setRoutes(t, []Route{{
+		Path:         "/v1/service",
+		DeprecatedAt: deprecatedAt,
+		Sunset:       &sunset,
+	}})
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPatch, "/v1/service").Code)
+}
+
+func TestSetRoutes_Invalid(t *testing.T) {
+	for name, route := range map[string]Route{
+		"relative path":         {Path: "v1/service", DeprecatedAt: deprecatedAt},
+		"no deprecation date":   {Path: "/v1/service"},
+		"sunset before":         {Path: "/v1/service", DeprecatedAt: sunset, Sunset: &deprecatedAt},
+		"gone without a sunset": {Path: "/v1/service", DeprecatedAt: deprecatedAt, GoneAfterSunset: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, SetRoutes([]Route{route}))
+		})
+	}
+
+	assert.Error(t, SetRoutes([]Route{
+		{Method: "PATCH", Path: "/v1/service", DeprecatedAt: deprecatedAt},
+		{Method: "patch", Path: "/v1/service", DeprecatedAt: deprecatedAt},
+	}))
+}
+
+func TestLoadRoutes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deprecations.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"method":"PATCH","path":"/v1/service","deprecated_at":"2026-01-01T00:00:00Z","sunset":"2026-07-01T00:00:00Z","gone_after_sunset":true}]`), 0600))
+	t.Cleanup(func() { SetRoutes(nil) })
+
+	require.NoError(t, LoadRoutes(path))
+	assert.Equal(t, []Route{{
+		Method:          "PATCH",
+		Path:            "/v1/service",
+		DeprecatedAt:    deprecatedAt,
+		Sunset:          &sunset,
+		GoneAfterSunset: true,
+	}}, Routes())
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"path":"/v1/service","deprecated":"2026-01-01T00:00:00Z"}]`), 0600))
+	assert.Error(t, LoadRoutes(path), "unknown fields are most likely misspelled")
+
+	require.NoError(t, LoadRoutes(""))
+	assert.Empty(t, Routes())
+}
@@ -0,0 +1,16 @@
+package deprecation
+
+import (
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// registered with the default registry, which is what the metrics server exposes
+var deprecatedCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: constants.METRICS_NAMESPACE,
+	Subsystem: "deprecation",
+	Name:      "calls_total",
+	Help:      "Number of calls to deprecated routes, per route and caller, identified by the subject of its client certificate or by the start of the SHA-256 of its API key. gone tells whether the call was refused past the sunset.",
+}, []string{"method", "route", "caller", "gone"})
@@ -0,0 +1,235 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+)
+
+const (
+	// number of events a subscriber can lag behind before it is dropped
+	SUBSCRIBER_BUFFER_SIZE = 64
+)
+
+var EventTypes = []string{
+	constants.EVENT_SERVICE_CREATED,
+	constants.EVENT_SERVICE_UPDATED,
+	constants.EVENT_SERVICE_DELETED,
+	constants.EVENT_VERSION_CREATED,
+	constants.EVENT_VERSION_UPDATED,
+	constants.EVENT_VERSION_DELETED,
+	constants.EVENT_VERSION_DEPLOYED,
+	constants.EVENT_CHANNEL_MOVED,
+	constants.EVENT_CHANNEL_DELETED,
+}
+
+func IsValidEventType(eventType string) bool {
+	for _, validType := range EventTypes {
+		if validType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter narrows down the events delivered to a subscriber.
+// Empty fields match everything.
+type Filter struct {
+	ServiceName string
+	Types       []string
+}
+
+func (f Filter) Matches(event models.Event) bool {
+	if f.ServiceName != "" && f.ServiceName != event.ServiceName {
+		return false
+	}
+
+	if len(f.Types) == 0 {
+		return true
+	}
+
+	for _, eventType := range f.Types {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Position orders the events as the streams send them: by the transaction
+// which recorded them, then by id. Ids are drawn before transactions commit,
+// in another order, but a transaction is only streamed once all the ones
+// started before it are done, none coming before it afterwards.
+type Position struct {
+	Xid uint64
+	ID  uint
+}
+
+func PositionOf(event models.Event) Position {
+	return Position{Xid: event.Xid, ID: event.ID}
+}
+
+func (p Position) After(other Position) bool {
+	if p.Xid != other.Xid {
+		return p.Xid > other.Xid
+	}
+	return p.ID > other.ID
+}
+
+type Subscription struct {
+	// C is closed when the subscription ends, either on Unsubscribe or
+	// because the subscriber fell too far behind.
+	C      <-chan models.Event
+	ch     chan models.Event
+	filter Filter
+}
+
+// Broker fans out published events to all matching subscribers. Events are
+// published by a single dispatcher, which is notified of the recorded ones.
+type Broker struct {
+	mu            sync.Mutex
+	subscribers   map[*Subscription]struct{}
+	notifications chan struct{}
+	closed        bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers:   make(map[*Subscription]struct{}),
+		notifications: make(chan struct{}, 1),
+	}
+}
+
+func (b *Broker) Subscribe(filter Filter) *Subscription {
+	ch := make(chan models.Event, SUBSCRIBER_BUFFER_SIZE)
+	subscription := &Subscription{C: ch, ch: ch, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return subscription
+	}
+	b.subscribers[subscription] = struct{}{}
+
+	return subscription
+}
+
+func (b *Broker) Unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(subscription)
+}
+
+func (b *Broker) Publish(event models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscription := range b.subscribers {
+		if !subscription.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case subscription.ch <- event:
+		default:
+			// Never block publishers on a slow consumer. Dropping it closes the
+			// channel so that the client reconnects with its Last-Event-ID.
+			b.remove(subscription)
+		}
+	}
+}
+
+// Notify tells the dispatcher that events were recorded. Notifications sent
+// while it is busy are merged into one.
+func (b *Broker) Notify() {
+	select {
+	case b.notifications <- struct{}{}:
+	default:
+	}
+}
+
+func (b *Broker) Notifications() <-chan struct{} {
+	return b.notifications
+}
+
+// Close ends every subscription, current and future, for streams not to hold
+// the shutdown up. Subscribers resume elsewhere from their last event.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for subscription := range b.subscribers {
+		b.remove(subscription)
+	}
+}
+
+// Closed tells whether subscriptions end because the broker was closed,
+// rather than for being too slow
+func (b *Broker) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
+
+func (b *Broker) remove(subscription *Subscription) {
+	if _, ok := b.subscribers[subscription]; !ok {
+		return
+	}
+
+	delete(b.subscribers, subscription)
+	close(subscription.ch)
+}
+
+var defaultBroker = NewBroker()
+
+func Subscribe(filter Filter) *Subscription {
+	return defaultBroker.Subscribe(filter)
+}
+
+func Unsubscribe(subscription *Subscription) {
+	defaultBroker.Unsubscribe(subscription)
+}
+
+func Publish(event models.Event) {
+	defaultBroker.Publish(event)
+}
+
+func Notify() {
+	defaultBroker.Notify()
+}
+
+func Notifications() <-chan struct{} {
+	return defaultBroker.Notifications()
+}
+
+func Close() {
+	defaultBroker.Close()
+}
+
+func Closed() bool {
+	return defaultBroker.Closed()
+}
+
+func ToResponse(event models.Event) api.EventResponse {
+	data := json.RawMessage(event.Data)
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+
+	return api.EventResponse{
+		ID:          event.ID,
+		Type:        event.Type,
+		ServiceName: event.ServiceName,
+		VersionName: event.VersionName,
+		Data:        data,
+		CreatedAt:   event.CreatedAt,
+	}
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"testing"
+
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker_PublishMatchingFilter(t *testing.T) {
+	broker := NewBroker()
+
+	all := broker.Subscribe(Filter{})
+	byService := broker.Subscribe(Filter{ServiceName: "payments"})
+	byType := broker.Subscribe(Filter{Types: []string{constants.EVENT_VERSION_CREATED}})
+
+	broker.Publish(models.Event{ID: 1, Type: constants.EVENT_SERVICE_CREATED, ServiceName: "payments"})
+	broker.Publish(models.Event{ID: 2, Type: constants.EVENT_VERSION_CREATED, ServiceName: "orders"})
+
+	assert.Len(t, all.C, 2)
+	assert.Len(t, byService.C, 1)
+	assert.Len(t, byType.C, 1)
+
+	assert.Equal(t, uint(1), (<-byService.C).ID)
+	assert.Equal(t, uint(2), (<-byType.C).ID)
+}
+
+func TestBroker_Unsubscribe(t *testing.T) {
+	broker := NewBroker()
+
+	subscription := broker.Subscribe(Filter{})
+	broker.Unsubscribe(subscription)
+	// unsubscribing twice must not panic on a closed channel
+	broker.Unsubscribe(subscription)
+
+	broker.Publish(models.Event{ID: 1, Type: constants.EVENT_SERVICE_CREATED, ServiceName: "payments"})
+
+	_, ok := <-subscription.C
+	assert.False(t, ok)
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+
+	subscription := broker.Subscribe(Filter{})
+	for i := 1; i <= SUBSCRIBER_BUFFER_SIZE+1; i++ {
+		broker.Publish(models.Event{ID: uint(i), Type: constants.EVENT_SERVICE_CREATED, ServiceName: "payments"})
+	}
+
+	received := 0
+	for range subscription.C {
+		received++
+	}
+
+	assert.Equal(t, SUBSCRIBER_BUFFER_SIZE, received)
+	assert.Empty(t, broker.subscribers)
+}
+
+func TestPosition_After(t *testing.T) {
+	// transactions order the events before their ids do
+	assert.True(t, Position{Xid: 8, ID: 1}.After(Position{Xid: 7, ID: 2}))
+	assert.True(t, Position{Xid: 7, ID: 3}.After(Position{Xid: 7, ID: 2}))
+	assert.False(t, Position{Xid: 7, ID: 2}.After(Position{Xid: 7, ID: 2}))
+}
+
+func TestBroker_NotificationsAreMerged(t *testing.T) {
+	broker := NewBroker()
+
+	broker.Notify()
+	broker.Notify()
+
+	assert.Len(t, broker.Notifications(), 1)
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := NewBroker()
+
+	before := broker.Subscribe(Filter{})
+	broker.Close()
+	after := broker.Subscribe(Filter{})
+	broker.Unsubscribe(after)
+
+	_, ok := <-before.C
+	assert.False(t, ok)
+	_, ok = <-after.C
+	assert.False(t, ok)
+	assert.Empty(t, broker.subscribers)
+}
@@ -0,0 +1,149 @@
+// Package health answers the liveness and readiness probes of the
+// orchestrator, readiness depending on the checks registered by main and on
+// the process not draining before shutdown
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	LIVE_PATH  = "/healthz/live"
+	READY_PATH = "/healthz/ready"
+
+	STATUS_OK        = "ok"
+	STATUS_NOT_READY = "not ready"
+	STATUS_DRAINING  = "draining"
+
+	// each check has to answer within it, for probes not to time out
+	CHECK_TIMEOUT = 2 * time.Second
+)
+
+// Check fails when a dependency the process needs to serve requests is not
+// usable
+type Check func(ctx context.Context) error
+
+var (
+	checksMutex sync.RWMutex
+	checks      = map[string]Check{}
+
+	draining atomic.Bool
+)
+
+// Register adds a check to readiness, replacing the one with the same name
+func Register(name string, check Check) {
+	checksMutex.Lock()
+	defer checksMutex.Unlock()
+
+	checks[name] = check
+}
+
+// Drain makes readiness fail from now on, for load balancers to stop sending
+// requests before the process shuts down
+func Drain() {
+	draining.Store(true)
+}
+
+// Live tells that the process is running, whatever its dependencies
+func Live(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, api.HealthResponse{
+		Status: STATUS_OK,
+	})
+}
+
+// Ready tells whether requests can be sent to the process, with the outcome
+// of each check
+func Ready(ctx echo.Context) error {
+	response := api.HealthResponse{
+		Status: STATUS_OK,
+		Checks: map[string]string{},
+	}
+
+	checksMutex.RLock()
+	registered := make(map[string]Check, len(checks))
+	names := make([]string, 0, len(checks))
+	for name, check := range checks {
+		registered[name] = check
+		names = append(names, name)
+	}
+	checksMutex.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		check := registered[name]
+
+		checkCtx, cancel := context.WithTimeout(ctx.Request().Context(), CHECK_TIMEOUT)
+		err := check(checkCtx)
+		cancel()
+
+		if err != nil {
+			response.Status = STATUS_NOT_READY
+			response.Checks[name] = err.Error()
+			continue
+		}
+		response.Checks[name] = STATUS_OK
+	}
+
+	if draining.Load() {
+		response.Status = STATUS_DRAINING
+	}
+
+	if response.Status != STATUS_OK {
+		return ctx.JSON(http.StatusServiceUnavailable, response)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// DBCheck fails when the database can not be reached
+func DBCheck(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// MigrationsCheck fails until the database is migrated to the latest
+// migration shipped
+func MigrationsCheck(db *gorm.DB, latest int) Check {
+	return func(ctx context.Context) error {
+		var applied *int
+		if err := db.WithContext(ctx).Raw(`SELECT MAX(version) FROM schema_migrations`).Scan(&applied).Error; err != nil {
+			return fmt.Errorf("migrations pending, up to %d: %w", latest, err)
+		}
+
+		if applied == nil || *applied < latest {
+			return fmt.Errorf("migrations pending, up to %d", latest)
+		}
+
+		return nil
+	}
+}
+
+// ServerCheck fails until the server listens, e.g. the metrics server. It
+// does not dial it, for a TLS server not to log a failed handshake on every
+// probe.
+func ServerCheck(name string, server *echo.Echo) Check {
+	return func(ctx context.Context) error {
+		if server.ListenerAddr() == nil && server.TLSListenerAddr() == nil {
+			return fmt.Errorf("%s server not listening", name)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func resetChecks(t *testing.T) {
+	t.Cleanup(func() {
+		checks = map[string]Check{}
+		draining.Store(false)
+	})
+}
+
+func probe(t *testing.T, handler echo.HandlerFunc) (int, api.HealthResponse) {
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, READY_PATH, nil), recorder)
+	require.NoError(t, handler(ctx))
+
+	var response api.HealthResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+
+	return recorder.Code, response
+}
+
+func TestReady(t *testing.T) {
+	resetChecks(t)
+	Register("db", func(ctx context.Context) error { return nil })
+
+	code, response := probe(t, Ready)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, api.HealthResponse{Status: STATUS_OK, Checks: map[string]string{"db": STATUS_OK}}, response)
+
+	Register("metrics", func(ctx context.Context) error { return errors.New("metrics server not listening") })
+
+	code, response = probe(t, Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, STATUS_NOT_READY, response.Status)
+	assert.Equal(t, "metrics server not listening", response.Checks["metrics"])
+}
+
+func TestReady_Draining(t *testing.T) {
+	resetChecks(t)
+	Register("db", func(ctx context.Context) error { return nil })
+
+	Drain()
+
+	code, response := probe(t, Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, STATUS_DRAINING, response.Status)
+
+	// the process keeps living while draining
+	code, _ = probe(t, Live)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestMigrationsCheck(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+
+	query := regexp.QuoteMeta(`SELECT MAX(version) FROM schema_migrations`)
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(10))
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(9))
+	mock.ExpectQuery(query).WillReturnError(errors.New(`relation "schema_migrations" does not exist`))
+
+	check := MigrationsCheck(db, 10)
+	assert.NoError(t, check(context.Background()))
+	assert.EqualError(t, check(context.Background()), "migrations pending, up to 10")
+	assert.Error(t, check(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestServerCheck(t *testing.T) {
+	server := echo.New()
+	server.HideBanner = true
+	server.HidePort = true
+
+	check := ServerCheck("metrics", server)
+	assert.EqualError(t, check(context.Background()), "metrics server not listening")
+
+	go server.Start("127.0.0.1:0")
+	t.Cleanup(func() { server.Close() })
+
+	assert.Eventually(t, func() bool { return check(context.Background()) == nil }, time.Second, 10*time.Millisecond)
+}
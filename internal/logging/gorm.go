@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// GormLogger logs the queries with the context they ran with, for them to
+// carry the request and trace IDs. Failed queries are errors, slow ones
+// warnings and the others debug records. The values of the queries are left
+// out, holding the data of the users.
+type GormLogger struct {
+	// queries taking longer are slow
+	SlowThreshold time.Duration
+}
+
+func NewGormLogger(slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{SlowThreshold: slowThreshold}
+}
+
+// LogMode is a no-op, the level being the one of slog
+func (l *GormLogger) LogMode(gormLogger.LogLevel) gormLogger.Interface {
+	return l
+}
+
+func (l *GormLogger) Info(ctx context.Context, message string, data ...interface{}) {
+	slog.InfoContext(ctx, fmt.Sprintf(message, data...))
+}
+
+func (l *GormLogger) Warn(ctx context.Context, message string, data ...interface{}) {
+	slog.WarnContext(ctx, fmt.Sprintf(message, data...))
+}
+
+func (l *GormLogger) Error(ctx context.Context, message string, data ...interface{}) {
+	slog.ErrorContext(ctx, fmt.Sprintf(message, data...))
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+
+	var level slog.Level
+	var message string
+	switch {
+	// lookups of missing records are answered with a 404
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, message = slog.LevelError, "query failed"
+	case elapsed > l.SlowThreshold:
+		level, message = slog.LevelWarn, "slow query"
+	default:
+		level, message = slog.LevelDebug, "query"
+	}
+
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("latency", elapsed),
+	}
+	if level == slog.LevelError {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	slog.LogAttrs(ctx, level, message, attrs...)
+}
+
+// ParamsFilter leaves the values of the queries out of their SQL
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,168 @@
+// Package logging writes the logs of the service as JSON with log/slog, to
+// stdout or to a file rotated by size. Records logged with the context of a
+// request carry its request and trace IDs, and secrets are redacted.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	REQUEST_ID_KEY = "request_id"
+	TRACE_ID_KEY   = "trace_id"
+
+	// logged in place of the values of secrets
+	REDACTED = config.REDACTED
+
+	// above every level, for nothing to be logged
+	LEVEL_OFF = slog.Level(16)
+)
+
+// keys of the attributes whose values are never logged, lower case, e.g. of
+// request headers
+var secretKeys = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+	"password":            true,
+	"secret":              true,
+	"token":               true,
+	"dsn":                 true,
+}
+
+var levels = map[string]slog.Level{
+	config.LOG_LEVEL_DEBUG: slog.LevelDebug,
+	config.LOG_LEVEL_INFO:  slog.LevelInfo,
+	config.LOG_LEVEL_WARN:  slog.LevelWarn,
+	config.LOG_LEVEL_ERROR: slog.LevelError,
+	config.LOG_LEVEL_OFF:   LEVEL_OFF,
+}
+
+// shared by the loggers, for reloads to change the level of all of them
+var level = new(slog.LevelVar)
+
+// Init makes the logger of the configuration the default one, of the log
+// package too. The returned closer closes the file logged to, if any.
+func Init(logConfig *config.LogConfig) (io.Closer, error) {
+	writer, err := newWriter(logConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	SetLevel(logConfig.Level)
+	slog.SetDefault(New(writer))
+
+	return writer, nil
+}
+
+// New logs to w as JSON, at the level currently configured
+func New(w io.Writer) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})})
+}
+
+// SetLevel changes the level of the logs, e.g. on reload
+func SetLevel(name string) {
+	level.Set(levels[name])
+}
+
+func newWriter(logConfig *config.LogConfig) (io.WriteCloser, error) {
+	if logConfig.Output != config.LOG_OUTPUT_FILE {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	// the file is opened on the first write, its errors would go unnoticed
+	if err := os.MkdirAll(filepath.Dir(logConfig.Path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(logConfig.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	return &lumberjack.Logger{
+		Filename:   logConfig.Path,
+		MaxSize:    logConfig.MaxSize,
+		MaxBackups: logConfig.MaxBackups,
+	}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if secretKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, REDACTED)
+	}
+
+	return attr
+}
+
+// contextHandler adds the request and trace IDs of the context of each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String(REQUEST_ID_KEY, requestID))
+	}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		record.AddAttrs(slog.String(TRACE_ID_KEY, traceID))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID is ctx, for the records logged with it to carry requestID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID is the ID of the request ctx is part of, empty when none
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// NewRequestID identifies a request whose caller sent no ID
+func NewRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,140 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// capture makes the default logger write to the returned buffer, at level
+func capture(t *testing.T, level string) *bytes.Buffer {
+	var buffer bytes.Buffer
+
+	previous := slog.Default()
+	SetLevel(level)
+	slog.SetDefault(New(&buffer))
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		SetLevel(config.DEFAULT_LOG_LEVEL)
+	})
+
+	return &buffer
+}
+
+// records decodes the JSON lines logged
+func records(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var logged []map[string]interface{}
+
+	decoder := json.NewDecoder(buffer)
+	for decoder.More() {
+		var record map[string]interface{}
+		require.NoError(t, decoder.Decode(&record))
+		logged = append(logged, record)
+	}
+
+	return logged
+}
+
+func TestNew_CarriesTheIDsOfTheRequest(t *testing.T) {
+	buffer := capture(t, config.LOG_LEVEL_INFO)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = WithRequestID(ctx, "caller-request-id")
+
+	slog.InfoContext(ctx, "served")
+	slog.Info("outside of a request")
+
+	logged := records(t, buffer)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "caller-request-id", logged[0][REQUEST_ID_KEY])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", logged[0][TRACE_ID_KEY])
+	assert.NotContains(t, logged[1], REQUEST_ID_KEY)
+	assert.NotContains(t, logged[1], TRACE_ID_KEY)
+}
+
+func TestNew_RedactsSecrets(t *testing.T) {
+	buffer := capture(t, config.LOG_LEVEL_INFO)
+
+	slog.Info("request", slog.Group("headers",
+		slog.String("Authorization", "Bearer valid-key"),
+		slog.String("Accept", "application/json"),
+	), "password", "mysecretpassword")
+
+	logged := records(t, buffer)
+	require.Len(t, logged, 1)
+	assert.Equal(t, map[string]interface{}{"Authorization": REDACTED, "Accept": "application/json"}, logged[0]["headers"])
+	assert.Equal(t, REDACTED, logged[0]["password"])
+	assert.NotContains(t, buffer.String(), "valid-key")
+}
+
+func TestSetLevel(t *testing.T) {
+	buffer := capture(t, config.LOG_LEVEL_WARN)
+
+	slog.Info("left out")
+	slog.Warn("logged")
+	assert.Len(t, records(t, buffer), 1)
+
+	SetLevel(config.LOG_LEVEL_OFF)
+	slog.Error("left out")
+	assert.Empty(t, records(t, buffer))
+}
+
+func TestInit_File(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	path := filepath.Join(t.TempDir(), "logs", "log_file")
+	closer, err := Init(&config.LogConfig{
+		Output:     config.LOG_OUTPUT_FILE,
+		Path:       path,
+		MaxSize:    1,
+		MaxBackups: 1,
+		Level:      config.LOG_LEVEL_INFO,
+	})
+	require.NoError(t, err)
+
+	slog.Info("logged to the file")
+	require.NoError(t, closer.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"logged to the file"`)
+}
+
+func TestGormLogger_Trace(t *testing.T) {
+	buffer := capture(t, config.LOG_LEVEL_INFO)
+	logger := NewGormLogger(100 * time.Millisecond)
+	ctx := WithRequestID(context.Background(), "caller-request-id")
+	sql := func() (string, int64) { return `SELECT * FROM "services" WHERE name = $1`, 1 }
+
+	logger.Trace(ctx, time.Now(), sql, nil)
+	logger.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)
+	logger.Trace(ctx, time.Now().Add(-time.Second), sql, nil)
+	logger.Trace(ctx, time.Now(), sql, errors.New("connection reset"))
+
+	logged := records(t, buffer)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "slow query", logged[0]["msg"])
+	assert.Equal(t, "WARN", logged[0]["level"])
+	assert.Equal(t, "query failed", logged[1]["msg"])
+	assert.Equal(t, "connection reset", logged[1]["error"])
+	assert.Equal(t, `SELECT * FROM "services" WHERE name = $1`, logged[1]["sql"])
+	assert.Equal(t, "caller-request-id", logged[1][REQUEST_ID_KEY])
+}
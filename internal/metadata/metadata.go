@@ -0,0 +1,214 @@
+package metadata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const (
+	// attribute filters of GET /v1/services, e.g. attributes[team.name]=payments
+	ATTRIBUTE_FILTER_PREFIX  = "attributes["
+	ATTRIBUTE_FILTER_SUFFIX  = "]"
+	ATTRIBUTE_PATH_SEPARATOR = "."
+
+	ATTRIBUTES_SCHEMA_URL = "attributes.schema.json"
+)
+
+var LinkTypes = []string{
+	api.LINK_REPOSITORY,
+	api.LINK_RUNBOOK,
+	api.LINK_DASHBOARD,
+	api.LINK_DOCS,
+}
+
+var attributePathSegment = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ErrInvalidAttributes is wrapped by the errors of attributes not matching the
+// schema
+var ErrInvalidAttributes = errors.New("invalid attributes")
+
+var (
+	schemaMutex      sync.RWMutex
+	attributesSchema *jsonschema.Schema
+)
+
+// LoadAttributesSchema reads the JSON Schema that attributes must match from
+// path. An empty path accepts any attributes.
+func LoadAttributesSchema(path string) error {
+	if path == "" {
+		return SetAttributesSchema(nil)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return SetAttributesSchema(data)
+}
+
+// SetAttributesSchema compiles and applies a JSON Schema, nil removes it
+func SetAttributesSchema(data []byte) error {
+	var schema *jsonschema.Schema
+
+	if data != nil {
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(ATTRIBUTES_SCHEMA_URL, bytes.NewReader(data)); err != nil {
+			return err
+		}
+
+		var err error
+		if schema, err = compiler.Compile(ATTRIBUTES_SCHEMA_URL); err != nil {
+			return err
+		}
+	}
+
+	schemaMutex.Lock()
+	attributesSchema = schema
+	schemaMutex.Unlock()
+
+	return nil
+}
+
+// Validate checks the metadata sent to create or update a service. Attributes
+// left out are kept by an update, and checked as stored by the controllers
+// otherwise.
+func Validate(request api.ServiceRequest) error {
+	if err := ValidateLinks(request.Links); err != nil {
+		return err
+	}
+
+	if err := ValidateContacts(request.Contacts); err != nil {
+		return err
+	}
+
+	if request.Attributes == nil {
+		return nil
+	}
+
+	return ValidateAttributes(request.Attributes)
+}
+
+func ValidateLinks(links []api.ServiceLink) error {
+	for _, link := range links {
+		if !isLinkType(link.Type) {
+			return fmt.Errorf("invalid link type %q, expected one of %s", link.Type, strings.Join(LinkTypes, ", "))
+		}
+
+		parsed, err := url.Parse(link.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid %s link %q, expected an absolute http(s) URL", link.Type, link.URL)
+		}
+	}
+
+	return nil
+}
+
+func ValidateContacts(contacts []api.ServiceContact) error {
+	for _, contact := range contacts {
+		if strings.TrimSpace(contact.Name) == "" {
+			return errors.New("invalid contact, name is required")
+		}
+
+		if _, err := mail.ParseAddress(contact.Email); err != nil {
+			return fmt.Errorf("invalid email %q for contact %q", contact.Email, contact.Name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAttributes checks the attributes of a service against the schema,
+// nil standing for none
+func ValidateAttributes(attributes map[string]interface{}) error {
+	schemaMutex.RLock()
+	schema := attributesSchema
+	schemaMutex.RUnlock()
+
+	if schema == nil {
+		return nil
+	}
+
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+
+	if err := schema.Validate(attributes); err != nil {
+		var validationError *jsonschema.ValidationError
+		if errors.As(err, &validationError) {
+			return fmt.Errorf("%w: %s", ErrInvalidAttributes, leafError(validationError))
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidAttributes, err)
+	}
+
+	return nil
+}
+
+// ParseAttributeFilters picks the attributes[path]=value filters out of a
+// query, keyed by the path segments joined with ATTRIBUTE_PATH_SEPARATOR
+func ParseAttributeFilters(query url.Values) (map[string]string, error) {
+	filters := map[string]string{}
+
+	for key, values := range query {
+		if !strings.HasPrefix(key, ATTRIBUTE_FILTER_PREFIX) || !strings.HasSuffix(key, ATTRIBUTE_FILTER_SUFFIX) {
+			continue
+		}
+
+		path := strings.TrimSuffix(strings.TrimPrefix(key, ATTRIBUTE_FILTER_PREFIX), ATTRIBUTE_FILTER_SUFFIX)
+		for _, segment := range strings.Split(path, ATTRIBUTE_PATH_SEPARATOR) {
+			if !attributePathSegment.MatchString(segment) {
+				return nil, fmt.Errorf("invalid attribute path %q", path)
+			}
+		}
+
+		filters[path] = values[0]
+	}
+
+	return filters, nil
+}
+
+// SortedPaths returns the paths of the filters in a stable order
+func SortedPaths(filters map[string]string) []string {
+	paths := make([]string, 0, len(filters))
+	for path := range filters {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+func isLinkType(linkType string) bool {
+	for _, validType := range LinkTypes {
+		if validType == linkType {
+			return true
+		}
+	}
+	return false
+}
+
+// leafError reports the most specific cause, which is what users need to fix
+func leafError(err *jsonschema.ValidationError) string {
+	for len(err.Causes) > 0 {
+		err = err.Causes[0]
+	}
+
+	location := err.InstanceLocation
+	if location == "" {
+		location = "/"
+	}
+
+	return fmt.Sprintf("%s: %s", location, err.Message)
+}
@@ -0,0 +1,77 @@
+package metadata
+
+import (
+	"net/url"
+	"testing"
+
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/structs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLinks(t *testing.T) {
+	assert.NoError(t, ValidateLinks([]api.ServiceLink{
+		{Type: api.LINK_REPOSITORY, URL: "https://git.example.com/payments"},
+		{Type: api.LINK_DASHBOARD, URL: "http://grafana.internal/d/payments", Title: "Latency"},
+	}))
+
+	assert.ErrorContains(t, ValidateLinks([]api.ServiceLink{{Type: "wiki", URL: "https://wiki.example.com"}}), `invalid link type "wiki"`)
+	assert.ErrorContains(t, ValidateLinks([]api.ServiceLink{{Type: api.LINK_RUNBOOK, URL: "/runbooks/payments"}}), "absolute http(s) URL")
+	assert.ErrorContains(t, ValidateLinks([]api.ServiceLink{{Type: api.LINK_DOCS, URL: "ftp://docs.example.com"}}), "absolute http(s) URL")
+}
+
+func TestValidateContacts(t *testing.T) {
+	assert.NoError(t, ValidateContacts([]api.ServiceContact{{Name: "Payments team", Email: "payments@example.com", Role: "owner"}}))
+
+	assert.ErrorContains(t, ValidateContacts([]api.ServiceContact{{Email: "payments@example.com"}}), "name is required")
+	assert.ErrorContains(t, ValidateContacts([]api.ServiceContact{{Name: "Payments team", Email: "payments"}}), `invalid email "payments"`)
+}
+
+func TestValidateAttributes(t *testing.T) {
+	defer SetAttributesSchema(nil)
+
+	// anything goes without a schema
+	assert.NoError(t, ValidateAttributes(map[string]interface{}{"tier": 1}))
+
+	require.NoError(t, SetAttributesSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"tier": {"enum": ["critical", "standard"]}
+		},
+		"required": ["tier"]
+	}`)))
+
+	assert.NoError(t, ValidateAttributes(map[string]interface{}{"tier": "critical", "team": "payments"}))
+	assert.ErrorContains(t, ValidateAttributes(map[string]interface{}{"tier": "best-effort"}), "invalid attributes: /tier")
+	assert.ErrorContains(t, ValidateAttributes(map[string]interface{}{}), "invalid attributes")
+
+	// no attributes are stored as none, which misses the required ones
+	assert.ErrorIs(t, ValidateAttributes(nil), ErrInvalidAttributes)
+
+	// but attributes left out of an update are kept
+	assert.NoError(t, Validate(api.ServiceRequest{}))
+}
+
+func TestSetAttributesSchema_Invalid(t *testing.T) {
+	defer SetAttributesSchema(nil)
+
+	assert.Error(t, SetAttributesSchema([]byte(`{"type": 1}`)))
+	assert.Error(t, SetAttributesSchema([]byte(`not json`)))
+}
+
+func TestParseAttributeFilters(t *testing.T) {
+	filters, err := ParseAttributeFilters(url.Values{
+		"attributes[tier]":      {"critical"},
+		"attributes[team.name]": {"payments"},
+		"name":                  {"pay"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"tier": "critical", "team.name": "payments"}, filters)
+	assert.Equal(t, []string{"team.name", "tier"}, SortedPaths(filters))
+
+	_, err = ParseAttributeFilters(url.Values{"attributes[team..name]": {"payments"}})
+	assert.Error(t, err)
+
+	_, err = ParseAttributeFilters(url.Values{"attributes[team'name]": {"payments"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,17 @@
+// internal/models/artifact.go
+package models
+
+import (
+	"time"
+)
+
+// Artifact is something shipped as a version: a container image, a binary
+// or a commit. Digest is indexed to find the version an artifact belongs to.
+type Artifact struct {
+	ID        uint      `gorm:"primaryKey"`
+	VersionID uint      `gorm:"not null;index"`
+	Type      string    `gorm:"not null"`
+	Reference string    `gorm:"default:null"`
+	Digest    string    `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+}
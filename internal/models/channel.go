@@ -0,0 +1,27 @@
+// internal/models/channel.go
+package models
+
+import (
+	"time"
+)
+
+// Channel is a movable name, e.g. stable, pointing to a version of a service
+type Channel struct {
+	ID        uint      `gorm:"primaryKey"`
+	ServiceID uint      `gorm:"not null;index"`
+	Name      string    `gorm:"not null"`
+	VersionID uint      `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// ChannelMove records a channel being pointed to another version. FromVersionID
+// is nil when the channel was created and ToVersionID when it was deleted.
+type ChannelMove struct {
+	ID            uint      `gorm:"primaryKey"`
+	ServiceID     uint      `gorm:"not null"`
+	Channel       string    `gorm:"not null"`
+	FromVersionID *uint     `gorm:"default:null"`
+	ToVersionID   *uint     `gorm:"default:null"`
+	MovedAt       time.Time `gorm:"not null"`
+}
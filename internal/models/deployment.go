@@ -0,0 +1,18 @@
+// internal/models/deployment.go
+package models
+
+import (
+	"time"
+)
+
+// Deployment records a version of a service being deployed to an environment.
+// Deployments are never updated, the latest one of a service to an
+// environment tells the version running there.
+type Deployment struct {
+	ID            uint      `gorm:"primaryKey"`
+	ServiceID     uint      `gorm:"not null;index"`
+	VersionID     uint      `gorm:"not null"`
+	EnvironmentID uint      `gorm:"not null"`
+	Actor         string    `gorm:"not null"`
+	DeployedAt    time.Time `gorm:"not null"`
+}
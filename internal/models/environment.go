@@ -0,0 +1,13 @@
+// internal/models/environment.go
+package models
+
+import (
+	"time"
+)
+
+type Environment struct {
+	ID          uint      `gorm:"primaryKey"`
+	Name        string    `gorm:"not null;unique"`
+	Description string    `gorm:"default:null"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
@@ -0,0 +1,20 @@
+// internal/models/event.go
+package models
+
+import (
+	"time"
+)
+
+// Event is a single entry of the catalog change log. Its ID is the
+// persisted sequence used by stream consumers to resume. Xid is the
+// transaction which recorded it, set by the database, which orders the
+// events streamed.
+type Event struct {
+	ID          uint      `gorm:"primaryKey"`
+	Xid         uint64    `gorm:"->"`
+	Type        string    `gorm:"not null;index"`
+	ServiceName string    `gorm:"not null;index"`
+	VersionName string    `gorm:"default:null"`
+	Data        string    `gorm:"type:jsonb"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
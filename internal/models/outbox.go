@@ -0,0 +1,24 @@
+// internal/models/outbox.go
+package models
+
+import (
+	"time"
+)
+
+// OutboxMessage is written in the same transaction as the mutation it
+// describes and relayed to the configured sinks afterwards.
+type OutboxMessage struct {
+	ID            uint       `gorm:"primaryKey"`
+	EventID       uint       `gorm:"not null;index"`
+	Type          string     `gorm:"not null"`
+	Payload       string     `gorm:"type:jsonb;not null"`
+	Attempts      int        `gorm:"default:0"`
+	LastError     string     `gorm:"default:null"`
+	CreatedAt     time.Time  `gorm:"not null"`
+	NextAttemptAt time.Time  `gorm:"not null"`
+	PublishedAt   *time.Time `gorm:"default:null"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
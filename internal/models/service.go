@@ -12,7 +12,12 @@ type Service struct {
 	Name         string         `gorm:"not null;unique"`
 	Description  string         `gorm:"type:text"`
 	CreatedAt    time.Time      `gorm:"not null"`
+	UpdatedAt    time.Time      `gorm:"not null"`
 	DeletedAt    gorm.DeletedAt `gorm:"default:null"`
 	VersionCount int            `gorm:"default:0"`
+	Revision     int            `gorm:"default:0"` // latest of the service's revisions
+	Links        string         `gorm:"type:jsonb;not null"`
+	Contacts     string         `gorm:"type:jsonb;not null"`
+	Attributes   string         `gorm:"type:jsonb;not null"`
 	Versions     []Version      `gorm:"foreignKey:ServiceID;references:ID"`
 }
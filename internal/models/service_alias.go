@@ -0,0 +1,15 @@
+// internal/models/service_alias.go
+package models
+
+import (
+	"time"
+)
+
+// ServiceAlias is a previous name of a renamed service, which keeps resolving
+// to it
+type ServiceAlias struct {
+	ID        uint      `gorm:"primaryKey"`
+	ServiceID uint      `gorm:"not null;index"`
+	Name      string    `gorm:"not null;unique"`
+	CreatedAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,18 @@
+// internal/models/service_revision.go
+package models
+
+import (
+	"time"
+)
+
+// ServiceRevision is written every time the name, description or metadata of
+// a service change. Changes holds the field-level diff with the previous
+// revision and Snapshot the service as it looked after the change.
+type ServiceRevision struct {
+	ID        uint      `gorm:"primaryKey"`
+	ServiceID uint      `gorm:"not null;index"`
+	Revision  int       `gorm:"not null"`
+	Changes   string    `gorm:"type:jsonb;not null"`
+	Snapshot  string    `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
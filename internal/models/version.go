@@ -8,11 +8,13 @@ import (
 )
 
 type Version struct {
-	ID          uint           `gorm:"primaryKey"`
-	ServiceID   uint           `gorm:"not null;index"`
-	Name        string         `gorm:"not null"`
-	CreatedAt   time.Time      `gorm:"not null"`
-	DeletedAt   gorm.DeletedAt `gorm:"default:null"`
-	Description string         `gorm:"default:null"`
-	Service     *Service       `gorm:"foreignKey:ServiceID;references:ID"`
+	ID           uint           `gorm:"primaryKey"`
+	ServiceID    uint           `gorm:"not null;index"`
+	Name         string         `gorm:"not null"`
+	CreatedAt    time.Time      `gorm:"not null"`
+	DeletedAt    gorm.DeletedAt `gorm:"default:null"`
+	Description  string         `gorm:"default:null"`
+	ReleaseNotes string         `gorm:"type:jsonb;not null"` // api.ReleaseNotes, as JSON
+	Service      *Service       `gorm:"foreignKey:ServiceID;references:ID"`
+	Artifacts    []Artifact     `gorm:"foreignKey:VersionID;references:ID"`
 }
@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// registered with the default registry, which is what the metrics server exposes
+var (
+	pendingMessages = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "outbox",
+		Name:      "pending_messages",
+		Help:      "Number of outbox messages not published yet.",
+	})
+
+	outboxLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "outbox",
+		Name:      "lag_seconds",
+		Help:      "Age of the oldest outbox message not published yet.",
+	})
+
+	publishedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "outbox",
+		Name:      "published_total",
+		Help:      "Number of outbox messages published, per sink.",
+	}, []string{"sink"})
+
+	publishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "outbox",
+		Name:      "publish_failures_total",
+		Help:      "Number of failed attempts to publish an outbox message, per sink.",
+	}, []string{"sink"})
+)
@@ -0,0 +1,207 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// failed messages are retried with an exponential backoff capped at this value
+	MAX_RETRY_BACKOFF = 5 * time.Minute
+
+	// claimed messages are not claimed again by other relays for this long
+	MESSAGE_LEASE = time.Minute
+)
+
+// Relay publishes pending outbox messages to all sinks. A message is marked as
+// published only once every sink accepted it, which gives at-least-once delivery.
+type Relay struct {
+	db           *gorm.DB
+	sinks        []Sink
+	pollInterval time.Duration
+	batchSize    int
+}
+
+func NewRelay(db *gorm.DB, sinks []Sink, outboxConfig *config.OutboxConfig) *Relay {
+	return &Relay{
+		db:           db,
+		sinks:        sinks,
+		pollInterval: outboxConfig.PollInterval,
+		batchSize:    outboxConfig.BatchSize,
+	}
+}
+
+// Run polls the outbox until the context is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// keep draining while full batches are being published
+		for {
+			published, err := r.ProcessBatch(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("error relaying outbox messages", "error", err)
+			}
+
+			if err != nil || published < r.batchSize {
+				break
+			}
+		}
+
+		if err := r.observeLag(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("error measuring outbox lag", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch publishes one batch of due messages and returns how many of them
+// were published. The batch is claimed in a short transaction which leases its
+// rows for MESSAGE_LEASE, so that several replicas can relay concurrently
+// without holding locks while the sinks are called. A relay stopping before the
+// outcomes are recorded leaves the messages to be published again once the
+// lease expires.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	leaseUntil := time.Now().Add(MESSAGE_LEASE)
+	messages, err := r.claim(ctx, leaseUntil)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	// messages not attempted within the lease are left to whoever claims them next
+	publishCtx, cancel := context.WithDeadline(ctx, leaseUntil)
+	defer cancel()
+
+	failures := make(map[uint]error, len(messages))
+	attempted := 0
+	for _, message := range messages {
+		if publishCtx.Err() != nil {
+			break
+		}
+		if err := r.publish(publishCtx, message); err != nil {
+			failures[message.ID] = err
+		}
+		attempted++
+	}
+
+	// published messages are recorded even when stopping, not to be published twice
+	err = r.db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		for _, message := range messages[:attempted] {
+			if err, failed := failures[message.ID]; failed {
+				attempts := message.Attempts + 1
+				if err := tx.Model(&message).Updates(map[string]interface{}{
+					"attempts":        attempts,
+					"last_error":      err.Error(),
+					"next_attempt_at": time.Now().Add(retryBackoff(attempts)),
+				}).Error; err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err := tx.Model(&message).Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return attempted - len(failures), nil
+}
+
+// claim locks the due messages, skipping those locked by other relays, and
+// pushes their next attempt to the end of the lease
+func (r *Relay) claim(ctx context.Context, leaseUntil time.Time) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("id ASC").
+			Limit(r.batchSize).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+		}
+
+		return tx.Model(&models.OutboxMessage{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", leaseUntil).Error
+	})
+
+	return messages, err
+}
+
+func (r *Relay) publish(ctx context.Context, message models.OutboxMessage) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, message); err != nil {
+			publishFailures.WithLabelValues(sink.Name()).Inc()
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+		publishedMessages.WithLabelValues(sink.Name()).Inc()
+	}
+
+	return nil
+}
+
+func (r *Relay) observeLag(ctx context.Context) error {
+	var pending struct {
+		Count  int64
+		Oldest *time.Time
+	}
+
+	if err := r.db.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Select("COUNT(*) AS count, MIN(created_at) AS oldest").
+		Where("published_at IS NULL").
+		Scan(&pending).Error; err != nil {
+		return err
+	}
+
+	pendingMessages.Set(float64(pending.Count))
+	if pending.Oldest == nil {
+		outboxLag.Set(0)
+	} else {
+		outboxLag.Set(time.Since(*pending.Oldest).Seconds())
+	}
+
+	return nil
+}
+
+func retryBackoff(attempts int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempts && backoff < MAX_RETRY_BACKOFF; i++ {
+		backoff *= 2
+	}
+
+	if backoff > MAX_RETRY_BACKOFF {
+		return MAX_RETRY_BACKOFF
+	}
+	return backoff
+}
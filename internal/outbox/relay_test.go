@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type fakeSink struct {
+	err       error
+	published []uint
+}
+
+func (s *fakeSink) Name() string {
+	return "fake"
+}
+
+func (s *fakeSink) Publish(_ context.Context, message models.OutboxMessage) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.published = append(s.published, message.EventID)
+	return nil
+}
+
+func initMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	gormMockDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return gormMockDB, mock
+}
+
+func expectPendingMessages(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE published_at IS NULL AND next_attempt_at <= $1 ORDER BY id ASC LIMIT $2 FOR UPDATE SKIP LOCKED`)).
+		WithArgs(sqlmock.AnyArg(), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "type", "payload", "attempts"}).
+			AddRow(1, 7, "service.created", `{"id":7}`, 0).
+			AddRow(2, 8, "service.deleted", `{"id":8}`, 2))
+	// leased before being published
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "next_attempt_at"=$1 WHERE id IN ($2,$3)`)).
+		WithArgs(sqlmock.AnyArg(), 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+}
+
+func TestProcessBatch_Published(t *testing.T) {
+	gormMockDB, mock := initMockDB(t)
+	sink := &fakeSink{}
+	relay := NewRelay(gormMockDB, []Sink{sink}, &config.OutboxConfig{PollInterval: time.Second, BatchSize: 10})
+
+	expectPendingMessages(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "published_at"=$1 WHERE "id" = $2`)).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "published_at"=$1 WHERE "id" = $2`)).
+		WithArgs(sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	published, err := relay.ProcessBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []uint{7, 8}, sink.published)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBatch_SinkFailureIsRetried(t *testing.T) {
+	gormMockDB, mock := initMockDB(t)
+	sink := &fakeSink{err: errors.New("connection refused")}
+	relay := NewRelay(gormMockDB, []Sink{sink}, &config.OutboxConfig{PollInterval: time.Second, BatchSize: 10})
+
+	expectPendingMessages(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "attempts"=$1,"last_error"=$2,"next_attempt_at"=$3 WHERE "id" = $4`)).
+		WithArgs(1, "fake sink: connection refused", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "attempts"=$1,"last_error"=$2,"next_attempt_at"=$3 WHERE "id" = $4`)).
+		WithArgs(3, "fake sink: connection refused", sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	published, err := relay.ProcessBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBatch_NothingDue(t *testing.T) {
+	gormMockDB, mock := initMockDB(t)
+	relay := NewRelay(gormMockDB, []Sink{&fakeSink{}}, &config.OutboxConfig{PollInterval: time.Second, BatchSize: 10})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE published_at IS NULL AND next_attempt_at <= $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "type", "payload", "attempts"}))
+	mock.ExpectCommit()
+
+	published, err := relay.ProcessBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, retryBackoff(1))
+	assert.Equal(t, 4*time.Second, retryBackoff(3))
+	assert.Equal(t, MAX_RETRY_BACKOFF, retryBackoff(50))
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		if r.Header.Get(EVENT_TYPE_HEADER) == "service.deleted" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+
+	err := sink.Publish(context.Background(), models.OutboxMessage{EventID: 7, Type: "service.created", Payload: `{}`})
+	assert.NoError(t, err)
+	assert.Equal(t, "7", received.Header.Get(EVENT_ID_HEADER))
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+
+	err = sink.Publish(context.Background(), models.OutboxMessage{EventID: 8, Type: "service.deleted", Payload: `{}`})
+	assert.EqualError(t, err, "webhook responded with status 503")
+}
+
+func TestNewSinks_Validation(t *testing.T) {
+	_, err := NewSinks(&config.OutboxConfig{Sinks: []string{WEBHOOK_SINK}})
+	assert.Error(t, err)
+
+	_, err = NewSinks(&config.OutboxConfig{Sinks: []string{"kafka"}})
+	assert.EqualError(t, err, `unknown outbox sink "kafka"`)
+
+	sinks, err := NewSinks(&config.OutboxConfig{Sinks: []string{STDOUT_SINK}})
+	assert.NoError(t, err)
+	assert.Len(t, sinks, 1)
+}
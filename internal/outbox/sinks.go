@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+)
+
+const (
+	STDOUT_SINK  = "stdout"
+	FILE_SINK    = "file"
+	WEBHOOK_SINK = "webhook"
+
+	WEBHOOK_TIMEOUT = 10 * time.Second
+
+	EVENT_ID_HEADER   = "X-Event-ID"
+	EVENT_TYPE_HEADER = "X-Event-Type"
+)
+
+// Sink receives every outbox message at least once. A message may be delivered
+// again if publishing it to any sink fails, so sinks should be idempotent on the
+// event id.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, message models.OutboxMessage) error
+}
+
+func NewSinks(outboxConfig *config.OutboxConfig) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range outboxConfig.Sinks {
+		switch name {
+		case STDOUT_SINK:
+			sinks = append(sinks, NewWriterSink(STDOUT_SINK, os.Stdout))
+
+		case FILE_SINK:
+			file, err := os.OpenFile(outboxConfig.FilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, NewWriterSink(FILE_SINK, file))
+
+		case WEBHOOK_SINK:
+			if outboxConfig.WebhookURL == "" {
+				return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for the %s sink", WEBHOOK_SINK)
+			}
+			sinks = append(sinks, NewWebhookSink(outboxConfig.WebhookURL))
+
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+
+	return sinks, nil
+}
+
+// WriterSink writes each message payload as a JSON line, used for stdout and files
+type WriterSink struct {
+	name   string
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewWriterSink(name string, writer io.Writer) *WriterSink {
+	return &WriterSink{name: name, writer: writer}
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Publish(_ context.Context, message models.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintln(s.writer, message.Payload)
+	return err
+}
+
+// WebhookSink POSTs each message payload to a URL, any non 2xx response is a failure
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: WEBHOOK_TIMEOUT},
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return WEBHOOK_SINK
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, message models.OutboxMessage) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewBufferString(message.Payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EVENT_ID_HEADER, strconv.FormatUint(uint64(message.EventID), 10))
+	request.Header.Set(EVENT_TYPE_HEADER, message.Type)
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
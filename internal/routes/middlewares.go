@@ -1,28 +1,36 @@
 package routes
 
 import (
-	"errors"
-	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/openapi"
+	"github.com/Prashansa-K/serviceCatalog/internal/auth"
+	"github.com/Prashansa-K/serviceCatalog/internal/consistency"
+	"github.com/Prashansa-K/serviceCatalog/internal/deprecation"
+	"github.com/Prashansa-K/serviceCatalog/internal/health"
+	"github.com/Prashansa-K/serviceCatalog/internal/logging"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
 
 	"github.com/labstack/echo-contrib/echoprometheus"
-	"github.com/labstack/echo-contrib/jaegertracing"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	gommonLog "github.com/labstack/gommon/log"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"golang.org/x/time/rate"
 )
 
 const (
-	// Rate limit
-	RPS            = 5
-	BURST_REQUESTS = 10
-
 	// Auth
-	BEARER_SCHEME = "Bearer"
+	BEARER_SCHEME = auth.BEARER_SCHEME
 	AUTH_HEADER   = "header:Authorization"
 )
 
@@ -32,30 +40,67 @@ func registerTrailingSlashRemover(app *echo.Echo) {
 }
 
 func registerRateLimit(app *echo.Echo) {
-	config := middleware.RateLimiterConfig{
-		Store: middleware.NewRateLimiterMemoryStoreWithConfig(
-			middleware.RateLimiterMemoryStoreConfig{
-				Rate:      RPS,
-				Burst:     BURST_REQUESTS,
-				ExpiresIn: 1 * time.Minute,
-			},
-		),
+	rateLimiterConfig := middleware.RateLimiterConfig{
+		// probes are not throttled, for a slow client not to get the process restarted
+		Skipper: isProbe,
+		Store:   &rateLimiterStore{},
 
 		DenyHandler: func(context echo.Context, identifier string, err error) error {
+			context.Response().Header().Set(echo.HeaderRetryAfter, retryAfter(config.GetRateLimitConfig().RPS))
 			return context.JSON(http.StatusTooManyRequests, "rate limit exceeded")
 		},
 	}
 
-	app.Use(middleware.RateLimiterWithConfig(config))
+	app.Use(middleware.RateLimiterWithConfig(rateLimiterConfig))
+}
+
+// rateLimiterStore applies the rate limit currently configured, the limits
+// reached being reset when it is reloaded
+type rateLimiterStore struct {
+	mutex sync.Mutex
+	limit config.RateLimitConfig
+	store *middleware.RateLimiterMemoryStore
+}
+
+func (s *rateLimiterStore) Allow(identifier string) (bool, error) {
+	limit := *config.GetRateLimitConfig()
+
+	s.mutex.Lock()
+	if s.store == nil || limit != s.limit {
+		s.limit = limit
+		s.store = middleware.NewRateLimiterMemoryStoreWithConfig(
+			middleware.RateLimiterMemoryStoreConfig{
+				Rate:      rate.Limit(limit.RPS),
+				Burst:     limit.Burst,
+				ExpiresIn: 1 * time.Minute,
+			},
+		)
+	}
+	store := s.store
+	s.mutex.Unlock()
+
+	return store.Allow(identifier)
+}
+
+// retryAfter is when the next token is refilled, rounded up to what
+// Retry-After can express
+func retryAfter(rps float64) string {
+	return strconv.Itoa(int(math.Ceil(1 / rps)))
 }
 
 func registerKeyBasedAuth(app *echo.Echo) {
 	app.Use(middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		// the API documentation and the probes are public, and callers with a
+		// client certificate are identified by its subject
+		Skipper: func(context echo.Context) bool {
+			path := context.Request().URL.Path
+			return path == openapi.SPEC_PATH || path == openapi.DOCS_PATH || isProbe(context) || hasValidCertificate(context)
+		},
 		KeyLookup:  AUTH_HEADER,
 		AuthScheme: BEARER_SCHEME,
 		// require Authorization: Bearer header to be set
 		Validator: func(key string, c echo.Context) (bool, error) {
-			return key == os.Getenv("API_AUTH_KEY"), nil
+			return auth.IsValidKey(key), nil
 		},
 
 		ErrorHandler: func(err error, context echo.Context) error {
@@ -64,41 +109,173 @@ func registerKeyBasedAuth(app *echo.Echo) {
 	}))
 }
 
-func registerLogger(app *echo.Echo) {
-	logFile, err := os.OpenFile(".log/log_file", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+func hasValidCertificate(context echo.Context) bool {
+	subject, ok := auth.CertificateSubject(context.Request())
+	return ok && auth.IsValidSubject(subject)
+}
+
+func isProbe(context echo.Context) bool {
+	path := context.Request().URL.Path
+	return path == health.LIVE_PATH || path == health.READY_PATH
+}
 
+// registerConsistency follows the writes of each caller once authenticated,
+// for its reads to see them
+func registerConsistency(app *echo.Echo) {
+	app.Use(consistency.Middleware)
+}
+
+// registerDeprecations signals the deprecated routes before the validator,
+// since the 410 of a route past its sunset is not part of the spec
+func registerDeprecations(app *echo.Echo) {
+	app.Use(deprecation.Middleware)
+}
+
+// warnOfUnknownDeprecations logs the deprecated routes matching no route,
+// which are most likely misspelled
+func warnOfUnknownDeprecations(app *echo.Echo) {
+	for _, deprecated := range deprecation.Routes() {
+		found := false
+		for _, route := range app.Routes() {
+			if route.Path == deprecated.Path && (deprecated.Method == "" || route.Method == deprecated.Method) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			slog.Warn("deprecated route matches no route", "method", deprecated.Method, "path", deprecated.Path)
+		}
+	}
+}
+
+func registerOpenAPIValidator(app *echo.Echo) {
+	openAPIConfig := config.GetOpenAPIConfig()
+	if openAPIConfig.ValidationMode == config.OPENAPI_VALIDATION_OFF {
+		return
+	}
+
+	validator, err := openapi.Validator(openapi.ValidatorConfig{
+		Mode: openAPIConfig.ValidationMode,
+	})
 	if err != nil {
-		fmt.Printf("error opening log file: %v", err)
-		logFile = os.Stdout
+		slog.Error("error loading the OpenAPI spec", "error", err)
+		os.Exit(1)
 	}
 
-	loggerConfig := middleware.LoggerConfig{
-		Format: `{"time":"${time_rfc3339_nano}","id":"${id}","remote_ip":"${remote_ip}",` +
-			`"host":"${host}","method":"${method}","uri":"${uri}","user_agent":"${user_agent}",` +
-			`"status":${status},"error":"${error}","latency_human":"${latency_human}"` +
-			`,"bytes_in":${bytes_in},"bytes_out":${bytes_out}}` + "\n",
-		Output: logFile,
+	app.Use(validator)
+}
+
+// registerLogger identifies each request by its X-Request-ID header, one
+// being generated when the caller sent none, and logs it once served. The
+// records logged with the context of the request carry its ID.
+func registerLogger(app *echo.Echo) {
+	SetLogLevel(app, config.GetLogConfig().Level)
+
+	app.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		Generator: logging.NewRequestID,
+		RequestIDHandler: func(ctx echo.Context, requestID string) {
+			request := ctx.Request()
+			ctx.SetRequest(request.WithContext(logging.WithRequestID(request.Context(), requestID)))
+		},
+	}))
+
+	app.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		// the status is the one the error handler answers with
+		HandleError:      true,
+		LogLatency:       true,
+		LogRemoteIP:      true,
+		LogHost:          true,
+		LogMethod:        true,
+		LogURI:           true,
+		LogUserAgent:     true,
+		LogStatus:        true,
+		LogError:         true,
+		LogContentLength: true,
+		LogResponseSize:  true,
+		LogValuesFunc: func(ctx echo.Context, values middleware.RequestLoggerValues) error {
+			level := slog.LevelInfo
+			if values.Status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.String("remote_ip", values.RemoteIP),
+				slog.String("host", values.Host),
+				slog.String("method", values.Method),
+				slog.String("uri", values.URI),
+				slog.String("user_agent", values.UserAgent),
+				slog.Int("status", values.Status),
+				slog.Duration("latency", values.Latency),
+				slog.String("bytes_in", values.ContentLength),
+				slog.Int64("bytes_out", values.ResponseSize),
+			}
+			if values.Error != nil {
+				attrs = append(attrs, slog.String("error", values.Error.Error()))
+			}
+
+			// the secrets among them are redacted by the logger
+			requestContext := ctx.Request().Context()
+			if slog.Default().Enabled(requestContext, slog.LevelDebug) {
+				attrs = append(attrs, slog.Group("headers", headerAttrs(ctx.Request().Header)...))
+			}
+
+			slog.LogAttrs(requestContext, level, "request", attrs...)
+			return nil
+		},
+	}))
+}
+
+func headerAttrs(header http.Header) []interface{} {
+	attrs := make([]interface{}, 0, len(header))
+	for name, values := range header {
+		attrs = append(attrs, slog.String(name, strings.Join(values, ", ")))
 	}
-	app.Use(middleware.LoggerWithConfig(loggerConfig))
+
+	return attrs
 }
 
-func registerMetricsServer(app *echo.Echo) {
-	app.Use(echoprometheus.NewMiddleware("serviceCatalog"))
+var logLevels = map[string]gommonLog.Lvl{
+	config.LOG_LEVEL_DEBUG: gommonLog.DEBUG,
+	config.LOG_LEVEL_INFO:  gommonLog.INFO,
+	config.LOG_LEVEL_WARN:  gommonLog.WARN,
+	config.LOG_LEVEL_ERROR: gommonLog.ERROR,
+	config.LOG_LEVEL_OFF:   gommonLog.OFF,
+}
 
-	go func() {
-		// running a separate metrics server
-		metricServer := echo.New()
+// SetLogLevel sets the level of the logs, and of those of echo itself, e.g. on
+// reload
+func SetLogLevel(app *echo.Echo, level string) {
+	logging.SetLevel(level)
+	app.Logger.SetLevel(logLevels[level])
+}
 
-		metricsServerConfig := config.GetMetricsServerConfig()
+func registerMetrics(app *echo.Echo) {
+	app.Use(echoprometheus.NewMiddleware(constants.METRICS_NAMESPACE))
+}
 
-		// adding a separate route to serve gathered metrics
-		metricServer.GET("/metrics", echoprometheus.NewHandler())
-		if err := metricServer.Start(metricsServerConfig.Address); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal(err)
-		}
-	}()
+// NewMetricsServer serves the gathered metrics, separately from the API.
+// It is started and shut down by the caller, along with the API.
+func NewMetricsServer() *echo.Echo {
+	metricServer := echo.New()
+	metricServer.HideBanner = true
+
+	// adding a separate route to serve gathered metrics
+	metricServer.GET("/metrics", echoprometheus.NewHandler())
+
+	return metricServer
 }
 
-func registerJaegarTracing(app *echo.Echo) {
-	jaegertracing.New(app, nil)
+// registerTracing starts the span of each request, continuing the trace of
+// its traceparent header if any, and echoes the trace ID in the response
+func registerTracing(app *echo.Echo) {
+	app.Use(otelecho.Middleware(config.GetTracingConfig().ServiceName, otelecho.WithSkipper(isProbe)))
+	app.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if traceID := tracing.TraceID(ctx.Request().Context()); traceID != "" {
+				ctx.Response().Header().Set(constants.TRACE_ID_HEADER, traceID)
+			}
+			return next(ctx)
+		}
+	})
 }
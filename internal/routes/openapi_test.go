@@ -0,0 +1,1310 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/openapi"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newSpecCheckedApp fails the test whenever a handler answers with a response
+// that is not documented in openapi_spec.yaml
+func newSpecCheckedApp(t *testing.T) *echo.Echo {
+	useAuthKey(t, TEST_API_KEY)
+
+	validator, err := openapi.Validator(openapi.ValidatorConfig{
+		Mode: config.OPENAPI_VALIDATION_LOG,
+		OnMismatch: func(ctx echo.Context, kind string, err error) {
+			if kind == openapi.RESPONSE {
+				t.Errorf("%s %s drifted from the spec: %v", ctx.Request().Method, ctx.Path(), err)
+			}
+		},
+	})
+	require.NoError(t, err)
+
+	app := echo.New()
+	registerTrailingSlashRemover(app)
+	registerRateLimit(app)
+	registerKeyBasedAuth(app)
+	app.Use(validator)
+	registerHandlers(app)
+
+	return app
+}
+
+const testImageDigest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+func expectMutationRecorded(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "events"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(1))
+	mock.ExpectCommit()
+}
+
+func expectRevisionRecorded(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "service_revisions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(1))
+}
+
+func expectServiceFound(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "revision", "created_at", "updated_at"}).
+			AddRow(123, "payments", "Payments", 1, 1, time.Now(), time.Now()))
+}
+
+func expectServiceMissing(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+		WillReturnError(gorm.ErrRecordNotFound)
+	expectNotRenamed(mock)
+	mock.ExpectRollback()
+}
+
+func expectNotRenamed(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}))
+}
+
+// expectRenamed finds the service looked up by its previous name, billing
+func expectRenamed(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+		WithArgs("billing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}).
+			AddRow("payments", time.Now()))
+}
+
+func expectServiceNameFree(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE id <> $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+}
+
+func expectMissingInTransaction(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	expectServiceMissing(mock)
+}
+
+func expectNotDeployed(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "environments"."name" FROM "deployments"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+}
+
+func expectChangelogVersions(mock sqlmock.Sqlmock) {
+	expectServiceFound(mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description", "created_at", "release_notes"}).
+			AddRow(1, "v1", 123, "Version 1", time.Now().Add(-time.Hour), `{"added":["Payments API"]}`).
+			AddRow(2, "v2", 123, "", time.Now(), `{"fixed":["Rounding of totals"],"security":["<script>alert(1)</script>"]}`))
+}
+
+func expectNoChannels(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "name" FROM "channels"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+}
+
+func expectNoChannel(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "channels"`)).
+		WillReturnError(gorm.ErrRecordNotFound)
+}
+
+func expectChannelFound(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "channels"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "name", "version_id", "created_at", "updated_at"}).
+			AddRow(3, 123, "stable", 1, time.Now(), time.Now()))
+}
+
+func expectEnvironmentFound(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "environments" WHERE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).
+			AddRow(3, "prod", "Production"))
+}
+
+func expectDeploymentsListed(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name AS service_name`)).
+		WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "environment", "actor", "deployed_at"}).
+			AddRow("payments", "v1", "prod", "release-pipeline", time.Now()))
+}
+
+// expectServiceRead answers the service the v2 API reads back after a mutation
+func expectServiceRead(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	expectServiceFound(mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description", "created_at"}).
+			AddRow(1, "v1", 123, "Version 1", time.Now()))
+}
+
+// expectVersionRead answers the version the v2 API reads back after a mutation
+func expectVersionRead(mock sqlmock.Sqlmock) {
+	expectServiceFound(mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "created_at"}).AddRow(1, "v1", 123, time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "artifacts"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version_id", "type", "reference", "digest"}).
+			AddRow(1, 1, "image", "ghcr.io/acme/payments:1.0.0", testImageDigest))
+}
+
+func TestHandlersMatchTheSpec(t *testing.T) {
+	for _, testCase := range []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		noAuth bool
+		expect func(mock sqlmock.Sqlmock)
+		status int
+	}{
+		{
+			name: "list services", method: http.MethodGet, path: "/v1/services?sort=desc",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "created_at"}).
+						AddRow(123, "payments", "Payments", 1, time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "list no services", method: http.MethodGet, path: "/v1/services",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "list invalid page", method: http.MethodGet, path: "/v1/services?page=3",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "get service", method: http.MethodGet, path: "/v1/service/payments",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description", "created_at"}).
+						AddRow(1, "v1", 123, "Version 1", time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "get missing service", method: http.MethodGet, path: "/v1/service/missing",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNotRenamed(mock)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "get service history", method: http.MethodGet, path: "/v1/service/payments/history",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "service_revisions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "service_revisions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "revision", "changes", "snapshot", "created_at"}).
+						AddRow(2, 123, 2, `[{"field":"description","from":"Payments","to":"Payments v2"}]`, `{"name":"payments","description":"Payments v2"}`, time.Now()).
+						AddRow(1, 123, 1, `[{"field":"name","from":null,"to":"payments"}]`, `{"name":"payments","description":"Payments"}`, time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "get history of a missing service", method: http.MethodGet, path: "/v1/service/missing/history",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNotRenamed(mock)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "get service revision", method: http.MethodGet, path: "/v1/service/payments/history/1",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "service_revisions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "revision", "changes", "snapshot", "created_at"}).
+						AddRow(1, 123, 1, `[{"field":"name","from":null,"to":"payments"}]`, `{"name":"payments","description":"Payments"}`, time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "get missing service revision", method: http.MethodGet, path: "/v1/service/payments/history/9",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "service_revisions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "get invalid service revision", method: http.MethodGet, path: "/v1/service/payments/history/first",
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "create service", method: http.MethodPost, path: "/v1/service",
+			body: `{"name":"payments","description":"Payments"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceNameFree(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(123))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "create service with metadata", method: http.MethodPost, path: "/v1/service",
+			body: `{"name":"payments","links":[{"type":"runbook","url":"https://wiki.example.com/payments"}],"contacts":[{"name":"Payments team","email":"payments@example.com","role":"owner"}],"attributes":{"tier":"critical"}}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceNameFree(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(123))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "create service with an invalid link", method: http.MethodPost, path: "/v1/service",
+			body:   `{"name":"payments","links":[{"type":"wiki","url":"https://wiki.example.com/payments"}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "list services by attribute", method: http.MethodGet, path: "/v1/services?attributes[team.name]=payments",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE jsonb_extract_path_text(attributes, $1, $2) = $3`)).
+					WithArgs("team", "name", "payments").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE jsonb_extract_path_text(attributes, $1, $2) = $3`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "created_at", "updated_at", "links", "contacts", "attributes"}).
+						AddRow(123, "payments", "Payments", 1, time.Now(), time.Now(), `[{"type":"repository","url":"https://git.example.com/payments"}]`, `[]`, `{"team":{"name":"payments"}}`))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "list services by an invalid attribute path", method: http.MethodGet, path: "/v1/services?attributes[team..name]=payments",
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "create service with a malformed body", method: http.MethodPost, path: "/v1/service",
+			body:   `{"name":`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "update service", method: http.MethodPatch, path: "/v1/service",
+			body: `{"id":123,"description":"Payments v2"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "update missing service", method: http.MethodPatch, path: "/v1/service",
+			body: `{"id":321,"name":"billing"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "rename service", method: http.MethodPatch, path: "/v1/service",
+			body: `{"id":123,"name":"billing"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				expectServiceNameFree(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "service_aliases"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "service_aliases"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "rename service to a name taken", method: http.MethodPatch, path: "/v1/service",
+			body: `{"id":123,"name":"billing"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE id <> $1`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "create service named after a previous name", method: http.MethodPost, path: "/v1/service",
+			body: `{"name":"billing"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE id <> $1`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "get service by a previous name", method: http.MethodGet, path: "/v1/service/billing?page=2",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				expectRenamed(mock)
+			},
+			status: http.StatusMovedPermanently,
+		},
+		{
+			name: "get version by a previous service name", method: http.MethodGet, path: "/v1/service/billing/version/v1",
+			expect: expectRenamed,
+			status: http.StatusMovedPermanently,
+		},
+		{
+			name: "list service aliases", method: http.MethodGet, path: "/v1/service/payments/aliases",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "service_aliases"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "name", "created_at"}).
+						AddRow(1, 123, "billing", time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "list aliases of a missing service", method: http.MethodGet, path: "/v1/service/missing/aliases",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNotRenamed(mock)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "delete service by a previous name", method: http.MethodDelete, path: "/v1/service/billing",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectRenamed(mock)
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "channels"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "service_aliases"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "delete service", method: http.MethodDelete, path: "/v1/service/payments",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "channels"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "service_aliases"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "delete missing service", method: http.MethodDelete, path: "/v1/service/missing",
+			expect: expectMissingInTransaction,
+			status: http.StatusNotFound,
+		},
+		{
+			name: "create version", method: http.MethodPost, path: "/v1/service/version",
+			body: `{"name":"v2","service_name":"payments","description":"Version 2"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "create duplicate version", method: http.MethodPost, path: "/v1/service/version",
+			body: `{"name":"v1","service_name":"payments"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+					WillReturnError(errors.New(`duplicate key value violates unique constraint "unique_service_version"`))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "create version of a missing service", method: http.MethodPost, path: "/v1/service/version",
+			body:   `{"name":"v1","service_name":"missing"}`,
+			expect: expectMissingInTransaction,
+			status: http.StatusNotFound,
+		},
+		{
+			name: "delete version", method: http.MethodDelete, path: "/v1/service/payments/version/v1",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectNoChannels(mock)
+				expectNotDeployed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "delete deployed version", method: http.MethodDelete, path: "/v1/service/payments/version/v1",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectNoChannels(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "environments"."name" FROM "deployments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("prod"))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "force delete deployed version", method: http.MethodDelete, path: "/v1/service/payments/version/v1?force=true",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectNoChannels(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "delete missing version", method: http.MethodDelete, path: "/v1/service/payments/version/v9",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "get version", method: http.MethodGet, path: "/v1/service/payments/version/v1",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "created_at"}).AddRow(1, "v1", 123, time.Now()))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "artifacts"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "version_id", "type", "reference", "digest"}).
+						AddRow(1, 1, "image", "ghcr.io/acme/payments:1.0.0", testImageDigest))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "get missing version", method: http.MethodGet, path: "/v1/service/payments/version/v9",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNoChannel(mock)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "create version with artifacts", method: http.MethodPost, path: "/v1/service/version",
+			body: `{"name":"v2","service_name":"payments","artifacts":[{"type":"image","reference":"ghcr.io/acme/payments:2.0.0","digest":"` + testImageDigest + `"}]}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "artifacts"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "create version with an unpinned image", method: http.MethodPost, path: "/v1/service/version",
+			body:   `{"name":"v2","service_name":"payments","artifacts":[{"type":"image","reference":"ghcr.io/acme/payments:latest"}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "create version with release notes", method: http.MethodPost, path: "/v1/service/version",
+			body: `{"name":"v2","service_name":"payments","release_notes":{"added":["Refunds"],"fixed":[" Rounding of totals "]}}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "create version with an empty release note", method: http.MethodPost, path: "/v1/service/version",
+			body:   `{"name":"v2","service_name":"payments","release_notes":{"security":[" "]}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "get changelog", method: http.MethodGet, path: "/v1/service/payments/changelog?from=v1&to=v2",
+			expect: expectChangelogVersions,
+			status: http.StatusOK,
+		},
+		{
+			name: "get changelog as markdown", method: http.MethodGet, path: "/v1/service/payments/changelog?format=markdown",
+			expect: expectChangelogVersions,
+			status: http.StatusOK,
+		},
+		{
+			name: "get changelog as keep a changelog", method: http.MethodGet, path: "/v1/service/payments/changelog?format=keep-a-changelog",
+			expect: expectChangelogVersions,
+			status: http.StatusOK,
+		},
+		{
+			name: "get changelog as html", method: http.MethodGet, path: "/v1/service/payments/changelog?format=html",
+			expect: expectChangelogVersions,
+			status: http.StatusOK,
+		},
+		{name: "get changelog in an unknown format", method: http.MethodGet, path: "/v1/service/payments/changelog?format=pdf", status: http.StatusBadRequest},
+		{
+			name: "get changelog of a reversed range", method: http.MethodGet, path: "/v1/service/payments/changelog?from=v2&to=v1",
+			expect: expectChangelogVersions,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "get changelog from a missing version", method: http.MethodGet, path: "/v1/service/payments/changelog?from=v9",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectChangelogVersions(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNoChannel(mock)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "look up an artifact", method: http.MethodGet, path: "/v1/artifacts/" + testImageDigest,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name AS service_name`)).
+					WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "type", "reference", "digest", "created_at"}).
+						AddRow("payments", "v1", "image", "ghcr.io/acme/payments:1.0.0", testImageDigest, time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "look up an unknown artifact", method: http.MethodGet, path: "/v1/artifacts/" + testImageDigest,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name AS service_name`)).
+					WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "type", "reference", "digest", "created_at"}))
+			},
+			status: http.StatusNotFound,
+		},
+		{name: "look up an invalid digest", method: http.MethodGet, path: "/v1/artifacts/latest", status: http.StatusBadRequest},
+		{
+			name: "list channels", method: http.MethodGet, path: "/v1/service/payments/channels",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT channels.name, versions.name AS version_name`)).
+					WillReturnRows(sqlmock.NewRows([]string{"name", "version_name", "updated_at"}).AddRow("stable", "v1", time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "create channel", method: http.MethodPut, path: "/v1/service/payments/channels/stable",
+			body: `{"version":"v1"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectNoChannel(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channels"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channel_moves"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "move channel", method: http.MethodPut, path: "/v1/service/payments/channels/stable",
+			body: `{"version":"v2"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(2, "v2", 123))
+				expectChannelFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "channels"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channel_moves"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "create channel concurrently", method: http.MethodPut, path: "/v1/service/payments/channels/stable",
+			body: `{"version":"v1"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectNoChannel(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channels"`)).
+					WillReturnError(errors.New(`duplicate key value violates unique constraint "unique_service_channel"`))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "create channel with an invalid name", method: http.MethodPut, path: "/v1/service/payments/channels/1.0",
+			body:   `{"version":"v1"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "point channel to a missing version", method: http.MethodPut, path: "/v1/service/payments/channels/stable",
+			body: `{"version":"v9"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNoChannel(mock)
+				mock.ExpectRollback()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "delete channel", method: http.MethodDelete, path: "/v1/service/payments/channels/stable",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				expectChannelFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "channels"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channel_moves"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "delete missing channel", method: http.MethodDelete, path: "/v1/service/payments/channels/lts",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				expectNoChannel(mock)
+				mock.ExpectRollback()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "get channel history", method: http.MethodGet, path: "/v1/service/payments/channels/stable/history",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "channel_moves"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT channel_moves.channel`)).
+					WillReturnRows(sqlmock.NewRows([]string{"channel", "from_version", "to_version", "moved_at"}).
+						AddRow("stable", "v1", "v2", time.Now()).
+						AddRow("stable", nil, "v1", time.Now().Add(-time.Hour)))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "get history of a missing channel", method: http.MethodGet, path: "/v1/service/payments/channels/lts/history",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "channel_moves"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "delete version a channel points to", method: http.MethodDelete, path: "/v1/service/payments/version/v1?force=true",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "name" FROM "channels"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("stable"))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "get version by channel", method: http.MethodGet, path: "/v1/service/payments/version/stable",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectChannelFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "created_at"}).AddRow(1, "v1", 123, time.Now()))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "artifacts"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "version_id", "type", "reference", "digest"}))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "list environments", method: http.MethodGet, path: "/v1/environments",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "environments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
+						AddRow(1, "dev", "Development", time.Now()).
+						AddRow(3, "prod", "Production", time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "create environment", method: http.MethodPost, path: "/v1/environments",
+			body: `{"name":"qa","description":"Quality assurance"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "environments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+				mock.ExpectCommit()
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "create duplicate environment", method: http.MethodPost, path: "/v1/environments",
+			body: `{"name":"prod"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "environments"`)).
+					WillReturnError(errors.New(`duplicate key value violates unique constraint "environments_name_key"`))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "deploy version", method: http.MethodPost, path: "/v1/service/payments/deployments",
+			body: `{"version":"v1","environment":"prod","actor":"release-pipeline"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectEnvironmentFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "deployments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "deploy version to a missing environment", method: http.MethodPost, path: "/v1/service/payments/deployments",
+			body: `{"version":"v1","environment":"qa","actor":"release-pipeline"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "environments"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "deploy version without an actor", method: http.MethodPost, path: "/v1/service/payments/deployments",
+			body:   `{"version":"v1","environment":"prod","actor":" "}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "get deployment history", method: http.MethodGet, path: "/v1/service/payments/deployments?environment=prod",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectServiceFound(mock)
+				expectEnvironmentFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "deployments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				expectDeploymentsListed(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "get environment services", method: http.MethodGet, path: "/v1/environments/prod/services",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectEnvironmentFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "deployments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				expectDeploymentsListed(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "get services of a missing environment", method: http.MethodGet, path: "/v1/environments/qa/services",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "environments"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "v2 list services", method: http.MethodGet, path: "/v2/services?sort=desc",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "created_at"}).
+						AddRow(123, "payments", "Payments", 1, time.Now()))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 create service", method: http.MethodPost, path: "/v2/services",
+			body: `{"name":"payments","description":"Payments"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceNameFree(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(123))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+				expectServiceRead(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "v2 create service without a name", method: http.MethodPost, path: "/v2/services",
+			body:   `{"description":"Payments"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "v2 get service", method: http.MethodGet, path: "/v2/services/payments",
+			expect: expectServiceRead,
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 get missing service", method: http.MethodGet, path: "/v2/services/missing",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNotRenamed(mock)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "v2 get service by a previous name", method: http.MethodGet, path: "/v2/services/billing",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				expectRenamed(mock)
+			},
+			status: http.StatusMovedPermanently,
+		},
+		{
+			name: "v2 put new service", method: http.MethodPut, path: "/v2/services/payments",
+			body: `{"description":"Payments"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				expectNotRenamed(mock)
+				expectServiceNameFree(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "services"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(123))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+				expectServiceRead(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "v2 put existing service", method: http.MethodPut, path: "/v2/services/payments",
+			body: `{"name":"payments","description":"Payments v2"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+				expectServiceRead(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 put service under another name", method: http.MethodPut, path: "/v2/services/payments",
+			body:   `{"name":"billing"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "v2 rename service", method: http.MethodPatch, path: "/v2/services/payments",
+			body: `{"name":"billing"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				expectServiceNameFree(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "service_aliases"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "service_aliases"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectRevisionRecorded(mock)
+				expectMutationRecorded(mock)
+				expectServiceRead(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 rename service to a name taken", method: http.MethodPatch, path: "/v2/services/payments",
+			body: `{"name":"billing"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE id <> $1`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "v2 patch missing service", method: http.MethodPatch, path: "/v2/services/missing",
+			body:   `{"description":"Missing"}`,
+			expect: expectMissingInTransaction,
+			status: http.StatusNotFound,
+		},
+		{
+			name: "v2 delete service", method: http.MethodDelete, path: "/v2/services/payments",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "channels"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "service_aliases"`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusNoContent,
+		},
+		{
+			name: "v2 delete missing service", method: http.MethodDelete, path: "/v2/services/missing",
+			expect: expectMissingInTransaction,
+			status: http.StatusNotFound,
+		},
+		{
+			name: "v2 list versions", method: http.MethodGet, path: "/v2/services/payments/versions?page=1",
+			expect: expectServiceRead,
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 create version", method: http.MethodPost, path: "/v2/services/payments/versions",
+			body: `{"name":"v1","description":"Version 1"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+				expectVersionRead(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "v2 create duplicate version", method: http.MethodPost, path: "/v2/services/payments/versions",
+			body: `{"name":"v1"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+					WillReturnError(errors.New(`duplicate key value violates unique constraint "unique_service_version"`))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "v2 create version of another service", method: http.MethodPost, path: "/v2/services/payments/versions",
+			body:   `{"name":"v1","service_name":"billing"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "v2 get version", method: http.MethodGet, path: "/v2/services/payments/versions/v1",
+			expect: expectVersionRead,
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 get version by a previous service name", method: http.MethodGet, path: "/v2/services/billing/versions/v1",
+			expect: expectRenamed,
+			status: http.StatusMovedPermanently,
+		},
+		{
+			name: "v2 put new version", method: http.MethodPut, path: "/v2/services/payments/versions/v1",
+			body: `{"description":"Version 1"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+				expectVersionRead(mock)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "v2 put existing version", method: http.MethodPut, path: "/v2/services/payments/versions/v1",
+			body: `{"name":"v1","artifacts":[{"type":"image","reference":"ghcr.io/acme/payments:1.0.0","digest":"` + testImageDigest + `"}]}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "created_at"}).AddRow(1, "v1", 123, time.Now()))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "artifacts"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "artifacts"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				expectMutationRecorded(mock)
+				expectVersionRead(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 put version under another name", method: http.MethodPut, path: "/v2/services/payments/versions/v1",
+			body:   `{"name":"v2"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "v2 patch version", method: http.MethodPatch, path: "/v2/services/payments/versions/v1",
+			body: `{"description":"Version 1, patched"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "created_at"}).AddRow(1, "v1", 123, time.Now()))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+				expectVersionRead(mock)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "v2 patch missing version", method: http.MethodPatch, path: "/v2/services/payments/versions/v9",
+			body: `{"description":"Version 9"}`,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "v2 delete version", method: http.MethodDelete, path: "/v2/services/payments/versions/v1",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectNoChannels(mock)
+				expectNotDeployed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectMutationRecorded(mock)
+			},
+			status: http.StatusNoContent,
+		},
+		{
+			name: "v2 delete deployed version", method: http.MethodDelete, path: "/v2/services/payments/versions/v1",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectServiceFound(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(1, "v1", 123))
+				expectNoChannels(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "environments"."name" FROM "deployments"`)).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("prod"))
+				mock.ExpectRollback()
+			},
+			status: http.StatusConflict,
+		},
+		{name: "ping", method: http.MethodGet, path: "/ping", status: http.StatusOK},
+		{name: "ping without key", method: http.MethodGet, path: "/ping", noAuth: true, status: http.StatusUnauthorized},
+		{name: "liveness", method: http.MethodGet, path: "/healthz/live", noAuth: true, status: http.StatusOK},
+		{name: "readiness", method: http.MethodGet, path: "/healthz/ready", noAuth: true, status: http.StatusOK},
+		{name: "spec", method: http.MethodGet, path: "/openapi.yaml", noAuth: true, status: http.StatusOK},
+		{name: "docs", method: http.MethodGet, path: "/docs", noAuth: true, status: http.StatusOK},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock := initMockDB(t)
+			if testCase.expect != nil {
+				testCase.expect(mock)
+			}
+
+			request := httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(testCase.body))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if !testCase.noAuth {
+				request.Header.Set(echo.HeaderAuthorization, "Bearer "+TEST_API_KEY)
+			}
+			recorder := httptest.NewRecorder()
+
+			newSpecCheckedApp(t).ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code, recorder.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestValidator_Enforce(t *testing.T) {
+	initMockDB(t)
+	useAuthKey(t, TEST_API_KEY)
+
+	validator, err := openapi.Validator(openapi.ValidatorConfig{Mode: config.OPENAPI_VALIDATION_ENFORCE})
+	require.NoError(t, err)
+
+	app := echo.New()
+	registerKeyBasedAuth(app)
+	app.Use(validator)
+	registerHandlers(app)
+
+	// a drifted response is held back and replaced
+	app.GET("/v1/services", func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, echo.Map{"services": "none"})
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+TEST_API_KEY)
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// the version name is required, the DB is never reached
+	recorder := serve(http.MethodPost, "/v1/service/version", `{"service_name":"payments"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "name")
+
+	recorder = serve(http.MethodGet, "/v1/services?page=first", "")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = serve(http.MethodGet, "/v1/services", "")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "none")
+
+	// null metadata leaves it unchanged on updates
+	recorder = serve(http.MethodPatch, "/v1/service", `{"id":123,"links":null,"contacts":null,"attributes":null}`)
+	assert.NotEqual(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+
+	// attribute filters are a valid request, only the response is replaced
+	recorder = serve(http.MethodGet, "/v1/services?attributes[team.name]=payments", "")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	// routes the spec does not describe are left alone
+	recorder = serve(http.MethodPost, "/graphql", `{"query":"{ __typename }"}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestEveryRouteIsInTheSpec(t *testing.T) {
+	spec, err := openapi.Spec()
+	require.NoError(t, err)
+
+	app := echo.New()
+	registerHandlers(app)
+
+	for _, route := range app.Routes() {
+		// GraphQL has its own, introspectable, schema
+		if route.Path == "/graphql" {
+			continue
+		}
+
+		path := regexp.MustCompile(`:(\w+)`).ReplaceAllString(route.Path, "{$1}")
+		pathItem := spec.Paths.Find(path)
+		if assert.NotNil(t, pathItem, "%s is not documented", route.Path) {
+			assert.NotNil(t, pathItem.GetOperation(route.Method), "%s %s is not documented", route.Method, route.Path)
+		}
+	}
+}
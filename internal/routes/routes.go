@@ -4,7 +4,11 @@ package routes
 import (
 	"net/http"
 
+	"github.com/Prashansa-K/serviceCatalog/internal/api/graphql"
+	"github.com/Prashansa-K/serviceCatalog/internal/api/openapi"
 	api "github.com/Prashansa-K/serviceCatalog/internal/api/v1"
+	apiV2 "github.com/Prashansa-K/serviceCatalog/internal/api/v2"
+	"github.com/Prashansa-K/serviceCatalog/internal/health"
 
 	"github.com/labstack/echo/v4"
 )
@@ -15,24 +19,56 @@ const (
 
 func RegisterRoutes(app *echo.Echo) {
 	// Registering middlewares
-	registerJaegarTracing(app)
+	registerTracing(app)
 	registerLogger(app)
-	registerMetricsServer(app)
+	registerMetrics(app)
+
+	RegisterAPI(app)
+}
+
+// RegisterAPI registers the routes along with the middlewares that shape their
+// responses. Unlike RegisterRoutes it needs no infrastructure besides the DB,
+// which lets clients be tested against the real routes.
+func RegisterAPI(app *echo.Echo) {
 	registerTrailingSlashRemover(app)
 	registerRateLimit(app)
 	registerKeyBasedAuth(app)
+	registerConsistency(app)
+	registerDeprecations(app)
+	registerOpenAPIValidator(app)
+
+	registerHandlers(app)
+	warnOfUnknownDeprecations(app)
+}
+
+func registerHandlers(app *echo.Echo) {
+	// The API specification and its Swagger UI, served without auth
+	app.GET(openapi.SPEC_PATH, openapi.ServeSpec)
+	app.GET(openapi.DOCS_PATH, openapi.ServeDocs)
 
 	// Health check route
 	app.GET("/ping", func(c echo.Context) error {
 		return c.String(http.StatusOK, PONG_RESPONSE)
 	})
 
+	// Probes of the orchestrator, served without auth
+	app.GET(health.LIVE_PATH, health.Live)
+	app.GET(health.READY_PATH, health.Ready)
+
+	// GraphQL API over services and versions, sharing the v1 controllers
+	app.GET("/graphql", graphql.Handler)
+	app.POST("/graphql", graphql.Handler)
+
 	appV1 := app.Group("/v1")
 
 	appV1.GET("/services", api.GetServices)
 
 	appV1.GET("/service/:serviceName", api.GetService)
 
+	appV1.GET("/service/:serviceName/history", api.GetServiceHistory)
+
+	appV1.GET("/service/:serviceName/history/:revision", api.GetServiceRevision)
+
 	appV1.POST("/service", api.CreateService)
 
 	appV1.POST("/service/version", api.CreateVersion)
@@ -41,5 +77,60 @@ func RegisterRoutes(app *echo.Echo) {
 
 	appV1.DELETE("/service/:serviceName", api.DeleteService)
 
+	appV1.GET("/service/:serviceName/version/:versionName", api.GetVersion)
+
 	appV1.DELETE("/service/:serviceName/version/:versionName", api.DeleteVersion)
+
+	appV1.GET("/service/:serviceName/aliases", api.GetServiceAliases)
+
+	appV1.GET("/service/:serviceName/changelog", api.GetChangelog)
+
+	appV1.GET("/service/:serviceName/channels", api.GetChannels)
+
+	appV1.PUT("/service/:serviceName/channels/:channel", api.SetChannel)
+
+	appV1.DELETE("/service/:serviceName/channels/:channel", api.DeleteChannel)
+
+	appV1.GET("/service/:serviceName/channels/:channel/history", api.GetChannelHistory)
+
+	appV1.GET("/service/:serviceName/deployments", api.GetDeploymentHistory)
+
+	appV1.POST("/service/:serviceName/deployments", api.DeployVersion)
+
+	appV1.GET("/artifacts/:digest", api.LookupArtifact)
+
+	appV1.GET("/environments", api.GetEnvironments)
+
+	appV1.POST("/environments", api.CreateEnvironment)
+
+	appV1.GET("/environments/:environment/services", api.GetEnvironmentServices)
+
+	appV1.GET("/events/stream", api.StreamEvents)
+
+	// v2 serves services and versions as resources, from the same controllers
+	appV2 := app.Group("/v2")
+
+	appV2.GET("/services", apiV2.ListServices)
+
+	appV2.POST("/services", apiV2.CreateService)
+
+	appV2.GET("/services/:serviceName", apiV2.GetService)
+
+	appV2.PUT("/services/:serviceName", apiV2.ReplaceService)
+
+	appV2.PATCH("/services/:serviceName", apiV2.PatchService)
+
+	appV2.DELETE("/services/:serviceName", apiV2.DeleteService)
+
+	appV2.GET("/services/:serviceName/versions", apiV2.ListVersions)
+
+	appV2.POST("/services/:serviceName/versions", apiV2.CreateVersion)
+
+	appV2.GET("/services/:serviceName/versions/:versionName", apiV2.GetVersion)
+
+	appV2.PUT("/services/:serviceName/versions/:versionName", apiV2.ReplaceVersion)
+
+	appV2.PATCH("/services/:serviceName/versions/:versionName", apiV2.PatchVersion)
+
+	appV2.DELETE("/services/:serviceName/versions/:versionName", apiV2.DeleteVersion)
 }
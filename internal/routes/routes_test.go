@@ -0,0 +1,435 @@
+package routes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	api "github.com/Prashansa-K/serviceCatalog/internal/api/v1"
+	"github.com/Prashansa-K/serviceCatalog/internal/auth"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/events"
+	"github.com/Prashansa-K/serviceCatalog/internal/logging"
+	"github.com/Prashansa-K/serviceCatalog/internal/models"
+	"github.com/Prashansa-K/serviceCatalog/internal/tracing"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const TEST_API_KEY = "test-key"
+
+func initMockDB(t *testing.T) sqlmock.Sqlmock {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db.DB, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.DB = nil
+	})
+
+	return mock
+}
+
+// useAuthKey has the API accept key, the configuration being restored after
+// the test
+func useAuthKey(t *testing.T, key string) {
+	previous := config.Current()
+	t.Cleanup(func() { config.Set(previous) })
+
+	authenticated := *previous
+	authenticated.Auth = config.AuthConfig{Key: key}
+	config.Set(&authenticated)
+}
+
+// newTestApp registers the same middleware chain as RegisterRoutes, minus the
+// logger, registered by the tests of the logs, and the metrics
+func newTestApp(t *testing.T) *echo.Echo {
+	useAuthKey(t, TEST_API_KEY)
+
+	app := echo.New()
+	registerTracing(app)
+	registerTrailingSlashRemover(app)
+	registerRateLimit(app)
+	registerKeyBasedAuth(app)
+
+	appV1 := app.Group("/v1")
+	appV1.GET("/events/stream", api.StreamEvents)
+
+	return app
+}
+
+func openStream(t *testing.T, server *httptest.Server, path string, headers map[string]string) (*bufio.Reader, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+TEST_API_KEY)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	return bufio.NewReader(response.Body), func() {
+		cancel()
+		response.Body.Close()
+	}
+}
+
+// readFrame reads a single SSE frame, up to the blank line terminating it
+func readFrame(t *testing.T, reader *bufio.Reader) string {
+	var frame strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return frame.String()
+		}
+		frame.WriteString(line)
+	}
+}
+
+func TestStreamEvents_LiveThroughMiddlewareChain(t *testing.T) {
+	initMockDB(t)
+	server := httptest.NewServer(newTestApp(t))
+	defer server.Close()
+
+	// trailing slash is removed before routing, filters narrow the stream
+	reader, closeStream := openStream(t, server, "/v1/events/stream/?service_name=payments", nil)
+	defer closeStream()
+
+	// the handler subscribes before sending the retry frame, so it is safe to publish once read
+	assert.Equal(t, "retry: 3000\n", readFrame(t, reader))
+
+	events.Publish(models.Event{ID: 41, Type: "service.created", ServiceName: "orders", Data: `{}`})
+	events.Publish(models.Event{ID: 42, Type: "service.created", ServiceName: "payments", Data: `{"name":"payments"}`})
+
+	frame := readFrame(t, reader)
+	assert.Contains(t, frame, "id: 42\n")
+	assert.Contains(t, frame, "event: service.created\n")
+	assert.Contains(t, frame, `"data":{"name":"payments"}`)
+}
+
+func TestStreamEvents_ResumesFromLastEventID(t *testing.T) {
+	mock := initMockDB(t)
+	server := httptest.NewServer(newTestApp(t))
+	defer server.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "xid","id" FROM "events" WHERE "events"."id" = $1 ORDER BY "events"."id" LIMIT $2`)).
+		WithArgs(10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"xid", "id"}).
+			AddRow(700, 10))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "events" WHERE (xid, id) > ($1, $2) AND xid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint AND type IN ($3) ORDER BY xid ASC, id ASC LIMIT $4`)).
+		WithArgs(700, 10, "version.created", 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "xid", "type", "service_name", "version_name", "data", "created_at"}).
+			AddRow(11, 702, "version.created", "payments", "v2", `{"name":"v2"}`, time.Now()))
+
+	reader, closeStream := openStream(t, server, "/v1/events/stream?type=version.created", map[string]string{
+		"Last-Event-ID": "10",
+	})
+	defer closeStream()
+
+	readFrame(t, reader)
+
+	replayed := readFrame(t, reader)
+	assert.Contains(t, replayed, "id: 11\n")
+	assert.Contains(t, replayed, `"version_name":"v2"`)
+
+	// already replayed events are not sent twice, ids being drawn before the
+	// transactions commit, an event of a later transaction can have a lower id
+	events.Publish(models.Event{ID: 11, Xid: 702, Type: "version.created", ServiceName: "payments"})
+	events.Publish(models.Event{ID: 9, Xid: 703, Type: "version.created", ServiceName: "payments", Data: `{}`})
+
+	assert.Contains(t, readFrame(t, reader), "id: 9\n")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamEvents_InvalidRequests(t *testing.T) {
+	mock := initMockDB(t)
+	app := newTestApp(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "xid","id" FROM "events" WHERE "events"."id" = $1`)).
+		WithArgs(404, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"xid", "id"}))
+
+	for _, testCase := range []struct {
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{path: "/v1/events/stream", status: http.StatusUnauthorized},
+		{path: "/v1/events/stream?type=service.renamed", headers: map[string]string{"Authorization": "Bearer " + TEST_API_KEY}, status: http.StatusBadRequest},
+		{path: "/v1/events/stream", headers: map[string]string{"Authorization": "Bearer " + TEST_API_KEY, "Last-Event-ID": "abc"}, status: http.StatusBadRequest},
+		{path: "/v1/events/stream", headers: map[string]string{"Authorization": "Bearer " + TEST_API_KEY, "Last-Event-ID": "404"}, status: http.StatusBadRequest},
+	} {
+		request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+		for key, value := range testCase.headers {
+			request.Header.Set(key, value)
+		}
+		recorder := httptest.NewRecorder()
+
+		app.ServeHTTP(recorder, request)
+
+		assert.Equal(t, testCase.status, recorder.Code, testCase.path)
+	}
+}
+
+func TestRenamedService_RedirectsReads(t *testing.T) {
+	mock := initMockDB(t)
+	renamedAt := time.Unix(1688169599, 0)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE`)).
+		WithArgs("billing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WithArgs("billing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}).
+			AddRow("payments v2", renamedAt))
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/service/billing/channels/stable/history?page=2", nil)
+	request.Header.Set(echo.HeaderAuthorization, "Bearer "+TEST_API_KEY)
+	recorder := httptest.NewRecorder()
+
+	newSpecCheckedApp(t).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/v1/service/payments%20v2/channels/stable/history?page=2", recorder.Header().Get(echo.HeaderLocation))
+	assert.Equal(t, "@1688169599", recorder.Header().Get("Deprecation"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestV2_CreateVersionAnswersWithTheResource(t *testing.T) {
+	mock := initMockDB(t)
+
+	mock.ExpectBegin()
+	expectServiceFound(mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectMutationRecorded(mock)
+	expectVersionRead(mock)
+
+	request := httptest.NewRequest(http.MethodPost, "/v2/services/payments/versions", strings.NewReader(`{"name":"v1"}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, "Bearer "+TEST_API_KEY)
+	recorder := httptest.NewRecorder()
+
+	newSpecCheckedApp(t).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "/v2/services/payments/versions/v1", recorder.Header().Get(echo.HeaderLocation))
+
+	var version map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &version))
+	assert.Equal(t, "payments", version["service_name"])
+	assert.Equal(t, "v1", version["name"])
+	assert.Len(t, version["artifacts"], 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestV2_PatchServiceByAPreviousName(t *testing.T) {
+	mock := initMockDB(t)
+
+	mock.ExpectBegin()
+	expectRenamed(mock)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	expectServiceFound(mock)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRevisionRecorded(mock)
+	expectMutationRecorded(mock)
+	expectServiceRead(mock)
+
+	request := httptest.NewRequest(http.MethodPatch, "/v2/services/billing", strings.NewReader(`{"description":"Payments v2"}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, "Bearer "+TEST_API_KEY)
+	recorder := httptest.NewRecorder()
+
+	newSpecCheckedApp(t).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.NotEmpty(t, recorder.Header().Get("Deprecation"))
+	assert.Contains(t, recorder.Body.String(), `"name":"payments"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyBasedAuth_ClientCertificate(t *testing.T) {
+	app := newTestApp(t)
+	app.GET("/caller", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, auth.Caller(ctx.Request()))
+	})
+
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: "billing", Organization: []string{"Payments"}}}
+	serve := func(withCertificate bool, key string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/caller", nil)
+		if withCertificate {
+			request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+		}
+		if key != "" {
+			request.Header.Set(echo.HeaderAuthorization, "Bearer "+key)
+		}
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve(true, "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "CN=billing,O=Payments", recorder.Body.String())
+
+	// restored along with the key
+	allowSubjects := func(subjects ...string) {
+		restricted := *config.Current()
+		restricted.Auth.Subjects = subjects
+		config.Set(&restricted)
+	}
+
+	allowSubjects("CN=shipping,O=Payments", "CN=billing,O=Payments")
+	assert.Equal(t, http.StatusOK, serve(true, "").Code)
+
+	// certificates of other subjects have to come with a key
+	allowSubjects("CN=shipping,O=Payments")
+	assert.Equal(t, http.StatusUnauthorized, serve(true, "").Code)
+
+	recorder = serve(true, TEST_API_KEY)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, auth.KeyID(TEST_API_KEY), recorder.Body.String())
+}
+
+func TestRateLimit_FollowsTheConfiguration(t *testing.T) {
+	previous := config.Current()
+	t.Cleanup(func() { config.Set(previous) })
+
+	limited := *previous
+	limited.RateLimit = config.RateLimitConfig{RPS: 0.25, Burst: 1}
+	config.Set(&limited)
+
+	app := newTestApp(t)
+	call := func() *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/v1/events/stream", nil)
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.NotEqual(t, http.StatusTooManyRequests, call().Code)
+	recorder := call()
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "4", recorder.Header().Get(echo.HeaderRetryAfter))
+
+	// a reload resets the limits reached
+	reloaded := limited
+	reloaded.RateLimit = config.RateLimitConfig{RPS: 1, Burst: 2}
+	config.Set(&reloaded)
+
+	assert.NotEqual(t, http.StatusTooManyRequests, call().Code)
+	assert.NotEqual(t, http.StatusTooManyRequests, call().Code)
+	assert.Equal(t, http.StatusTooManyRequests, call().Code)
+}
+
+func TestTracing_EchoesTheTraceOfTheCaller(t *testing.T) {
+	stopTracing, err := tracing.Init(context.Background(), &config.TracingConfig{
+		Exporter:    config.TRACING_EXPORTER_NONE,
+		SampleRatio: 1,
+		ServiceName: config.DEFAULT_TRACING_SERVICE_NAME,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { stopTracing(context.Background()) })
+
+	app := newTestApp(t)
+	app.GET("/trace", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, tracing.TraceID(ctx.Request().Context()))
+	})
+
+	serve := func(traceparent string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/trace", nil)
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+TEST_API_KEY)
+		if traceparent != "" {
+			request.Header.Set("traceparent", traceparent)
+		}
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", recorder.Header().Get(constants.TRACE_ID_HEADER))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", recorder.Body.String())
+
+	// a trace is started for callers without one
+	recorder = serve("")
+	assert.Len(t, recorder.Header().Get(constants.TRACE_ID_HEADER), 32)
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", recorder.Header().Get(constants.TRACE_ID_HEADER))
+}
+
+func TestLogger_CorrelatesTheRequest(t *testing.T) {
+	var buffer bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buffer))
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		logging.SetLevel(config.DEFAULT_LOG_LEVEL)
+	})
+
+	app := newTestApp(t)
+	registerLogger(app)
+	// the headers of the requests are logged at debug level
+	logging.SetLevel(config.LOG_LEVEL_DEBUG)
+	app.GET("/request-id", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, logging.RequestID(ctx.Request().Context()))
+	})
+
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/request-id", nil)
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+TEST_API_KEY)
+		if requestID != "" {
+			request.Header.Set(echo.HeaderXRequestID, requestID)
+		}
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve("caller-request-id")
+	assert.Equal(t, "caller-request-id", recorder.Header().Get(echo.HeaderXRequestID))
+	assert.Equal(t, "caller-request-id", recorder.Body.String())
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "caller-request-id", record[logging.REQUEST_ID_KEY])
+	assert.Equal(t, float64(http.StatusOK), record["status"])
+	assert.Equal(t, logging.REDACTED, record["headers"].(map[string]interface{})[echo.HeaderAuthorization])
+	assert.NotContains(t, buffer.String(), TEST_API_KEY)
+
+	// an ID is generated for callers without one
+	recorder = serve("")
+	assert.Len(t, recorder.Header().Get(echo.HeaderXRequestID), 32)
+	assert.Equal(t, recorder.Header().Get(echo.HeaderXRequestID), recorder.Body.String())
+}
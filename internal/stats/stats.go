@@ -0,0 +1,122 @@
+// Package stats exposes what the catalog holds as gauges on the metrics
+// server: its services and versions, overall and per value of the configured
+// attributes, and the services whose version_count drifted from their
+// versions. They are counted every interval, on a replica when one answers.
+package stats
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+	constants "github.com/Prashansa-K/serviceCatalog/internal"
+	"github.com/Prashansa-K/serviceCatalog/internal/controllers"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// registered with the default registry, which is what the metrics server exposes
+var (
+	services = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "catalog",
+		Name:      "services",
+		Help:      "Number of services in the catalog.",
+	})
+
+	versions = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "catalog",
+		Name:      "versions",
+		Help:      "Number of versions of the services in the catalog.",
+	})
+
+	servicesByAttribute = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "catalog",
+		Name:      "services_by_attribute",
+		Help:      "Number of services in the catalog, per value of the configured attributes.",
+	}, []string{"attribute", "value"})
+
+	versionsByAttribute = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "catalog",
+		Name:      "versions_by_attribute",
+		Help:      "Number of versions in the catalog, per value of the configured attributes of their service.",
+	}, []string{"attribute", "value"})
+
+	versionCountDrift = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "catalog",
+		Name:      "version_count_drift",
+		Help:      "Number of services whose version_count differs from their number of versions.",
+	})
+
+	lastRefresh = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.METRICS_NAMESPACE,
+		Subsystem: "catalog",
+		Name:      "last_refresh_timestamp_seconds",
+		Help:      "When the gauges of the catalog were last counted, as a Unix time.",
+	})
+)
+
+// Run refreshes the gauges right away, then every interval until ctx is done
+func Run(ctx context.Context, catalogMetricsConfig *config.CatalogMetricsConfig) {
+	ticker := time.NewTicker(catalogMetricsConfig.Interval)
+	defer ticker.Stop()
+
+	for {
+		// counting is left to the replicas, whose reads can lag behind
+		database, err := db.GetReplicaDB()
+		if err == nil {
+			err = Refresh(database.WithContext(ctx), catalogMetricsConfig.Attributes)
+		}
+		if err != nil && ctx.Err() == nil {
+			slog.Error("error counting the catalog", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh counts the catalog once. The gauges keep their previous values when
+// counting fails.
+func Refresh(database *gorm.DB, attributePaths []string) error {
+	stats, err := controllers.GetCatalogStats(database, attributePaths)
+	if err != nil {
+		return err
+	}
+
+	services.Set(float64(stats.Services))
+	versions.Set(float64(stats.Versions))
+
+	// values no longer taken by any service are dropped
+	setByAttribute(servicesByAttribute, stats.ServicesByAttribute)
+	setByAttribute(versionsByAttribute, stats.VersionsByAttribute)
+
+	versionCountDrift.Set(float64(len(stats.DriftedServices)))
+	if len(stats.DriftedServices) > 0 {
+		slog.Warn("version_count of services differs from their number of versions", "services", stats.DriftedServices)
+	}
+
+	lastRefresh.SetToCurrentTime()
+
+	return nil
+}
+
+func setByAttribute(gauge *prometheus.GaugeVec, counts map[string]map[string]int64) {
+	gauge.Reset()
+	for path, values := range counts {
+		for value, count := range values {
+			gauge.WithLabelValues(path, value).Set(float64(count))
+		}
+	}
+}
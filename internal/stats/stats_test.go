@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// expectCount answers the queries of a refresh counting attribute team
+func expectCount(mock sqlmock.Sqlmock, teams map[string]int, drifted ...string) {
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "services"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "versions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	serviceRows := sqlmock.NewRows([]string{"value", "count"})
+	versionRows := sqlmock.NewRows([]string{"value", "count"})
+	for team, count := range teams {
+		serviceRows.AddRow(team, count)
+		versionRows.AddRow(team, 2*count)
+	}
+	mock.ExpectQuery(`FROM "services" WHERE jsonb_extract_path_text`).WithArgs("team", "team").WillReturnRows(serviceRows)
+	mock.ExpectQuery(`FROM "versions" JOIN services`).WithArgs("team", "team").WillReturnRows(versionRows)
+
+	driftRows := sqlmock.NewRows([]string{"name"})
+	for _, name := range drifted {
+		driftRows.AddRow(name)
+	}
+	mock.ExpectQuery(`HAVING count\(versions.id\) <> services.version_count`).WillReturnRows(driftRows)
+}
+
+func TestRefresh(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	database, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+
+	expectCount(mock, map[string]int{"payments": 2, "search": 1}, "search-api")
+	require.NoError(t, Refresh(database, []string{"team"}))
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(services))
+	assert.Equal(t, float64(7), testutil.ToFloat64(versions))
+	assert.Equal(t, float64(2), testutil.ToFloat64(servicesByAttribute.WithLabelValues("team", "payments")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(versionsByAttribute.WithLabelValues("team", "search")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(versionCountDrift))
+
+	// the last search service moved to another team
+	expectCount(mock, map[string]int{"payments": 3})
+	require.NoError(t, Refresh(database, []string{"team"}))
+
+	assert.Equal(t, 1, testutil.CollectAndCount(servicesByAttribute))
+	assert.Equal(t, float64(3), testutil.ToFloat64(servicesByAttribute.WithLabelValues("team", "payments")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(versionCountDrift))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
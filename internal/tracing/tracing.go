@@ -0,0 +1,153 @@
+// Package tracing records the spans of the requests, of the controller calls
+// they make and of the queries those run, with OpenTelemetry. Traces are
+// continued from, and propagated to, the W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Prashansa-K/serviceCatalog/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"gorm.io/gorm"
+)
+
+// names the spans started by the service itself
+const TRACER_NAME = "github.com/Prashansa-K/serviceCatalog"
+
+var tracer = ChildSpans().Tracer(TRACER_NAME)
+
+// Init installs the tracer provider and the propagators. The returned
+// function flushes the spans left and stops exporting, on shutdown.
+func Init(ctx context.Context, tracingConfig *config.TracingConfig) (func(context.Context) error, error) {
+	exporter, closer, err := newExporter(ctx, tracingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(tracingConfig.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tracingConfig.SampleRatio))),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// newExporter returns no exporter when spans are not exported, and the file
+// written to by the file exporter
+func newExporter(ctx context.Context, tracingConfig *config.TracingConfig) (sdktrace.SpanExporter, io.Closer, error) {
+	switch tracingConfig.Exporter {
+	case config.TRACING_EXPORTER_OTLP:
+		exporter, err := otlptracegrpc.New(ctx)
+		return exporter, nil, err
+	case config.TRACING_EXPORTER_STDOUT:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case config.TRACING_EXPORTER_FILE:
+		if err := os.MkdirAll(filepath.Dir(tracingConfig.Path), 0755); err != nil {
+			return nil, nil, err
+		}
+		file, err := os.OpenFile(tracingConfig.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		return exporter, file, err
+	default:
+		return nil, nil, nil
+	}
+}
+
+// Start starts a span named after its caller, e.g. controllers.CreateService,
+// as a child of the span of db's context. The queries run with the returned
+// db are children of the new span, which the caller ends.
+func Start(db *gorm.DB) (*gorm.DB, trace.Span) {
+	name := "unknown"
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		// the package path is left out, e.g. github.com/Prashansa-K/serviceCatalog/internal/
+		name = runtime.FuncForPC(pc).Name()
+		name = name[strings.LastIndex(name, "/")+1:]
+	}
+
+	ctx, span := tracer.Start(db.Statement.Context, name)
+
+	return db.WithContext(ctx), span
+}
+
+// TraceID is the ID of the trace ctx is part of, empty when none
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+// ChildSpans is the global tracer provider, except that spans are only started
+// as part of an existing trace, e.g. of a request. The queries of the outbox
+// relay and the like then do not each make a trace of their own.
+func ChildSpans() trace.TracerProvider {
+	return childSpansProvider{}
+}
+
+type childSpansProvider struct {
+	embedded.TracerProvider
+}
+
+func (childSpansProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return childSpansTracer{name: name, options: options}
+}
+
+// childSpansTracer gets its tracer from the global provider on each span, for
+// the provider to be replaced, e.g. by tests
+type childSpansTracer struct {
+	embedded.Tracer
+
+	name    string
+	options []trace.TracerOption
+}
+
+func (t childSpansTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	// the span of ctx, which records nothing
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	return otel.GetTracerProvider().Tracer(t.name, t.options...).Start(ctx, name, options...)
+}
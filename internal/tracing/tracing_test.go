@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+// tracedDB runs its queries with the plugin installed by the db package
+func tracedDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(otelgorm.NewPlugin(otelgorm.WithTracerProvider(ChildSpans()), otelgorm.WithoutMetrics())))
+
+	return db, mock
+}
+
+// countServices stands for a controller function
+func countServices(db *gorm.DB) error {
+	db, span := Start(db)
+	defer span.End()
+
+	var count int64
+	return db.Raw("SELECT count(*) FROM services").Scan(&count).Error
+}
+
+func TestStart_SpansOfAControllerCall(t *testing.T) {
+	recorder := recordSpans(t)
+	db, mock := tracedDB(t)
+	mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "GET /v1/services")
+	require.NoError(t, countServices(db.WithContext(ctx)))
+	request.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	query, controller := spans[0], spans[1]
+	assert.Equal(t, "gorm.Row", query.Name())
+	assert.Equal(t, "tracing.countServices", controller.Name())
+	assert.Equal(t, controller.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, request.SpanContext().SpanID(), controller.Parent().SpanID())
+	assert.Equal(t, request.SpanContext().TraceID().String(), TraceID(ctx))
+}
+
+func TestStart_NoTraceOutsideOfARequest(t *testing.T) {
+	recorder := recordSpans(t)
+	db, mock := tracedDB(t)
+	mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	require.NoError(t, countServices(db))
+
+	assert.Empty(t, recorder.Ended())
+	assert.Empty(t, TraceID(context.Background()))
+}
@@ -1,13 +0,0 @@
-package internal
-
-import (
-	"os"
-)
-
-func GetEnvWithDefault(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
-}
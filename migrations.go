@@ -0,0 +1,35 @@
+package servicecatalog
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// LatestMigration is the version of the last migration shipped, which the
+// database has to be migrated to
+func LatestMigration() (int, error) {
+	files, err := fs.Glob(Migrations, "migrations/*.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	latest := -1
+	for _, file := range files {
+		version, err := strconv.Atoi(strings.TrimSuffix(path.Base(file), ".sql"))
+		if err != nil {
+			return 0, err
+		}
+
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
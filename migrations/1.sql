@@ -0,0 +1,15 @@
+--- Creating event log for the change stream
+CREATE TABLE IF NOT EXISTS events (
+  id BIGSERIAL PRIMARY KEY,
+  xid BIGINT NOT NULL DEFAULT pg_current_xact_id()::text::bigint,
+  type VARCHAR(64) NOT NULL,
+  service_name VARCHAR(255) NOT NULL,
+  version_name VARCHAR(255) NULL,
+  data JSONB,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS events_service_name_idx ON events (service_name);
+CREATE INDEX IF NOT EXISTS events_type_idx ON events (type);
+-- streams read the events in the order of their transactions
+CREATE INDEX IF NOT EXISTS events_xid_id_idx ON events (xid, id);
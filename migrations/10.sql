@@ -0,0 +1,9 @@
+--- Recording the migrations applied, readiness checks them against the ones shipped
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INT PRIMARY KEY,
+  applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- migrations are applied in order, so the previous ones were too. Every
+-- migration from now on ends by recording its own version.
+INSERT INTO schema_migrations (version) SELECT generate_series(0, 10) ON CONFLICT DO NOTHING;
@@ -0,0 +1,16 @@
+--- Creating transactional outbox for reliable event publication
+CREATE TABLE IF NOT EXISTS outbox (
+  id BIGSERIAL PRIMARY KEY,
+  event_id BIGINT NOT NULL,
+  type VARCHAR(64) NOT NULL,
+  payload JSONB NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  published_at TIMESTAMP NULL,
+  FOREIGN KEY (event_id) REFERENCES events(id)
+);
+
+-- the relay only ever looks for unpublished rows
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at) WHERE published_at IS NULL;
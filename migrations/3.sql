@@ -0,0 +1,28 @@
+--- Tracking changes made to services
+ALTER TABLE services ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE services ADD COLUMN IF NOT EXISTS revision INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS service_revisions (
+  id BIGSERIAL PRIMARY KEY,
+  service_id INT NOT NULL,
+  revision INT NOT NULL,
+  changes JSONB NOT NULL,
+  snapshot JSONB NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (service_id, revision),
+  FOREIGN KEY (service_id) REFERENCES services(id)
+);
+
+-- existing services start their history with their current state
+INSERT INTO service_revisions (service_id, revision, changes, snapshot, created_at)
+SELECT id, 1,
+  jsonb_build_array(
+    jsonb_build_object('field', 'name', 'from', NULL, 'to', name),
+    jsonb_build_object('field', 'description', 'from', NULL, 'to', description)
+  ),
+  jsonb_build_object('name', name, 'description', description),
+  created_at
+FROM services
+WHERE revision = 0;
+
+UPDATE services SET revision = 1, updated_at = created_at WHERE revision = 0;
@@ -0,0 +1,4 @@
+--- Adding links, contacts and custom attributes to services
+ALTER TABLE services ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE services ADD COLUMN IF NOT EXISTS contacts JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE services ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
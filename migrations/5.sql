@@ -0,0 +1,14 @@
+--- Recording the artifacts shipped as each version
+CREATE TABLE IF NOT EXISTS artifacts (
+  id BIGSERIAL PRIMARY KEY,
+  version_id INT NOT NULL,
+  type VARCHAR(16) NOT NULL,
+  reference TEXT NULL,
+  digest VARCHAR(128) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (version_id) REFERENCES versions(id)
+);
+
+CREATE INDEX IF NOT EXISTS artifacts_version_id_idx ON artifacts (version_id);
+-- on-call looks up running images by digest
+CREATE INDEX IF NOT EXISTS artifacts_digest_idx ON artifacts (digest);
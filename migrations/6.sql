@@ -0,0 +1,29 @@
+--- Tracking which version of each service is deployed to each environment
+CREATE TABLE IF NOT EXISTS environments (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(32) NOT NULL UNIQUE,
+  description TEXT,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO environments (name, description) VALUES
+  ('dev', 'Development'),
+  ('staging', 'Staging'),
+  ('prod', 'Production')
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS deployments (
+  id BIGSERIAL PRIMARY KEY,
+  service_id INT NOT NULL,
+  version_id INT NOT NULL,
+  environment_id INT NOT NULL,
+  actor VARCHAR(255) NOT NULL,
+  deployed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (service_id) REFERENCES services(id),
+  FOREIGN KEY (version_id) REFERENCES versions(id),
+  FOREIGN KEY (environment_id) REFERENCES environments(id)
+);
+
+-- the latest deployment of a service to an environment is the one running there
+CREATE INDEX IF NOT EXISTS deployments_environment_id_service_id_idx ON deployments (environment_id, service_id, id);
+CREATE INDEX IF NOT EXISTS deployments_service_id_idx ON deployments (service_id, id);
@@ -0,0 +1,2 @@
+--- Adding release notes to versions
+ALTER TABLE versions ADD COLUMN IF NOT EXISTS release_notes JSONB NOT NULL DEFAULT '{}';
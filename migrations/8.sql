@@ -0,0 +1,30 @@
+--- Adding channels, movable names pointing to a version of a service
+CREATE TABLE IF NOT EXISTS channels (
+  id SERIAL PRIMARY KEY,
+  service_id INT NOT NULL,
+  name VARCHAR(32) NOT NULL,
+  version_id INT NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (service_id) REFERENCES services(id),
+  FOREIGN KEY (version_id) REFERENCES versions(id),
+  CONSTRAINT unique_service_channel UNIQUE (service_id, name)
+);
+
+-- a version pointed to by a channel can not be deleted
+CREATE INDEX IF NOT EXISTS channels_version_id_idx ON channels (version_id);
+
+-- moves outlive the channel, so they refer to it by name
+CREATE TABLE IF NOT EXISTS channel_moves (
+  id BIGSERIAL PRIMARY KEY,
+  service_id INT NOT NULL,
+  channel VARCHAR(32) NOT NULL,
+  from_version_id INT NULL,
+  to_version_id INT NULL,
+  moved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (service_id) REFERENCES services(id),
+  FOREIGN KEY (from_version_id) REFERENCES versions(id),
+  FOREIGN KEY (to_version_id) REFERENCES versions(id)
+);
+
+CREATE INDEX IF NOT EXISTS channel_moves_service_id_channel_idx ON channel_moves (service_id, channel, id);
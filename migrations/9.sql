@@ -0,0 +1,14 @@
+--- Keeping the previous names of renamed services as aliases
+CREATE TABLE IF NOT EXISTS service_aliases (
+  id SERIAL PRIMARY KEY,
+  service_id INT NOT NULL,
+  name VARCHAR(255) NOT NULL UNIQUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (service_id) REFERENCES services(id)
+);
+
+CREATE INDEX IF NOT EXISTS service_aliases_service_id_idx ON service_aliases (service_id);
+
+-- names of the services in the catalog are unique, duplicates have to be
+-- renamed before running this migration
+CREATE UNIQUE INDEX IF NOT EXISTS services_name_idx ON services (name) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+// Package servicecatalog holds the assets living at the root of the repository
+package servicecatalog
+
+import _ "embed"
+
+//go:embed openapi_spec.yaml
+var OpenAPISpec []byte
@@ -0,0 +1,454 @@
+// Package client is a Go client of the service catalog v1 REST APIs.
+//
+//	catalog := client.New("http://localhost:8080", os.Getenv("API_AUTH_KEY"))
+//	service, err := catalog.GetService(ctx, "payments", 1)
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT     = 30 * time.Second
+	DEFAULT_MAX_RETRIES = 3
+
+	// backoff when a 429 carries no usable Retry-After
+	DEFAULT_RETRY_BACKOFF = 500 * time.Millisecond
+	MAX_RETRY_BACKOFF     = 30 * time.Second
+)
+
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default client, e.g. to set up TLS or tracing
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithMaxRetries sets how many times a rate limited request is retried, 0 disables retries
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// New returns a client of the catalog served at baseURL, e.g. http://localhost:8080
+func New(baseURL, apiKey string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: DEFAULT_TIMEOUT},
+		maxRetries: DEFAULT_MAX_RETRIES,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+func (c *Client) ListServices(ctx context.Context, options ListServicesOptions) (*ServicePage, error) {
+	query := url.Values{}
+	if options.Page > 0 {
+		query.Set("page", strconv.Itoa(options.Page))
+	}
+	if options.Sort != "" {
+		query.Set("sort", options.Sort)
+	}
+	if options.Name != "" {
+		query.Set("name", options.Name)
+	}
+	if options.Description != "" {
+		query.Set("description", options.Description)
+	}
+	for path, value := range options.Attributes {
+		query.Set("attributes["+path+"]", value)
+	}
+
+	path := "/v1/services"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var page ServicePage
+	if err := c.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// GetService returns the service with the given page of its versions
+func (c *Client) GetService(ctx context.Context, name string, versionsPage int) (*ServiceWithVersions, error) {
+	path := "/v1/service/" + url.PathEscape(name)
+	if versionsPage > 0 {
+		path += "?page=" + strconv.Itoa(versionsPage)
+	}
+
+	var service ServiceWithVersions
+	if err := c.do(ctx, http.MethodGet, path, nil, &service); err != nil {
+		return nil, err
+	}
+
+	return &service, nil
+}
+
+func (c *Client) GetServiceHistory(ctx context.Context, name string, page int) (*ServiceHistory, error) {
+	path := "/v1/service/" + url.PathEscape(name) + "/history"
+	if page > 0 {
+		path += "?page=" + strconv.Itoa(page)
+	}
+
+	var history ServiceHistory
+	if err := c.do(ctx, http.MethodGet, path, nil, &history); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+func (c *Client) GetServiceRevision(ctx context.Context, name string, revision int) (*ServiceAtRevision, error) {
+	path := "/v1/service/" + url.PathEscape(name) + "/history/" + strconv.Itoa(revision)
+
+	var service ServiceAtRevision
+	if err := c.do(ctx, http.MethodGet, path, nil, &service); err != nil {
+		return nil, err
+	}
+
+	return &service, nil
+}
+
+// ListServiceAliases lists the previous names of a service, latest first
+func (c *Client) ListServiceAliases(ctx context.Context, name string) ([]ServiceAlias, error) {
+	var response struct {
+		Aliases []ServiceAlias `json:"aliases"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/service/"+url.PathEscape(name)+"/aliases", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Aliases, nil
+}
+
+func (c *Client) CreateService(ctx context.Context, request CreateServiceRequest) error {
+	return c.do(ctx, http.MethodPost, "/v1/service", request, nil)
+}
+
+func (c *Client) UpdateService(ctx context.Context, request UpdateServiceRequest) error {
+	return c.do(ctx, http.MethodPatch, "/v1/service", request, nil)
+}
+
+// DeleteService soft deletes the service along with all its versions
+func (c *Client) DeleteService(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/service/"+url.PathEscape(name), nil, nil)
+}
+
+func (c *Client) CreateVersion(ctx context.Context, request CreateVersionRequest) error {
+	return c.do(ctx, http.MethodPost, "/v1/service/version", request, nil)
+}
+
+func (c *Client) GetVersion(ctx context.Context, serviceName, versionName string) (*VersionWithArtifacts, error) {
+	path := fmt.Sprintf("/v1/service/%s/version/%s", url.PathEscape(serviceName), url.PathEscape(versionName))
+
+	var version VersionWithArtifacts
+	if err := c.do(ctx, http.MethodGet, path, nil, &version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// LookupArtifact finds the versions which shipped an artifact by its digest,
+// a bare sha256 hex also matches sha256:<hex>
+func (c *Client) LookupArtifact(ctx context.Context, digest string) (*ArtifactLookup, error) {
+	var lookup ArtifactLookup
+	if err := c.do(ctx, http.MethodGet, "/v1/artifacts/"+url.PathEscape(digest), nil, &lookup); err != nil {
+		return nil, err
+	}
+
+	return &lookup, nil
+}
+
+// GetChangelog lists the release notes of the versions between from and to,
+// both included. Either bound may be empty to leave the range open.
+func (c *Client) GetChangelog(ctx context.Context, serviceName, from, to string) (*Changelog, error) {
+	var changelog Changelog
+	if err := c.do(ctx, http.MethodGet, changelogPath(serviceName, from, to, ""), nil, &changelog); err != nil {
+		return nil, err
+	}
+
+	return &changelog, nil
+}
+
+// RenderChangelog returns the changelog rendered by the server in format,
+// which is one of markdown, keep-a-changelog or html
+func (c *Client) RenderChangelog(ctx context.Context, serviceName, from, to, format string) (string, error) {
+	var page []byte
+	if err := c.do(ctx, http.MethodGet, changelogPath(serviceName, from, to, format), nil, &page); err != nil {
+		return "", err
+	}
+
+	return string(page), nil
+}
+
+func changelogPath(serviceName, from, to, format string) string {
+	query := url.Values{}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+	if format != "" {
+		query.Set("format", format)
+	}
+
+	path := "/v1/service/" + url.PathEscape(serviceName) + "/changelog"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return path
+}
+
+// DeleteVersion fails with ErrConflict when the version is currently deployed
+// to an environment or a channel points to it
+func (c *Client) DeleteVersion(ctx context.Context, serviceName, versionName string) error {
+	path := fmt.Sprintf("/v1/service/%s/version/%s", url.PathEscape(serviceName), url.PathEscape(versionName))
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// ForceDeleteVersion deletes the version even when it is currently deployed,
+// but not while a channel points to it
+func (c *Client) ForceDeleteVersion(ctx context.Context, serviceName, versionName string) error {
+	path := fmt.Sprintf("/v1/service/%s/version/%s?force=true", url.PathEscape(serviceName), url.PathEscape(versionName))
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// ListChannels lists the channels of a service by name
+func (c *Client) ListChannels(ctx context.Context, serviceName string) ([]Channel, error) {
+	var response struct {
+		Channels []Channel `json:"channels"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/service/"+url.PathEscape(serviceName)+"/channels", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Channels, nil
+}
+
+// SetChannel points the channel to a version, named by itself or by another
+// channel, creating the channel when it does not exist yet
+func (c *Client) SetChannel(ctx context.Context, serviceName, channel, version string) error {
+	request := struct {
+		Version string `json:"version"`
+	}{version}
+
+	return c.do(ctx, http.MethodPut, channelPath(serviceName, channel), request, nil)
+}
+
+func (c *Client) DeleteChannel(ctx context.Context, serviceName, channel string) error {
+	return c.do(ctx, http.MethodDelete, channelPath(serviceName, channel), nil, nil)
+}
+
+// GetChannelHistory lists the moves of a channel, including those of a
+// channel deleted since
+func (c *Client) GetChannelHistory(ctx context.Context, serviceName, channel string, page int) (*ChannelHistory, error) {
+	path := channelPath(serviceName, channel) + "/history"
+	if page > 0 {
+		path += "?page=" + strconv.Itoa(page)
+	}
+
+	var history ChannelHistory
+	if err := c.do(ctx, http.MethodGet, path, nil, &history); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+func channelPath(serviceName, channel string) string {
+	return fmt.Sprintf("/v1/service/%s/channels/%s", url.PathEscape(serviceName), url.PathEscape(channel))
+}
+
+func (c *Client) ListEnvironments(ctx context.Context) ([]Environment, error) {
+	var response struct {
+		Environments []Environment `json:"environments"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/environments", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Environments, nil
+}
+
+func (c *Client) CreateEnvironment(ctx context.Context, request CreateEnvironmentRequest) error {
+	return c.do(ctx, http.MethodPost, "/v1/environments", request, nil)
+}
+
+// Deploy records a version of the service being deployed to an environment
+func (c *Client) Deploy(ctx context.Context, serviceName string, request DeployRequest) error {
+	return c.do(ctx, http.MethodPost, "/v1/service/"+url.PathEscape(serviceName)+"/deployments", request, nil)
+}
+
+// GetDeploymentHistory lists the deployments of a service, only those to the
+// environment when it is not empty
+func (c *Client) GetDeploymentHistory(ctx context.Context, serviceName, environment string, page int) (*DeploymentHistory, error) {
+	query := url.Values{}
+	if environment != "" {
+		query.Set("environment", environment)
+	}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+
+	path := "/v1/service/" + url.PathEscape(serviceName) + "/deployments"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var history DeploymentHistory
+	if err := c.do(ctx, http.MethodGet, path, nil, &history); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// GetEnvironmentServices lists the version of each service deployed to the
+// environment
+func (c *Client) GetEnvironmentServices(ctx context.Context, environment string, page int) (*EnvironmentServices, error) {
+	path := "/v1/environments/" + url.PathEscape(environment) + "/services"
+	if page > 0 {
+		path += "?page=" + strconv.Itoa(page)
+	}
+
+	var services EnvironmentServices
+	if err := c.do(ctx, http.MethodGet, path, nil, &services); err != nil {
+		return nil, err
+	}
+
+	return &services, nil
+}
+
+// do sends the request, retrying it while it is rate limited, and decodes a
+// successful response into out when it is not nil. A *[]byte out receives
+// the body as is.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		statusCode, header, data, err := c.send(ctx, method, path, payload)
+		if err != nil {
+			return err
+		}
+
+		if statusCode < http.StatusBadRequest {
+			if out == nil {
+				return nil
+			}
+			if raw, ok := out.(*[]byte); ok {
+				*raw = data
+				return nil
+			}
+			return json.Unmarshal(data, out)
+		}
+
+		if statusCode != http.StatusTooManyRequests || attempt >= c.maxRetries {
+			return newError(statusCode, data)
+		}
+
+		timer := time.NewTimer(retryDelay(header, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte) (int, http.Header, []byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return response.StatusCode, response.Header, data, nil
+}
+
+// retryDelay honours Retry-After, in seconds or as an HTTP date, and falls
+// back to an exponential backoff
+func retryDelay(header http.Header, attempt int) time.Duration {
+	delay := MAX_RETRY_BACKOFF
+	if attempt < 16 {
+		delay = DEFAULT_RETRY_BACKOFF << attempt
+	}
+
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			delay = time.Until(date)
+		}
+	}
+
+	if delay < 0 {
+		return 0
+	}
+	if delay > MAX_RETRY_BACKOFF {
+		return MAX_RETRY_BACKOFF
+	}
+
+	return delay
+}
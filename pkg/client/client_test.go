@@ -0,0 +1,399 @@
+package client
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Prashansa-K/serviceCatalog/config"
+	"github.com/Prashansa-K/serviceCatalog/internal/db"
+	"github.com/Prashansa-K/serviceCatalog/internal/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const TEST_API_KEY = "test-key"
+
+// useAuthKey has the API accept key, the configuration being restored after
+// the test
+func useAuthKey(t *testing.T, key string) {
+	previous := config.Current()
+	t.Cleanup(func() { config.Set(previous) })
+
+	authenticated := *previous
+	authenticated.Auth = config.AuthConfig{Key: key}
+	config.Set(&authenticated)
+}
+
+// newTestServer serves the real routes on top of a mocked DB
+func newTestServer(t *testing.T) (*httptest.Server, sqlmock.Sqlmock) {
+	useAuthKey(t, TEST_API_KEY)
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db.DB, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	app := echo.New()
+	routes.RegisterAPI(app)
+	server := httptest.NewServer(app)
+
+	t.Cleanup(func() {
+		server.Close()
+		db.DB = nil
+	})
+
+	return server, mock
+}
+
+func expectServicesPage(mock sqlmock.Sqlmock, total int, offset int, names ...string) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "services" WHERE LOWER(name) LIKE $1 AND "services"."deleted_at" IS NULL`)).
+		WithArgs(`%pay%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(total))
+
+	query := `SELECT * FROM "services" WHERE LOWER(name) LIKE $1 AND "services"."deleted_at" IS NULL ORDER BY name DESC LIMIT $2`
+	args := []driver.Value{`%pay%`, 2}
+	if offset > 0 {
+		query += ` OFFSET $3`
+		args = append(args, offset)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "version_count"})
+	for i, name := range names {
+		rows.AddRow(offset+i+1, name, "", 0)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(args...).
+		WillReturnRows(rows)
+}
+
+func TestAllServices_WalksEveryPage(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+
+	expectServicesPage(mock, 3, 0, "payments-v2", "payments")
+	expectServicesPage(mock, 3, 2, "pay-later")
+
+	var names []string
+	services := catalog.AllServices(context.Background(), ListServicesOptions{Name: "pay", Sort: SortDescending})
+	for services.Next() {
+		names = append(names, services.Service().Name)
+	}
+
+	require.NoError(t, services.Err())
+	assert.Equal(t, []string{"payments-v2", "payments", "pay-later"}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetService_Success(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+
+	createdAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "versions" JOIN services ON versions.service_id = services.id WHERE services.name = $1 AND "versions"."deleted_at" IS NULL`)).
+		WithArgs("payments").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).
+			AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1 AND "services"."deleted_at" IS NULL ORDER BY "services"."id" LIMIT $2`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "version_count", "created_at"}).
+			AddRow(123, "payments", "Payments", 1, createdAt))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE "versions"."service_id" = $1 AND "versions"."deleted_at" IS NULL LIMIT $2`)).
+		WithArgs(123, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id", "description", "created_at"}).
+			AddRow(1, "v1", 123, "Version 1", createdAt))
+
+	service, err := catalog.GetService(context.Background(), "payments", 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(123), service.ID)
+	assert.Equal(t, "payments", service.Name)
+	assert.True(t, createdAt.Equal(service.CreatedAt))
+	require.Len(t, service.Versions, 1)
+	assert.Equal(t, "Version 1", service.Versions[0].Description)
+	assert.True(t, createdAt.Equal(service.Versions[0].CreatedAt))
+	assert.Equal(t, 1, service.TotalPages)
+	assert.Equal(t, int64(1), service.TotalVersionRecords)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTypedErrors(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("missing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WithArgs("missing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}))
+	mock.ExpectRollback()
+
+	err := catalog.DeleteService(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var apiError *Error
+	require.True(t, errors.As(err, &apiError))
+	assert.Equal(t, "service not found", apiError.Message)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(123, "payments"))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "versions"`)).
+		WillReturnError(errors.New(`duplicate key value violates unique constraint "unique_service_version"`))
+	mock.ExpectRollback()
+
+	err = catalog.CreateVersion(ctx, CreateVersionRequest{ServiceName: "payments", Name: "v1"})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	err = New(server.URL, "wrong-key").DeleteService(ctx, "payments")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLookupArtifact(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+	ctx := context.Background()
+
+	digest := "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name AS service_name`)).
+		WithArgs(digest).
+		WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "type", "reference", "digest", "created_at"}).
+			AddRow("payments", "v1", ArtifactImage, "ghcr.io/acme/payments:1.0.0", digest, time.Now()))
+
+	lookup, err := catalog.LookupArtifact(ctx, digest)
+	require.NoError(t, err)
+	assert.Equal(t, digest, lookup.Digest)
+	require.Len(t, lookup.Matches, 1)
+	assert.Equal(t, "payments", lookup.Matches[0].ServiceName)
+	assert.Equal(t, "v1", lookup.Matches[0].VersionName)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name AS service_name`)).
+		WithArgs(digest).
+		WillReturnRows(sqlmock.NewRows([]string{"service_name", "version_name", "type", "reference", "digest", "created_at"}))
+
+	_, err = catalog.LookupArtifact(ctx, digest)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var apiError *Error
+	_, err = catalog.LookupArtifact(ctx, "latest")
+	require.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusBadRequest, apiError.StatusCode)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChangelog(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+	ctx := context.Background()
+
+	expectVersions := func() {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+			WithArgs("payments", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(123, "payments"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE service_id = $1`)).
+			WithArgs(123).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at", "release_notes"}).
+				AddRow(1, "v1.0.0", time.Now().Add(-time.Hour), `{"added":["Payments API"]}`).
+				AddRow(2, "v1.1.0", time.Now(), `{"fixed":["Rounding of totals"]}`))
+	}
+
+	expectVersions()
+	changelog, err := catalog.GetChangelog(ctx, "payments", "", "v1.1.0")
+	require.NoError(t, err)
+	require.Len(t, changelog.Versions, 2)
+	assert.Equal(t, "v1.1.0", changelog.Versions[0].Name)
+	assert.Equal(t, []string{"Rounding of totals"}, changelog.Versions[0].ReleaseNotes.Fixed)
+
+	expectVersions()
+	page, err := catalog.RenderChangelog(ctx, "payments", "v1.1.0", "", "keep-a-changelog")
+	require.NoError(t, err)
+	assert.Contains(t, page, "## [v1.1.0] - ")
+	assert.Contains(t, page, "### Fixed\n\n- Rounding of totals\n")
+	assert.NotContains(t, page, "v1.0.0")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestServiceAliases_FollowsRename(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+	ctx := context.Background()
+
+	// the previous name is redirected to the current one
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("billing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT services.name, service_aliases.created_at AS renamed_at FROM "service_aliases"`)).
+		WithArgs("billing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "renamed_at"}).AddRow("payments", time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+		WithArgs("payments", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(123, "payments"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "service_aliases" WHERE service_id = $1 ORDER BY id DESC`)).
+		WithArgs(123).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "name", "created_at"}).AddRow(1, 123, "billing", time.Now()))
+
+	aliases, err := catalog.ListServiceAliases(ctx, "billing")
+	require.NoError(t, err)
+	require.Len(t, aliases, 1)
+	assert.Equal(t, "billing", aliases[0].Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChannels(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+	ctx := context.Background()
+
+	expectServiceFound := func() {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+			WithArgs("payments", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(123, "payments"))
+	}
+
+	mock.ExpectBegin()
+	expectServiceFound()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE (service_id = $1 AND name = $2)`)).
+		WithArgs(123, "v1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).AddRow(7, "v1", 123))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "channels" WHERE service_id = $1 AND name = $2`)).
+		WithArgs(123, "stable", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channels"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "channel_moves"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "events"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	require.NoError(t, catalog.SetChannel(ctx, "payments", "stable", "v1"))
+
+	expectServiceFound()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT channels.name, versions.name AS version_name`)).
+		WithArgs(123).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "version_name", "updated_at"}).AddRow("stable", "v1", time.Now()))
+
+	channels, err := catalog.ListChannels(ctx, "payments")
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, "v1", channels[0].VersionName)
+
+	expectServiceFound()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "channel_moves"`)).
+		WithArgs(123, "lts").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	_, err = catalog.GetChannelHistory(ctx, "payments", "lts", 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeployedVersion(t *testing.T) {
+	server, mock := newTestServer(t)
+	catalog := New(server.URL, TEST_API_KEY)
+	ctx := context.Background()
+
+	expectVersionFound := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "services" WHERE name = $1`)).
+			WithArgs("payments", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version_count"}).
+				AddRow(123, "payments", 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "versions" WHERE (service_id = $1 AND name = $2)`)).
+			WithArgs(123, "v1", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "service_id"}).
+				AddRow(7, "v1", 123))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "name" FROM "channels"`)).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	}
+
+	expectVersionFound()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "environments"."name" FROM "deployments"`)).
+		WithArgs(123, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("prod"))
+	mock.ExpectRollback()
+
+	err := catalog.DeleteVersion(ctx, "payments", "v1")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	// forcing skips the check
+	expectVersionFound()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "versions" SET "deleted_at"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "services"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "events"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	err = catalog.ForceDeleteVersion(ctx, "payments", "v1")
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRateLimitedRequestsAreRetried(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	// use up the burst allowed by the rate limiter
+	for i := 0; i < config.GetRateLimitConfig().Burst; i++ {
+		response, err := http.Get(server.URL + "/ping")
+		require.NoError(t, err)
+		response.Body.Close()
+	}
+
+	err := New(server.URL, TEST_API_KEY, WithMaxRetries(0)).DeleteVersion(context.Background(), "payments", "v1")
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	// Retry-After asks for a second, by which the limiter has refilled
+	start := time.Now()
+	err = New(server.URL, "wrong-key").DeleteService(context.Background(), "payments")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryDelay(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, DEFAULT_RETRY_BACKOFF, retryDelay(header, 0))
+	assert.Equal(t, 4*DEFAULT_RETRY_BACKOFF, retryDelay(header, 2))
+	assert.Equal(t, MAX_RETRY_BACKOFF, retryDelay(header, 100))
+
+	header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, retryDelay(header, 5))
+
+	header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.Equal(t, time.Duration(0), retryDelay(header, 0))
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinels matched by errors.Is against an *Error
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// Error is a non 2xx response of the catalog
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("service catalog: %s (%d)", e.Message, e.StatusCode)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// newError reads the message of an error response, which the API sends either
+// as a bare JSON string or as {"error": ...} / {"message": ...}
+func newError(statusCode int, body []byte) *Error {
+	apiError := &Error{StatusCode: statusCode, Message: http.StatusText(statusCode)}
+
+	var message string
+	if err := json.Unmarshal(body, &message); err == nil && message != "" {
+		apiError.Message = message
+		return apiError
+	}
+
+	var object struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &object); err == nil {
+		if object.Error != "" {
+			apiError.Message = object.Error
+		} else if object.Message != "" {
+			apiError.Message = object.Message
+		}
+	}
+
+	return apiError
+}
@@ -0,0 +1,121 @@
+package client
+
+import "context"
+
+// ServiceIterator walks every page of GET /v1/services, fetching them as needed
+//
+//	services := catalog.AllServices(ctx, client.ListServicesOptions{Name: "pay"})
+//	for services.Next() {
+//		fmt.Println(services.Service().Name)
+//	}
+//	if err := services.Err(); err != nil {
+//		...
+//	}
+type ServiceIterator struct {
+	client  *Client
+	ctx     context.Context
+	options ListServicesOptions
+
+	services []Service
+	index    int
+	lastPage bool
+	err      error
+}
+
+// AllServices iterates over the services matching the options, starting at
+// options.Page when it is set
+func (c *Client) AllServices(ctx context.Context, options ListServicesOptions) *ServiceIterator {
+	if options.Page < 1 {
+		options.Page = 1
+	}
+
+	return &ServiceIterator{client: c, ctx: ctx, options: options, index: -1}
+}
+
+func (it *ServiceIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.services) {
+		if it.lastPage {
+			return false
+		}
+
+		page, err := it.client.ListServices(it.ctx, it.options)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.services = page.Services
+		it.index = 0
+		it.lastPage = page.CurrentPage >= page.TotalPages
+		it.options.Page++
+	}
+
+	return true
+}
+
+// Service returns the current service, valid after Next returned true
+func (it *ServiceIterator) Service() Service {
+	return it.services[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ServiceIterator) Err() error {
+	return it.err
+}
+
+// VersionIterator walks every page of versions of a service
+type VersionIterator struct {
+	client      *Client
+	ctx         context.Context
+	serviceName string
+	page        int
+
+	versions []Version
+	index    int
+	lastPage bool
+	err      error
+}
+
+func (c *Client) AllVersions(ctx context.Context, serviceName string) *VersionIterator {
+	return &VersionIterator{client: c, ctx: ctx, serviceName: serviceName, page: 1, index: -1}
+}
+
+func (it *VersionIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.versions) {
+		if it.lastPage {
+			return false
+		}
+
+		service, err := it.client.GetService(it.ctx, it.serviceName, it.page)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.versions = service.Versions
+		it.index = 0
+		it.lastPage = service.CurrentPage >= service.TotalPages
+		it.page++
+	}
+
+	return true
+}
+
+// Version returns the current version, valid after Next returned true
+func (it *VersionIterator) Version() Version {
+	return it.versions[it.index]
+}
+
+func (it *VersionIterator) Err() error {
+	return it.err
+}
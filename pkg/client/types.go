@@ -0,0 +1,285 @@
+package client
+
+import "time"
+
+// Sort orders accepted when listing services, which are sorted by name
+const (
+	SortAscending  = "ASC"
+	SortDescending = "DESC"
+)
+
+// Types of the links of a service
+const (
+	LinkRepository = "repository"
+	LinkRunbook    = "runbook"
+	LinkDashboard  = "dashboard"
+	LinkDocs       = "docs"
+)
+
+// Types of the artifacts shipped as a version
+const (
+	ArtifactImage  = "image"
+	ArtifactBinary = "binary"
+	ArtifactCommit = "commit"
+)
+
+type Link struct {
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+type Contact struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role,omitempty"`
+}
+
+// Artifact is an image with its digest, a binary with its checksum or a
+// commit hash. The catalog normalizes digests to sha256:<hex>.
+type Artifact struct {
+	Type      string `json:"type"`
+	Reference string `json:"reference,omitempty"`
+	Digest    string `json:"digest"`
+}
+
+type Service struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	VersionCount int       `json:"version_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Links      []Link                 `json:"links"`
+	Contacts   []Contact              `json:"contacts"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type Version struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// VersionWithArtifacts is a version as returned by
+// GET /v1/service/:serviceName/version/:versionName
+type VersionWithArtifacts struct {
+	Name         string       `json:"name"`
+	ServiceName  string       `json:"service_name"`
+	Description  string       `json:"description"`
+	CreatedAt    time.Time    `json:"created_at"`
+	Artifacts    []Artifact   `json:"artifacts"`
+	ReleaseNotes ReleaseNotes `json:"release_notes"`
+}
+
+// ReleaseNotes are the changes a version made, by section
+type ReleaseNotes struct {
+	Added    []string `json:"added,omitempty"`
+	Changed  []string `json:"changed,omitempty"`
+	Fixed    []string `json:"fixed,omitempty"`
+	Security []string `json:"security,omitempty"`
+}
+
+type VersionReleaseNotes struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ReleaseNotes ReleaseNotes `json:"release_notes"`
+}
+
+// Changelog is the release notes of a range of versions of a service, latest
+// first, as returned by GET /v1/service/:serviceName/changelog
+type Changelog struct {
+	ServiceName string                `json:"service_name"`
+	From        string                `json:"from,omitempty"`
+	To          string                `json:"to,omitempty"`
+	Versions    []VersionReleaseNotes `json:"versions"`
+}
+
+type ArtifactMatch struct {
+	ServiceName string    `json:"service_name"`
+	VersionName string    `json:"version_name"`
+	Type        string    `json:"type"`
+	Reference   string    `json:"reference,omitempty"`
+	Digest      string    `json:"digest"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ArtifactLookup lists the versions which shipped an artifact, oldest first
+type ArtifactLookup struct {
+	Digest  string          `json:"digest"`
+	Matches []ArtifactMatch `json:"matches"`
+}
+
+// ServicePage is a page of GET /v1/services
+type ServicePage struct {
+	Services     []Service `json:"services"`
+	TotalPages   int       `json:"total_pages"`
+	CurrentPage  int       `json:"current_page"`
+	TotalRecords int64     `json:"total_records"`
+}
+
+// ServiceWithVersions is a service along with a page of its versions, as
+// returned by GET /v1/service/:serviceName
+type ServiceWithVersions struct {
+	Service
+
+	Versions            []Version `json:"versions"`
+	TotalPages          int       `json:"total_pages"`
+	CurrentPage         int       `json:"current_page"`
+	TotalVersionRecords int64     `json:"total_version_records"`
+}
+
+// FieldChange is a field changed by a revision, From is nil for the fields
+// set when the service was created
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type Revision struct {
+	Revision  int           `json:"revision"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ServiceHistory is a page of GET /v1/service/:serviceName/history, latest
+// revisions first
+type ServiceHistory struct {
+	Revisions    []Revision `json:"revisions"`
+	TotalPages   int        `json:"total_pages"`
+	CurrentPage  int        `json:"current_page"`
+	TotalRecords int64      `json:"total_records"`
+}
+
+// ServiceAtRevision is a service as it looked at a revision, UpdatedAt is the
+// time of the revision
+type ServiceAtRevision struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Revision    int       `json:"revision"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Links      []Link                 `json:"links"`
+	Contacts   []Contact              `json:"contacts"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type Environment struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Deployment is a version of a service deployed to an environment by Actor
+type Deployment struct {
+	ServiceName string    `json:"service_name"`
+	VersionName string    `json:"version_name"`
+	Environment string    `json:"environment"`
+	Actor       string    `json:"actor"`
+	DeployedAt  time.Time `json:"deployed_at"`
+}
+
+// DeploymentHistory is a page of GET /v1/service/:serviceName/deployments,
+// latest deployments first
+type DeploymentHistory struct {
+	Deployments  []Deployment `json:"deployments"`
+	TotalPages   int          `json:"total_pages"`
+	CurrentPage  int          `json:"current_page"`
+	TotalRecords int64        `json:"total_records"`
+}
+
+// ServiceAlias is a previous name of a renamed service, which keeps resolving
+// to it
+type ServiceAlias struct {
+	Name      string    `json:"name"`
+	RenamedAt time.Time `json:"renamed_at"`
+}
+
+// Channel is a movable name, e.g. stable, pointing to a version of a service
+type Channel struct {
+	Name        string    `json:"name"`
+	VersionName string    `json:"version_name"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ChannelMove is a channel pointed to another version. FromVersion is empty
+// when the channel was created and ToVersion when it was deleted.
+type ChannelMove struct {
+	Channel     string    `json:"channel"`
+	FromVersion string    `json:"from_version,omitempty"`
+	ToVersion   string    `json:"to_version,omitempty"`
+	MovedAt     time.Time `json:"moved_at"`
+}
+
+// ChannelHistory is a page of
+// GET /v1/service/:serviceName/channels/:channel/history, latest moves first
+type ChannelHistory struct {
+	Moves        []ChannelMove `json:"moves"`
+	TotalPages   int           `json:"total_pages"`
+	CurrentPage  int           `json:"current_page"`
+	TotalRecords int64         `json:"total_records"`
+}
+
+// EnvironmentServices is a page of GET /v1/environments/:env/services, the
+// latest deployment of each service to the environment by service name
+type EnvironmentServices struct {
+	Environment  string       `json:"environment"`
+	Services     []Deployment `json:"services"`
+	TotalPages   int          `json:"total_pages"`
+	CurrentPage  int          `json:"current_page"`
+	TotalRecords int64        `json:"total_records"`
+}
+
+// ListServicesOptions filters GET /v1/services, zero values are left out
+type ListServicesOptions struct {
+	Page        int
+	Sort        string
+	Name        string
+	Description string
+	// dot-separated attribute paths and the values they must equal, as text
+	Attributes map[string]string
+}
+
+type CreateServiceRequest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Links       []Link                 `json:"links,omitempty"`
+	Contacts    []Contact              `json:"contacts,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// UpdateServiceRequest identifies the service by ID, empty fields are left
+// unchanged. Links, contacts and attributes are sent as null when nil, which
+// leaves them unchanged too, otherwise they are replaced as a whole.
+type UpdateServiceRequest struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Links       []Link                 `json:"links"`
+	Contacts    []Contact              `json:"contacts"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+type CreateVersionRequest struct {
+	ServiceName  string        `json:"service_name"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description"`
+	Artifacts    []Artifact    `json:"artifacts,omitempty"`
+	ReleaseNotes *ReleaseNotes `json:"release_notes,omitempty"`
+}
+
+type CreateEnvironmentRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type DeployRequest struct {
+	Version     string `json:"version"`
+	Environment string `json:"environment"`
+	Actor       string `json:"actor"`
+}